@@ -0,0 +1,160 @@
+package foundation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrorCode is a stable, machine-readable classification of an AppError
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidArgument is for requests with invalid input
+	ErrorCodeInvalidArgument ErrorCode = "invalid_argument"
+	// ErrorCodeNotFound is for requests for resources that don't exist
+	ErrorCodeNotFound ErrorCode = "not_found"
+	// ErrorCodeAlreadyExists is for requests creating resources that already exist
+	ErrorCodeAlreadyExists ErrorCode = "already_exists"
+	// ErrorCodeUnauthenticated is for requests without valid credentials
+	ErrorCodeUnauthenticated ErrorCode = "unauthenticated"
+	// ErrorCodePermissionDenied is for requests the caller isn't allowed to make
+	ErrorCodePermissionDenied ErrorCode = "permission_denied"
+	// ErrorCodeFailedPrecondition is for requests conflicting with the current state of a resource
+	ErrorCodeFailedPrecondition ErrorCode = "failed_precondition"
+	// ErrorCodeResourceExhausted is for requests exceeding a quota or rate limit
+	ErrorCodeResourceExhausted ErrorCode = "resource_exhausted"
+	// ErrorCodeUnavailable is for failures of a dependency that are likely temporary
+	ErrorCodeUnavailable ErrorCode = "unavailable"
+	// ErrorCodeDeadlineExceeded is for operations that didn't finish in time
+	ErrorCodeDeadlineExceeded ErrorCode = "deadline_exceeded"
+	// ErrorCodeInternal is for unexpected failures; it's the code of any error that isn't an AppError
+	ErrorCodeInternal ErrorCode = "internal"
+)
+
+type errorCodeMapping struct {
+	httpStatus int
+	grpcCode   int
+	retryable  bool
+}
+
+// errorCodeMappings maps each code to its http status, grpc status code as defined in google.golang.org/grpc/codes and whether it's retryable by default
+var errorCodeMappings = map[ErrorCode]errorCodeMapping{
+	ErrorCodeInvalidArgument:    {http.StatusBadRequest, 3, false},
+	ErrorCodeNotFound:           {http.StatusNotFound, 5, false},
+	ErrorCodeAlreadyExists:      {http.StatusConflict, 6, false},
+	ErrorCodeUnauthenticated:    {http.StatusUnauthorized, 16, false},
+	ErrorCodePermissionDenied:   {http.StatusForbidden, 7, false},
+	ErrorCodeFailedPrecondition: {http.StatusConflict, 9, false},
+	ErrorCodeResourceExhausted:  {http.StatusTooManyRequests, 8, true},
+	ErrorCodeUnavailable:        {http.StatusServiceUnavailable, 14, true},
+	ErrorCodeDeadlineExceeded:   {http.StatusGatewayTimeout, 4, true},
+	ErrorCodeInternal:           {http.StatusInternalServerError, 13, false},
+}
+
+// AppError is an error with a stable code, a message that is safe to show to users and whether the failed operation can be retried; the wrapped cause is only meant for logging
+type AppError struct {
+	Code      ErrorCode
+	Message   string
+	Retryable bool
+	Err       error
+}
+
+// NewAppError returns an AppError with the code, user-safe message and the default retryability of the code
+func NewAppError(code ErrorCode, message string) *AppError {
+	return &AppError{
+		Code:      code,
+		Message:   message,
+		Retryable: errorCodeMappings[code].retryable,
+	}
+}
+
+// WrapAppError returns an AppError with the code and user-safe message wrapping err as its cause
+func WrapAppError(err error, code ErrorCode, message string) *AppError {
+	appErr := NewAppError(code, message)
+	appErr.Err = err
+
+	return appErr
+}
+
+// Error returns the code, message and cause; use Message for anything shown to users
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v: %v: %v", e.Code, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("%v: %v", e.Code, e.Message)
+}
+
+// Unwrap returns the cause, so errors.Is and errors.As see through an AppError
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, target) match any AppError with the same code as target
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+
+	return ok && t.Code == e.Code
+}
+
+// HTTPStatus returns the http status code for the error code
+func (e *AppError) HTTPStatus() int {
+	if mapping, ok := errorCodeMappings[e.Code]; ok {
+		return mapping.httpStatus
+	}
+
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the grpc status code for the error code, to be converted with codes.Code(e.GRPCCode())
+func (e *AppError) GRPCCode() int {
+	if mapping, ok := errorCodeMappings[e.Code]; ok {
+		return mapping.grpcCode
+	}
+
+	return errorCodeMappings[ErrorCodeInternal].grpcCode
+}
+
+// MarshalZerologObject adds the code, user-safe message, retryability and cause as fields to a log event, as in log.Error().EmbedObject(appErr).Msg("Handling request failed")
+func (e *AppError) MarshalZerologObject(event *zerolog.Event) {
+	event.Str("code", string(e.Code)).
+		Str("userMessage", e.Message).
+		Bool("retryable", e.Retryable)
+	if e.Err != nil {
+		event.AnErr("cause", e.Err)
+	}
+}
+
+// GetErrorCode returns the code of the first AppError in the chain of err, or ErrorCodeInternal if there is none
+func GetErrorCode(err error) ErrorCode {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+
+	return ErrorCodeInternal
+}
+
+// RetryableAppErrors sets IsRetryableAppError as IsRetryableError
+func RetryableAppErrors() RetryOption {
+	return func(c *RetryConfig) {
+		c.IsRetryableError = IsRetryableAppError
+	}
+}
+
+// IsRetryableAppError is a IsRetryableErrorFunc which returns whether the first AppError in the chain of err is retryable; errors that aren't an AppError are considered retryable
+func IsRetryableAppError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Retryable
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppError(t *testing.T) {
+
+	t.Run("MatchesAppErrorWithSameCodeThroughWrapping", func(t *testing.T) {
+
+		cause := errors.New("sql: no rows in result set")
+		err := fmt.Errorf("Getting pipeline failed: %w", WrapAppError(cause, ErrorCodeNotFound, "Pipeline does not exist"))
+
+		// act
+		isNotFound := errors.Is(err, NewAppError(ErrorCodeNotFound, ""))
+
+		assert.True(t, isNotFound)
+		assert.False(t, errors.Is(err, NewAppError(ErrorCodeInternal, "")))
+		assert.True(t, errors.Is(err, cause))
+		assert.Equal(t, ErrorCodeNotFound, GetErrorCode(err))
+	})
+
+	t.Run("MapsCodeToHTTPAndGRPCStatus", func(t *testing.T) {
+
+		err := NewAppError(ErrorCodeUnavailable, "Database is unavailable")
+
+		// act
+		status := err.HTTPStatus()
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, 14, err.GRPCCode())
+		assert.True(t, err.Retryable)
+	})
+
+	t.Run("MapsFailedPreconditionToConflict", func(t *testing.T) {
+
+		err := NewAppError(ErrorCodeFailedPrecondition, "Pipeline is already running")
+
+		// act
+		status := err.HTTPStatus()
+
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, 9, err.GRPCCode())
+	})
+
+	t.Run("AddsFieldsToLogEvent", func(t *testing.T) {
+
+		var buffer bytes.Buffer
+		logger := zerolog.New(&buffer)
+
+		// act
+		logger.Error().EmbedObject(WrapAppError(errors.New("i/o timeout"), ErrorCodeUnavailable, "Database is unavailable")).Msg("Getting pipeline failed")
+
+		assert.Equal(t, `{"level":"error","code":"unavailable","userMessage":"Database is unavailable","retryable":true,"cause":"i/o timeout","message":"Getting pipeline failed"}`+"\n", buffer.String())
+	})
+
+	t.Run("ReturnsInternalCodeForOtherErrors", func(t *testing.T) {
+
+		// act
+		code := GetErrorCode(errors.New("boom"))
+
+		assert.Equal(t, ErrorCodeInternal, code)
+	})
+}
+
+func TestIsRetryableAppError(t *testing.T) {
+
+	t.Run("StopsRetryingNonRetryableAppErrors", func(t *testing.T) {
+
+		attempts := 0
+
+		// act
+		err := Retry(func() error {
+			attempts++
+			return NewAppError(ErrorCodeInvalidArgument, "Name is required")
+		}, Attempts(3), DelayMillisecond(1), RetryableAppErrors())
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("ReturnsTrueForErrorsThatAreNoAppError", func(t *testing.T) {
+
+		// act
+		retryable := IsRetryableAppError(errors.New("connection reset"))
+
+		assert.True(t, retryable)
+	})
+}
+
+func TestWriteProblemErrorForAppError(t *testing.T) {
+
+	t.Run("WritesStatusAndUserSafeMessageWithoutCause", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodGet, "/api/pipelines/ziplinee-ci", nil)
+		recorder := httptest.NewRecorder()
+
+		// act
+		WriteProblemError(recorder, request, WrapAppError(errors.New("sql: no rows in result set"), ErrorCodeNotFound, "Pipeline does not exist"))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		problem := Problem{}
+		json.Unmarshal(recorder.Body.Bytes(), &problem)
+		assert.Equal(t, "urn:ziplinee:error:not_found", problem.Type)
+		assert.Equal(t, "Pipeline does not exist", problem.Detail)
+	})
+}
@@ -0,0 +1,151 @@
+package foundation
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AuthIdentity is the caller authenticated by the auth middleware
+type AuthIdentity struct {
+	// Name is the name of the api key or the subject of the token
+	Name string
+	// Claims are set when authenticated with a token, nil for api keys
+	Claims *JWTClaims
+}
+
+// AuthOption allows to configure the auth middleware
+type AuthOption func(*AuthConfig)
+
+// AuthConfig is used to configure NewAuthMiddleware
+type AuthConfig struct {
+	// APIKeys maps api key names to their static key
+	APIKeys map[string]string
+	// Keyring is used to validate tokens; tokens aren't accepted if nil
+	Keyring              *JWTKeyring
+	JWTValidationOptions []JWTValidationOption
+	// Authorize decides whether an authenticated identity is allowed to make the request; responds with 403 if not
+	Authorize func(r *http.Request, identity AuthIdentity) bool
+	// AllowRequest is a hook for per-identity rate limiting; responds with 429 if it returns false
+	AllowRequest func(identity AuthIdentity) bool
+}
+
+// WithAPIKey accepts a static api key, identified by name
+func WithAPIKey(name, key string) AuthOption {
+	return func(c *AuthConfig) {
+		c.APIKeys[name] = key
+	}
+}
+
+// WithJWTKeyring accepts tokens signed by any key in the keyring, validated with the validation options
+func WithJWTKeyring(keyring *JWTKeyring, opts ...JWTValidationOption) AuthOption {
+	return func(c *AuthConfig) {
+		c.Keyring = keyring
+		c.JWTValidationOptions = opts
+	}
+}
+
+// WithAuthorization sets a function deciding whether an authenticated identity is allowed to make a request
+func WithAuthorization(authorize func(r *http.Request, identity AuthIdentity) bool) AuthOption {
+	return func(c *AuthConfig) {
+		c.Authorize = authorize
+	}
+}
+
+// WithRateLimiting sets a function deciding whether an authenticated identity has requests left
+func WithRateLimiting(allowRequest func(identity AuthIdentity) bool) AuthOption {
+	return func(c *AuthConfig) {
+		c.AllowRequest = allowRequest
+	}
+}
+
+type authIdentityContextKey struct{}
+
+// GetAuthIdentity returns the identity authenticated by the auth middleware for a request context
+func GetAuthIdentity(ctx context.Context) (AuthIdentity, bool) {
+	identity, ok := ctx.Value(authIdentityContextKey{}).(AuthIdentity)
+	return identity, ok
+}
+
+// NewAuthMiddleware returns middleware requiring an 'Authorization: Bearer <api key or token>' header; the authenticated identity is available to handlers via GetAuthIdentity
+func NewAuthMiddleware(opts ...AuthOption) func(http.Handler) http.Handler {
+
+	config := &AuthConfig{
+		APIKeys: map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	// hash the keys once, so comparisons take constant time regardless of key length
+	apiKeyHashes := map[string][32]byte{}
+	for name, key := range config.APIKeys {
+		apiKeyHashes[name] = sha256.Sum256([]byte(key))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			authorization := r.Header.Get("Authorization")
+			if !strings.HasPrefix(authorization, "Bearer ") {
+				writeAuthProblem(w, r, http.StatusUnauthorized, "Authorization header with bearer token is missing")
+				return
+			}
+			bearer := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+
+			identity, authenticated := authenticateAPIKey(apiKeyHashes, bearer)
+			if !authenticated && config.Keyring != nil {
+				claims, err := ValidateJWT(config.Keyring, bearer, config.JWTValidationOptions...)
+				if err != nil {
+					log.Debug().Err(err).Msg("Validating bearer token failed")
+				} else {
+					identity = AuthIdentity{Name: claims.Subject, Claims: claims}
+					authenticated = true
+				}
+			}
+			if !authenticated {
+				writeAuthProblem(w, r, http.StatusUnauthorized, "Bearer token is invalid")
+				return
+			}
+
+			if config.Authorize != nil && !config.Authorize(r, identity) {
+				writeAuthProblem(w, r, http.StatusForbidden, "Not allowed to access this resource")
+				return
+			}
+
+			if config.AllowRequest != nil && !config.AllowRequest(identity) {
+				writeAuthProblem(w, r, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authIdentityContextKey{}, identity)))
+		})
+	}
+}
+
+func authenticateAPIKey(apiKeyHashes map[string][32]byte, bearer string) (identity AuthIdentity, authenticated bool) {
+	bearerHash := sha256.Sum256([]byte(bearer))
+
+	// compare against all keys without returning early to not leak which key matched through timing
+	for name, keyHash := range apiKeyHashes {
+		if subtle.ConstantTimeCompare(bearerHash[:], keyHash[:]) == 1 {
+			identity = AuthIdentity{Name: name}
+			authenticated = true
+		}
+	}
+
+	return
+}
+
+// writeAuthProblem writes the rejection as problem details, like WriteProblem, with a WWW-Authenticate challenge for 401 responses
+func writeAuthProblem(w http.ResponseWriter, r *http.Request, statusCode int, detail string) {
+	if statusCode == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	WriteProblem(w, r, statusCode, detail)
+}
@@ -0,0 +1,102 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuthMiddleware(t *testing.T) {
+
+	var authenticatedAs string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := GetAuthIdentity(r.Context())
+		authenticatedAs = identity.Name
+	})
+
+	serve := func(middleware func(http.Handler) http.Handler, authorization string) *httptest.ResponseRecorder {
+		authenticatedAs = ""
+		request := httptest.NewRequest(http.MethodGet, "/api/builds", nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		middleware(handler).ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("Returns401IfAuthorizationHeaderIsMissing", func(t *testing.T) {
+
+		middleware := NewAuthMiddleware(WithAPIKey("ci", "abc123"))
+
+		// act
+		recorder := serve(middleware, "")
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+		assert.Equal(t, ProblemContentType, recorder.Header().Get("Content-Type"))
+	})
+
+	t.Run("Returns401IfAPIKeyIsInvalid", func(t *testing.T) {
+
+		middleware := NewAuthMiddleware(WithAPIKey("ci", "abc123"))
+
+		// act
+		recorder := serve(middleware, "Bearer wrong")
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, "", authenticatedAs)
+	})
+
+	t.Run("CallsNextHandlerWithIdentityIfAPIKeyIsValid", func(t *testing.T) {
+
+		middleware := NewAuthMiddleware(WithAPIKey("ci", "abc123"), WithAPIKey("cron", "def456"))
+
+		// act
+		recorder := serve(middleware, "Bearer def456")
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "cron", authenticatedAs)
+	})
+
+	t.Run("CallsNextHandlerWithIdentityIfTokenIsValid", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(JWTKey{ID: "1", Algorithm: JWTAlgorithmHS256, Secret: []byte("secret")})
+		token, _ := IssueJWT(keyring, JWTClaims{Subject: "agent-1", ExpiresAt: time.Now().Add(time.Hour)})
+		middleware := NewAuthMiddleware(WithJWTKeyring(keyring))
+
+		// act
+		recorder := serve(middleware, "Bearer "+token)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "agent-1", authenticatedAs)
+	})
+
+	t.Run("Returns403IfIdentityIsNotAuthorized", func(t *testing.T) {
+
+		middleware := NewAuthMiddleware(WithAPIKey("ci", "abc123"), WithAuthorization(func(r *http.Request, identity AuthIdentity) bool {
+			return identity.Name == "admin"
+		}))
+
+		// act
+		recorder := serve(middleware, "Bearer abc123")
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Equal(t, "", authenticatedAs)
+	})
+
+	t.Run("Returns429IfRateLimitHookDeniesRequest", func(t *testing.T) {
+
+		middleware := NewAuthMiddleware(WithAPIKey("ci", "abc123"), WithRateLimiting(func(identity AuthIdentity) bool {
+			return false
+		}))
+
+		// act
+		recorder := serve(middleware, "Bearer abc123")
+
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	})
+}
@@ -0,0 +1,64 @@
+package foundation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxPersistedBackoffFailures caps the failures carried over between restarts, so the exponential delay can't grow without bound
+const maxPersistedBackoffFailures = 10
+
+// backoffState is persisted by Retry when configured with PersistBackoffState
+type backoffState struct {
+	Failures  uint      `json:"failures"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// PersistBackoffState stores the number of consecutive failures in a small json file at path, so a process that crash-loops against a broken upstream resumes with its previous backoff delay after a restart, instead of reconnecting immediately; the file is removed after a successful attempt
+func PersistBackoffState(path string) RetryOption {
+	return func(c *RetryConfig) {
+		c.BackoffStatePath = path
+	}
+}
+
+// loadBackoffState returns the persisted state, or an empty state if it doesn't exist or can't be read
+func loadBackoffState(path string) backoffState {
+	state := backoffState{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn().Err(err).Msgf("Reading backoff state from %v failed, starting without backoff", path)
+		}
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Warn().Err(err).Msgf("Unmarshalling backoff state from %v failed, starting without backoff", path)
+		return backoffState{}
+	}
+	if state.Failures > maxPersistedBackoffFailures {
+		state.Failures = maxPersistedBackoffFailures
+	}
+
+	return state
+}
+
+func saveBackoffState(path string, state backoffState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomically(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msgf("Writing backoff state to %v failed", path)
+	}
+}
+
+func removeBackoffState(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Msgf("Removing backoff state %v failed", path)
+	}
+}
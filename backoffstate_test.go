@@ -0,0 +1,60 @@
+package foundation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistBackoffState(t *testing.T) {
+
+	t.Run("PersistsConsecutiveFailures", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "backoff.json")
+
+		// act
+		Retry(func() error { return errors.New("connection refused") }, Attempts(2), DelayMillisecond(1), ExponentialBackOff(), PersistBackoffState(path))
+
+		assert.Equal(t, uint(2), loadBackoffState(path).Failures)
+	})
+
+	t.Run("RemovesStateAfterSuccessfulAttempt", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "backoff.json")
+		saveBackoffState(path, backoffState{Failures: 1, UpdatedAt: time.Now().Add(-time.Minute)})
+
+		// act
+		err := Retry(func() error { return nil }, DelayMillisecond(1), PersistBackoffState(path))
+
+		assert.Nil(t, err)
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("WaitsForRemainingDelayOfPreviousProcess", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "backoff.json")
+		saveBackoffState(path, backoffState{Failures: 3, UpdatedAt: time.Now()})
+		start := time.Now()
+
+		// act
+		Retry(func() error { return nil }, DelayMillisecond(10), ExponentialBackOff(), PersistBackoffState(path))
+
+		assert.True(t, time.Since(start) >= 30*time.Millisecond)
+	})
+
+	t.Run("CapsPersistedFailures", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "backoff.json")
+		saveBackoffState(path, backoffState{Failures: 64, UpdatedAt: time.Now().Add(-time.Hour)})
+
+		// act
+		state := loadBackoffState(path)
+
+		assert.Equal(t, uint(maxPersistedBackoffFailures), state.Failures)
+	})
+}
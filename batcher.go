@@ -0,0 +1,147 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrBatcherClosed is returned when adding items to a closed batcher
+var ErrBatcherClosed = errors.New("Batcher is closed")
+
+// FlushBatchFunc writes a batch of items, for example as a single bulk insert
+type FlushBatchFunc[T any] func(ctx context.Context, items []T) error
+
+// Batcher collects items and flushes them in batches
+type Batcher[T any] interface {
+	// Add queues an item; it blocks while the queue is full and flushing can't keep up, until ctx is done
+	Add(ctx context.Context, item T) error
+	// Close flushes the queued items and stops the batcher; call it on graceful shutdown
+	Close(ctx context.Context) error
+}
+
+type batcher[T any] struct {
+	maxBatchSize int
+	maxLatency   time.Duration
+	flush        FlushBatchFunc[T]
+
+	items   chan T
+	mutex   sync.RWMutex
+	closed  bool
+	closing chan context.Context
+	stopped chan error
+}
+
+// NewBatcher returns a Batcher that flushes once maxBatchSize items are collected or maxLatency after the first item of a batch was added, whichever comes first; failed flushes are logged and the items are dropped
+func NewBatcher[T any](maxBatchSize int, maxLatency time.Duration, flush FlushBatchFunc[T]) Batcher[T] {
+	if maxBatchSize < 1 {
+		maxBatchSize = 1
+	}
+
+	b := &batcher[T]{
+		maxBatchSize: maxBatchSize,
+		maxLatency:   maxLatency,
+		flush:        flush,
+		items:        make(chan T, maxBatchSize),
+		closing:      make(chan context.Context, 1),
+		stopped:      make(chan error, 1),
+	}
+
+	go b.run()
+
+	return b
+}
+
+func (b *batcher[T]) Add(ctx context.Context, item T) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.closed {
+		return ErrBatcherClosed
+	}
+
+	select {
+	case b.items <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *batcher[T]) Close(ctx context.Context) error {
+	// wait for blocked adds to finish, so no item gets added after the final flush
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return ErrBatcherClosed
+	}
+	b.closed = true
+	b.mutex.Unlock()
+
+	b.closing <- ctx
+
+	return <-b.stopped
+}
+
+func (b *batcher[T]) run() {
+	batch := make([]T, 0, b.maxBatchSize)
+	timer := time.NewTimer(b.maxLatency)
+	timer.Stop()
+
+	flush := func(ctx context.Context) error {
+		if !timer.Stop() {
+			// drain a fired timer so it doesn't flush the next batch early
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := b.flush(ctx, batch)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Flushing batch of %v items failed", len(batch))
+		}
+		batch = make([]T, 0, b.maxBatchSize)
+
+		return err
+	}
+
+	for {
+		select {
+		case item := <-b.items:
+			batch = append(batch, item)
+			if len(batch) == 1 {
+				timer.Reset(b.maxLatency)
+			}
+			if len(batch) >= b.maxBatchSize {
+				flush(context.Background())
+			}
+
+		case <-timer.C:
+			flush(context.Background())
+
+		case ctx := <-b.closing:
+			// no more items can be added, drain the queue
+			for {
+				select {
+				case item := <-b.items:
+					batch = append(batch, item)
+					if len(batch) >= b.maxBatchSize {
+						flush(ctx)
+					}
+					continue
+				default:
+				}
+				break
+			}
+			b.stopped <- flush(ctx)
+			return
+		}
+	}
+}
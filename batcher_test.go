@@ -0,0 +1,93 @@
+package foundation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedBatches struct {
+	mutex   sync.Mutex
+	batches [][]int
+}
+
+func (r *recordedBatches) flush(ctx context.Context, items []int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.batches = append(r.batches, items)
+	return nil
+}
+
+func (r *recordedBatches) get() [][]int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([][]int{}, r.batches...)
+}
+
+func TestBatcher(t *testing.T) {
+
+	t.Run("FlushesWhenMaxBatchSizeIsReached", func(t *testing.T) {
+
+		recorded := &recordedBatches{}
+		batcher := NewBatcher(2, time.Hour, recorded.flush)
+		defer batcher.Close(context.Background())
+
+		// act
+		batcher.Add(context.Background(), 1)
+		batcher.Add(context.Background(), 2)
+
+		assert.Eventually(t, func() bool { return len(recorded.get()) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, []int{1, 2}, recorded.get()[0])
+	})
+
+	t.Run("FlushesWhenMaxLatencyIsReached", func(t *testing.T) {
+
+		recorded := &recordedBatches{}
+		batcher := NewBatcher(100, 10*time.Millisecond, recorded.flush)
+		defer batcher.Close(context.Background())
+
+		// act
+		batcher.Add(context.Background(), 1)
+
+		assert.Eventually(t, func() bool { return len(recorded.get()) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, []int{1}, recorded.get()[0])
+	})
+
+	t.Run("FlushesRemainingItemsOnClose", func(t *testing.T) {
+
+		recorded := &recordedBatches{}
+		batcher := NewBatcher(100, time.Hour, recorded.flush)
+		batcher.Add(context.Background(), 1)
+		batcher.Add(context.Background(), 2)
+
+		// act
+		err := batcher.Close(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, [][]int{{1, 2}}, recorded.get())
+		assert.Equal(t, ErrBatcherClosed, batcher.Add(context.Background(), 3))
+	})
+
+	t.Run("BlocksAddWhileFlushingCannotKeepUp", func(t *testing.T) {
+
+		unblock := make(chan struct{})
+		batcher := NewBatcher(1, time.Hour, func(ctx context.Context, items []int) error {
+			<-unblock
+			return nil
+		})
+		batcher.Add(context.Background(), 1)
+		batcher.Add(context.Background(), 2)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// act
+		err := batcher.Add(ctx, 3)
+
+		assert.Equal(t, context.DeadlineExceeded, err)
+		close(unblock)
+		batcher.Close(context.Background())
+	})
+}
@@ -0,0 +1,100 @@
+package foundation
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge forwards the values of all channels to the returned channel, which is closed once all channels are closed or ctx is done
+func Merge[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+	for _, ch := range channels {
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for v := range OrDone(ctx, ch) {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// Broadcast sends each value of ch to all n returned channels, which are closed once ch is closed or ctx is done; a slow reader holds up the others, so every returned channel has to be read until closed or ctx cancelled
+func Broadcast[T any](ctx context.Context, ch <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	readOnlyOuts := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		readOnlyOuts[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		for v := range OrDone(ctx, ch) {
+			for _, out := range outs {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return readOnlyOuts
+}
+
+// OrDone forwards the values of ch to the returned channel, which is closed once ch is closed or ctx is done, so ranging over it doesn't block after cancellation
+func OrDone[T any](ctx context.Context, ch <-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// Drain discards the values of ch until it's closed and returns how many were discarded, to unblock the goroutine writing to it
+func Drain[T any](ch <-chan T) int {
+	count := 0
+	for range ch {
+		count++
+	}
+
+	return count
+}
@@ -0,0 +1,116 @@
+package foundation
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sliceToChannel[T any](values ...T) <-chan T {
+	ch := make(chan T, len(values))
+	for _, v := range values {
+		ch <- v
+	}
+	close(ch)
+
+	return ch
+}
+
+func TestMerge(t *testing.T) {
+
+	t.Run("ReturnsValuesOfAllChannels", func(t *testing.T) {
+
+		// act
+		out := Merge(context.Background(), sliceToChannel(1, 2), sliceToChannel(3), sliceToChannel[int]())
+
+		values := []int{}
+		for v := range out {
+			values = append(values, v)
+		}
+		sort.Ints(values)
+		assert.Equal(t, []int{1, 2, 3}, values)
+	})
+
+	t.Run("ClosesChannelWhenContextIsCancelled", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		blocking := make(chan int)
+		defer close(blocking)
+		out := Merge(ctx, blocking)
+
+		// act
+		cancel()
+
+		_, ok := <-out
+		assert.False(t, ok)
+	})
+}
+
+func TestBroadcast(t *testing.T) {
+
+	t.Run("SendsEachValueToAllChannels", func(t *testing.T) {
+
+		outs := Broadcast(context.Background(), sliceToChannel("a", "b"), 2)
+
+		// act
+		merged := Merge(context.Background(), outs...)
+
+		values := []string{}
+		for v := range merged {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		assert.Equal(t, []string{"a", "a", "b", "b"}, values)
+	})
+
+	t.Run("ClosesChannelsWhenContextIsCancelled", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		outs := Broadcast(ctx, sliceToChannel(1, 2), 2)
+
+		// act
+		cancel()
+
+		for _, out := range outs {
+			Drain(out)
+		}
+	})
+}
+
+func TestOrDone(t *testing.T) {
+
+	t.Run("ForwardsValuesUntilChannelIsClosed", func(t *testing.T) {
+
+		// act
+		out := OrDone(context.Background(), sliceToChannel(1, 2, 3))
+
+		assert.Equal(t, 3, Drain(out))
+	})
+
+	t.Run("ClosesChannelWhenContextIsCancelled", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		blocking := make(chan int)
+		defer close(blocking)
+		out := OrDone(ctx, blocking)
+
+		// act
+		cancel()
+
+		_, ok := <-out
+		assert.False(t, ok)
+	})
+}
+
+func TestDrain(t *testing.T) {
+
+	t.Run("ReturnsNumberOfDiscardedValues", func(t *testing.T) {
+
+		// act
+		count := Drain(sliceToChannel("a", "b"))
+
+		assert.Equal(t, 2, count)
+	})
+}
@@ -0,0 +1,219 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ServeFunc runs the application until ctx is canceled on SIGTERM or SIGINT
+type ServeFunc func(ctx context.Context) error
+
+// RootCommand is the command line interface shared by all ziplinee binaries, with the subcommands
+//
+//	serve            loads the config from envvars and runs the application; the default without subcommand
+//	version          prints the version, branch, revision and build date
+//	healthcheck      exits with 0 if the liveness endpoint responds with 200, for a docker HEALTHCHECK in images without curl
+//	validate-config  loads and validates the config, runs the startup checks in check-only mode and prints a json report
+//	config-help      prints all envvars read into the config
+type RootCommand interface {
+	// Execute runs the subcommand in args, without the program name, and returns the exit code
+	Execute(ctx context.Context, args []string) int
+	// Run executes the subcommand in the command line arguments and exits with its exit code
+	Run()
+}
+
+// CommandOption allows to override the defaults of NewRootCommand
+type CommandOption func(*CommandConfig)
+
+// CommandConfig is used to configure the command returned by NewRootCommand
+type CommandConfig struct {
+	HealthcheckURL string
+	Stdout         io.Writer
+	Stderr         io.Writer
+}
+
+// WithHealthcheckURL sets the url the healthcheck subcommand requests
+// default is http://localhost:5000/liveness
+func WithHealthcheckURL(url string) CommandOption {
+	return func(c *CommandConfig) {
+		c.HealthcheckURL = url
+	}
+}
+
+// WithCommandOutput sets where subcommands print their output and errors
+// default is os.Stdout and os.Stderr
+func WithCommandOutput(stdout, stderr io.Writer) CommandOption {
+	return func(c *CommandConfig) {
+		c.Stdout = stdout
+		c.Stderr = stderr
+	}
+}
+
+// NewRootCommand returns a RootCommand for the application; cfg is a pointer to the config struct loaded with LoadConfigFromEnv before serve is called, or nil if the application has no config
+func NewRootCommand(applicationInfo ApplicationInfo, cfg interface{}, serve ServeFunc, opts ...CommandOption) RootCommand {
+
+	config := &CommandConfig{
+		HealthcheckURL: "http://localhost:5000/liveness",
+		Stdout:         os.Stdout,
+		Stderr:         os.Stderr,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return &rootCommand{
+		applicationInfo: applicationInfo,
+		cfg:             cfg,
+		serve:           serve,
+		config:          config,
+	}
+}
+
+type rootCommand struct {
+	applicationInfo ApplicationInfo
+	cfg             interface{}
+	serve           ServeFunc
+	config          *CommandConfig
+}
+
+func (c *rootCommand) Run() {
+	exit(c.Execute(context.Background(), os.Args[1:]))
+}
+
+func (c *rootCommand) Execute(ctx context.Context, args []string) int {
+	subcommand := "serve"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+
+	switch subcommand {
+	case "serve":
+		return c.executeServe(ctx)
+	case "version":
+		return c.executeVersion()
+	case "healthcheck":
+		return c.executeHealthcheck(ctx)
+	case "validate-config":
+		return c.executeValidateConfig(ctx)
+	case "config-help":
+		return c.executeConfigHelp()
+	case "help", "--help", "-h":
+		c.printUsage(c.config.Stdout)
+		return 0
+	}
+
+	fmt.Fprintf(c.config.Stderr, "Unknown command %q\n\n", subcommand)
+	c.printUsage(c.config.Stderr)
+
+	return 2
+}
+
+func (c *rootCommand) executeServe(ctx context.Context) int {
+	if c.cfg != nil {
+		if err := LoadConfigFromEnv(c.cfg); err != nil {
+			log.Error().Err(err).Msg("Loading config failed")
+			return 1
+		}
+	}
+
+	if err := c.serve(InitCancellationContext(ctx)); err != nil {
+		log.Error().Err(err).Msgf("Running %v failed", c.applicationInfo.App)
+		return 1
+	}
+
+	return 0
+}
+
+func (c *rootCommand) executeVersion() int {
+	fmt.Fprintf(c.config.Stdout, "%v version %v (branch %v, revision %v, built %v, %v %v)\n",
+		c.applicationInfo.App,
+		c.applicationInfo.Version,
+		c.applicationInfo.Branch,
+		c.applicationInfo.Revision,
+		c.applicationInfo.BuildDate,
+		c.applicationInfo.GoVersion(),
+		c.applicationInfo.OperatingSystem())
+
+	return 0
+}
+
+func (c *rootCommand) executeHealthcheck(ctx context.Context) int {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.HealthcheckURL, nil)
+	if err != nil {
+		fmt.Fprintln(c.config.Stderr, err)
+		return 1
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintln(c.config.Stderr, err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(c.config.Stderr, "%v responded with status %v\n", c.config.HealthcheckURL, resp.StatusCode)
+		return 1
+	}
+
+	return 0
+}
+
+func (c *rootCommand) executeValidateConfig(ctx context.Context) int {
+	if c.cfg == nil {
+		fmt.Fprintln(c.config.Stderr, "Application has no config to validate")
+		return 1
+	}
+
+	report := validateConfig(ctx, c.cfg)
+
+	data, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Fprintln(c.config.Stdout, string(data))
+
+	if !report.Passed {
+		return 1
+	}
+
+	return 0
+}
+
+func (c *rootCommand) executeConfigHelp() int {
+	if c.cfg == nil {
+		fmt.Fprintln(c.config.Stdout, "Application has no config")
+		return 0
+	}
+
+	help, err := GetConfigHelp(c.cfg)
+	if err != nil {
+		fmt.Fprintln(c.config.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprint(c.config.Stdout, help)
+
+	return 0
+}
+
+func (c *rootCommand) printUsage(w io.Writer) {
+	fmt.Fprintf(w, `Usage: %v [command]
+
+Commands:
+  serve            Run %v; the default without command
+  version          Print version information
+  healthcheck      Check whether the running application is alive
+  validate-config  Validate the config and run startup checks without making changes
+  config-help      Print all envvars read into the config
+`, c.applicationInfo.App, c.applicationInfo.App)
+}
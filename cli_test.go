@@ -0,0 +1,105 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootCommand(t *testing.T) {
+
+	applicationInfo := NewApplicationInfo("ziplinee-ci", "ziplinee-api", "1.2.3", "main", "abc123", "2021-03-01")
+
+	t.Run("ServesWithLoadedConfigWithoutSubcommand", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_DATABASE_DSN", "postgres://localhost/ziplinee")
+		cfg := testConfig{}
+		served := false
+		command := NewRootCommand(applicationInfo, &cfg, func(ctx context.Context) error {
+			served = true
+			assert.Equal(t, "postgres://localhost/ziplinee", cfg.Database.DSN)
+			return nil
+		})
+
+		// act
+		exitCode := command.Execute(context.Background(), []string{})
+
+		assert.Equal(t, 0, exitCode)
+		assert.True(t, served)
+	})
+
+	t.Run("ReturnsNonZeroExitCodeWithoutServingIfConfigFailsToLoad", func(t *testing.T) {
+
+		served := false
+		command := NewRootCommand(applicationInfo, &testConfig{}, func(ctx context.Context) error {
+			served = true
+			return nil
+		})
+
+		// act
+		exitCode := command.Execute(context.Background(), []string{"serve"})
+
+		assert.Equal(t, 1, exitCode)
+		assert.False(t, served)
+	})
+
+	t.Run("PrintsVersion", func(t *testing.T) {
+
+		var stdout bytes.Buffer
+		command := NewRootCommand(applicationInfo, nil, nil, WithCommandOutput(&stdout, &bytes.Buffer{}))
+
+		// act
+		exitCode := command.Execute(context.Background(), []string{"version"})
+
+		assert.Equal(t, 0, exitCode)
+		assert.True(t, strings.HasPrefix(stdout.String(), "ziplinee-api version 1.2.3 (branch main, revision abc123, built 2021-03-01"))
+	})
+
+	t.Run("ReturnsExitCodeOfHealthcheck", func(t *testing.T) {
+
+		alive := true
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !alive {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+		command := NewRootCommand(applicationInfo, nil, nil, WithHealthcheckURL(server.URL), WithCommandOutput(&bytes.Buffer{}, &bytes.Buffer{}))
+
+		// act
+		exitCode := command.Execute(context.Background(), []string{"healthcheck"})
+
+		assert.Equal(t, 0, exitCode)
+		alive = false
+		assert.Equal(t, 1, command.Execute(context.Background(), []string{"healthcheck"}))
+	})
+
+	t.Run("PrintsValidationReport", func(t *testing.T) {
+
+		var stdout bytes.Buffer
+		command := NewRootCommand(applicationInfo, &testConfig{}, nil, WithCommandOutput(&stdout, &bytes.Buffer{}))
+
+		// act
+		exitCode := command.Execute(context.Background(), []string{"validate-config"})
+
+		assert.Equal(t, 1, exitCode)
+		assert.True(t, strings.Contains(stdout.String(), "ESTAFETTE_DATABASE_DSN"))
+	})
+
+	t.Run("ReturnsUsageErrorForUnknownCommand", func(t *testing.T) {
+
+		var stderr bytes.Buffer
+		command := NewRootCommand(applicationInfo, nil, nil, WithCommandOutput(&bytes.Buffer{}, &stderr))
+
+		// act
+		exitCode := command.Execute(context.Background(), []string{"deploy"})
+
+		assert.Equal(t, 2, exitCode)
+		assert.True(t, strings.Contains(stderr.String(), "Usage: ziplinee-api [command]"))
+	})
+}
@@ -0,0 +1,26 @@
+package foundation
+
+import "time"
+
+// Clock provides the current time and timers, so tests can replace the system clock with a fake one to control timeouts, see the testkit package
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After returns a channel that receives the current time once d has passed
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+// NewSystemClock returns a Clock using the time package
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
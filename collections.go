@@ -0,0 +1,224 @@
+package foundation
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Map is a concurrency-safe map with typed keys and values, as a replacement for sync.Map and its type assertions; the zero value is an empty map ready to use
+type Map[K comparable, V any] struct {
+	mutex sync.RWMutex
+	items map[K]V
+}
+
+// NewMap returns an empty Map
+func NewMap[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{}
+}
+
+// Load returns the value stored for key and whether it was present
+func (m *Map[K, V]) Load(key K) (value V, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	value, ok = m.items[key]
+	return
+}
+
+// Store sets the value for key
+func (m *Map[K, V]) Store(key K, value V) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.items == nil {
+		m.items = map[K]V{}
+	}
+	m.items[key] = value
+}
+
+// LoadOrStore returns the existing value for key if present and true; otherwise it stores value and returns it and false
+func (m *Map[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if existing, ok := m.items[key]; ok {
+		return existing, true
+	}
+	if m.items == nil {
+		m.items = map[K]V{}
+	}
+	m.items[key] = value
+
+	return value, false
+}
+
+// Delete removes key
+func (m *Map[K, V]) Delete(key K) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.items, key)
+}
+
+// Range calls f for each key and value until f returns false; it iterates over a copy, so f can modify the map
+func (m *Map[K, V]) Range(f func(key K, value V) bool) {
+	m.mutex.RLock()
+	items := make(map[K]V, len(m.items))
+	for key, value := range m.items {
+		items[key] = value
+	}
+	m.mutex.RUnlock()
+
+	for key, value := range items {
+		if !f(key, value) {
+			return
+		}
+	}
+}
+
+// Keys returns the keys in unspecified order
+func (m *Map[K, V]) Keys() []K {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	keys := make([]K, 0, len(m.items))
+	for key := range m.items {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Len returns the number of keys
+func (m *Map[K, V]) Len() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return len(m.items)
+}
+
+// MarshalJSON marshals the map as a json object
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.items == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(m.items)
+}
+
+// UnmarshalJSON replaces the contents of the map with a json object
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	items := map[K]V{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.items = items
+
+	return nil
+}
+
+// Set is a concurrency-safe set; the zero value is an empty set ready to use
+type Set[T comparable] struct {
+	mutex sync.RWMutex
+	items map[T]struct{}
+}
+
+// NewSet returns a Set containing items
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{}
+	s.Add(items...)
+
+	return s
+}
+
+// Add adds the items to the set
+func (s *Set[T]) Add(items ...T) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.items == nil {
+		s.items = map[T]struct{}{}
+	}
+	for _, item := range items {
+		s.items[item] = struct{}{}
+	}
+}
+
+// Remove removes the items from the set
+func (s *Set[T]) Remove(items ...T) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, item := range items {
+		delete(s.items, item)
+	}
+}
+
+// Contains returns whether item is in the set
+func (s *Set[T]) Contains(item T) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, ok := s.items[item]
+	return ok
+}
+
+// Range calls f for each item until f returns false; it iterates over a copy, so f can modify the set
+func (s *Set[T]) Range(f func(item T) bool) {
+	for _, item := range s.Items() {
+		if !f(item) {
+			return
+		}
+	}
+}
+
+// Items returns the items in unspecified order
+func (s *Set[T]) Items() []T {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	items := make([]T, 0, len(s.items))
+	for item := range s.items {
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// Len returns the number of items
+func (s *Set[T]) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.items)
+}
+
+// MarshalJSON marshals the set as a json array in unspecified order
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Items())
+}
+
+// UnmarshalJSON replaces the contents of the set with the items of a json array
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.items = make(map[T]struct{}, len(items))
+	for _, item := range items {
+		s.items[item] = struct{}{}
+	}
+
+	return nil
+}
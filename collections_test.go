@@ -0,0 +1,120 @@
+package foundation
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+
+	t.Run("ReturnsStoredValue", func(t *testing.T) {
+
+		var m Map[string, int]
+		m.Store("agent-1", 2)
+
+		// act
+		value, ok := m.Load("agent-1")
+
+		assert.True(t, ok)
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("LoadOrStoreKeepsExistingValue", func(t *testing.T) {
+
+		m := NewMap[string, int]()
+		m.Store("agent-1", 2)
+
+		// act
+		value, loaded := m.LoadOrStore("agent-1", 5)
+
+		assert.True(t, loaded)
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("RangesOverCopySoMapCanBeModified", func(t *testing.T) {
+
+		m := NewMap[string, int]()
+		m.Store("agent-1", 1)
+		m.Store("agent-2", 2)
+
+		// act
+		m.Range(func(key string, value int) bool {
+			m.Delete(key)
+			return true
+		})
+
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("IsSafeForConcurrentUse", func(t *testing.T) {
+
+		m := NewMap[int, int]()
+		var wg sync.WaitGroup
+
+		// act
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				m.Store(i, i)
+				m.Load(i)
+				m.Keys()
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, 50, m.Len())
+	})
+
+	t.Run("RoundTripsJSON", func(t *testing.T) {
+
+		m := NewMap[string, int]()
+		m.Store("agent-1", 1)
+
+		// act
+		data, err := json.Marshal(m)
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"agent-1":1}`, string(data))
+		var unmarshalled Map[string, int]
+		if assert.Nil(t, json.Unmarshal(data, &unmarshalled)) {
+			assert.Equal(t, []string{"agent-1"}, unmarshalled.Keys())
+		}
+	})
+}
+
+func TestSet(t *testing.T) {
+
+	t.Run("ContainsAddedItems", func(t *testing.T) {
+
+		s := NewSet("linux", "windows")
+		s.Remove("windows")
+
+		// act
+		contains := s.Contains("linux")
+
+		assert.True(t, contains)
+		assert.False(t, s.Contains("windows"))
+		assert.Equal(t, 1, s.Len())
+	})
+
+	t.Run("RoundTripsJSON", func(t *testing.T) {
+
+		s := NewSet("linux", "windows", "linux")
+
+		// act
+		data, err := json.Marshal(s)
+
+		assert.Nil(t, err)
+		var unmarshalled Set[string]
+		if assert.Nil(t, json.Unmarshal(data, &unmarshalled)) {
+			items := unmarshalled.Items()
+			sort.Strings(items)
+			assert.Equal(t, []string{"linux", "windows"}, items)
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrConfigNotStructPointer is returned when the config passed to the config loader isn't a pointer to a struct
+var ErrConfigNotStructPointer = errors.New("Config has to be a pointer to a struct")
+
+// ConfigField describes a single envvar read by LoadConfigFromEnv
+type ConfigField struct {
+	EnvVar      string
+	Type        string
+	Default     string
+	Required    bool
+	Description string
+}
+
+// LoadConfigFromEnv sets the fields of the struct cfg points to from envvars, as specified by their struct tags:
+//
+//	type Config struct {
+//		APIBaseURL string        `env:"ESTAFETTE_API_BASE_URL" required:"true" description:"Url of the ziplinee api"`
+//		Timeout    time.Duration `env:"ESTAFETTE_TIMEOUT" default:"30s"`
+//	}
+//
+// Fields that are already set, for example by LoadConfigFromFile, are only overridden by envvars, not by defaults. Supported field types are string, bool, all int, uint and float types, time.Duration, []string (comma-separated) and types implementing encoding.TextUnmarshaler, like Duration, ByteSize and URL; nested structs are loaded recursively
+func LoadConfigFromEnv(cfg interface{}) error {
+
+	fields, values, err := configFields(cfg)
+	if err != nil {
+		return err
+	}
+
+	missing := []string{}
+	for i, field := range fields {
+		value, ok := os.LookupEnv(field.EnvVar)
+		if !ok || value == "" {
+			// keep values set before, for example by LoadConfigFromFile
+			if !values[i].IsZero() {
+				continue
+			}
+			if field.Required {
+				missing = append(missing, field.EnvVar)
+				continue
+			}
+			value = field.Default
+		}
+		if value == "" {
+			continue
+		}
+
+		if err := setConfigValue(values[i], value); err != nil {
+			return fmt.Errorf("Envvar %v has invalid value %q: %w", field.EnvVar, value, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("Required envvars %v are not set", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// GetConfigFields returns a description of all envvars read by LoadConfigFromEnv for the struct cfg points to
+func GetConfigFields(cfg interface{}) ([]ConfigField, error) {
+	fields, _, err := configFields(cfg)
+
+	return fields, err
+}
+
+// GetConfigHelp returns a table of all envvars read by LoadConfigFromEnv for the struct cfg points to, including their type, default and whether they're required
+func GetConfigHelp(cfg interface{}) (string, error) {
+
+	fields, err := GetConfigFields(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ENVVAR\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, field := range fields {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", field.EnvVar, field.Type, field.Default, field.Required, field.Description)
+	}
+	writer.Flush()
+
+	return buffer.String(), nil
+}
+
+// PrintConfigHelpIfRequested prints the table returned by GetConfigHelp and exits if the application is started with --print-config-help
+func PrintConfigHelpIfRequested(cfg interface{}) {
+	if !StringArrayContains(os.Args[1:], "--print-config-help") {
+		return
+	}
+
+	help, err := GetConfigHelp(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(1)
+		return
+	}
+
+	fmt.Print(help)
+	exit(0)
+}
+
+func configFields(cfg interface{}) (fields []ConfigField, values []reflect.Value, err error) {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, nil, ErrConfigNotStructPointer
+	}
+
+	collectConfigFields(value.Elem(), &fields, &values)
+
+	return fields, values, nil
+}
+
+func collectConfigFields(value reflect.Value, fields *[]ConfigField, values *[]reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		envVar, ok := structField.Tag.Lookup("env")
+		if !ok {
+			if structField.Type.Kind() == reflect.Struct && !isTextUnmarshaler(structField.Type) {
+				collectConfigFields(value.Field(i), fields, values)
+			}
+			continue
+		}
+
+		required, _ := strconv.ParseBool(structField.Tag.Get("required"))
+
+		*fields = append(*fields, ConfigField{
+			EnvVar:      envVar,
+			Type:        configTypeName(structField.Type),
+			Default:     structField.Tag.Get("default"),
+			Required:    required,
+			Description: structField.Tag.Get("description"),
+		})
+		*values = append(*values, value.Field(i))
+	}
+}
+
+func configTypeName(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)), reflect.TypeOf(Duration(0)):
+		return "duration"
+	case reflect.TypeOf(ByteSize(0)):
+		return "bytesize"
+	case reflect.TypeOf(URL{}):
+		return "url"
+	}
+	if t.Kind() == reflect.Slice {
+		return "[]" + t.Elem().Kind().String()
+	}
+
+	return t.Kind().String()
+}
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+func setConfigValue(field reflect.Value, value string) error {
+
+	if isTextUnmarshaler(field.Type()) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("Unsupported slice type %v", field.Type())
+		}
+		items := strings.Split(value, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("Unsupported type %v", field.Type())
+	}
+
+	return nil
+}
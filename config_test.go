@@ -0,0 +1,108 @@
+package foundation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDatabaseConfig struct {
+	DSN string `env:"ESTAFETTE_DATABASE_DSN" required:"true" description:"Connection string of the database"`
+}
+
+type testConfig struct {
+	APIBaseURL string        `env:"ESTAFETTE_API_BASE_URL" default:"http://localhost:5000" description:"Url of the ziplinee api"`
+	Timeout    time.Duration `env:"ESTAFETTE_TIMEOUT" default:"30s"`
+	Workers    int           `env:"ESTAFETTE_WORKERS" default:"4"`
+	Labels     []string      `env:"ESTAFETTE_LABELS"`
+	Database   testDatabaseConfig
+	ignored    string
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+
+	t.Run("SetsFieldsFromEnvvarsAndDefaults", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_DATABASE_DSN", "postgres://localhost/ziplinee")
+		t.Setenv("ESTAFETTE_WORKERS", "8")
+		t.Setenv("ESTAFETTE_LABELS", "linux, arm64")
+		cfg := testConfig{}
+
+		// act
+		err := LoadConfigFromEnv(&cfg)
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, "http://localhost:5000", cfg.APIBaseURL)
+			assert.Equal(t, 30*time.Second, cfg.Timeout)
+			assert.Equal(t, 8, cfg.Workers)
+			assert.Equal(t, []string{"linux", "arm64"}, cfg.Labels)
+			assert.Equal(t, "postgres://localhost/ziplinee", cfg.Database.DSN)
+		}
+	})
+
+	t.Run("KeepsValuesSetBeforeIfEnvvarIsNotSet", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_WORKERS", "8")
+		cfg := testConfig{APIBaseURL: "https://ziplinee.io/api", Workers: 2, Database: testDatabaseConfig{DSN: "postgres://localhost/ziplinee"}}
+
+		// act
+		err := LoadConfigFromEnv(&cfg)
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, "https://ziplinee.io/api", cfg.APIBaseURL)
+			assert.Equal(t, 8, cfg.Workers)
+		}
+	})
+
+	t.Run("ReturnsErrorIfRequiredEnvvarIsNotSet", func(t *testing.T) {
+
+		cfg := testConfig{}
+
+		// act
+		err := LoadConfigFromEnv(&cfg)
+
+		assert.NotNil(t, err)
+		assert.True(t, strings.Contains(err.Error(), "ESTAFETTE_DATABASE_DSN"))
+	})
+
+	t.Run("ReturnsErrorIfEnvvarHasInvalidValue", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_DATABASE_DSN", "postgres://localhost/ziplinee")
+		t.Setenv("ESTAFETTE_WORKERS", "many")
+		cfg := testConfig{}
+
+		// act
+		err := LoadConfigFromEnv(&cfg)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorIfConfigIsNotAStructPointer", func(t *testing.T) {
+
+		// act
+		err := LoadConfigFromEnv(testConfig{})
+
+		assert.Equal(t, ErrConfigNotStructPointer, err)
+	})
+}
+
+func TestGetConfigHelp(t *testing.T) {
+
+	t.Run("ReturnsTableWithAllEnvvars", func(t *testing.T) {
+
+		// act
+		help, err := GetConfigHelp(&testConfig{})
+
+		if assert.Nil(t, err) {
+			lines := strings.Split(strings.TrimSpace(help), "\n")
+			assert.Equal(t, 6, len(lines))
+			assert.Equal(t, []string{"ENVVAR", "TYPE", "DEFAULT", "REQUIRED", "DESCRIPTION"}, strings.Fields(lines[0]))
+			assert.Equal(t, []string{"ESTAFETTE_TIMEOUT", "duration", "30s", "false"}, strings.Fields(lines[2]))
+			assert.Equal(t, []string{"ESTAFETTE_LABELS", "[]string", "false"}, strings.Fields(lines[4]))
+			assert.True(t, strings.HasPrefix(lines[5], "ESTAFETTE_DATABASE_DSN"))
+			assert.True(t, strings.Contains(lines[5], "Connection string of the database"))
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrConfigKeyUnavailable is returned by LoadConfigFromFile for an encrypted config file when no age key is available to decrypt it
+var ErrConfigKeyUnavailable = errors.New("Config file is encrypted, but no age key is available")
+
+// the binaries used for decryption; overridden in tests
+var (
+	sopsCommand = "sops"
+	ageCommand  = "age"
+)
+
+// ageHeader is the first line of a file encrypted with age
+var ageHeader = []byte("age-encryption.org/v1\n")
+
+// LoadConfigFromFile unmarshals the yaml file at path into the struct cfg points to; a file encrypted with sops or age is decrypted transparently using the age key from envvar SOPS_AGE_KEY or the key file at SOPS_AGE_KEY_FILE, so encrypted config can be committed to git. Decryption shells out to the sops or age binary, which has to be installed. Call LoadConfigFromEnv afterwards to let envvars override values from the file
+func LoadConfigFromFile(ctx context.Context, path string, cfg interface{}) error {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	data, err = decryptConfig(ctx, path, data)
+	if err != nil {
+		return fmt.Errorf("Decrypting config file %v failed: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("Unmarshalling config file %v failed: %w", path, err)
+	}
+
+	return nil
+}
+
+// decryptConfig returns data as is if it isn't encrypted with sops or age
+func decryptConfig(ctx context.Context, path string, data []byte) ([]byte, error) {
+
+	isAge := bytes.HasPrefix(data, ageHeader) || bytes.HasPrefix(data, []byte("-----BEGIN AGE ENCRYPTED FILE-----"))
+	if !isAge && !isSopsEncrypted(data) {
+		return data, nil
+	}
+
+	keyFile, cleanup, err := ageKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var cmd *exec.Cmd
+	if isAge {
+		cmd = exec.CommandContext(ctx, ageCommand, "--decrypt", "--identity", keyFile, path)
+	} else {
+		cmd = exec.CommandContext(ctx, sopsCommand, "--decrypt", "--input-type", "yaml", "--output-type", "yaml", path)
+		cmd.Env = append(os.Environ(), "SOPS_AGE_KEY_FILE="+keyFile)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	decrypted, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", err, string(bytes.TrimSpace(stderr.Bytes())))
+	}
+
+	return decrypted, nil
+}
+
+// isSopsEncrypted returns true for yaml with the top-level sops metadata key added by sops
+func isSopsEncrypted(data []byte) bool {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return false
+	}
+	_, ok := document["sops"]
+
+	return ok
+}
+
+// ageKeyFile returns the path of a file with the age key, writing envvar SOPS_AGE_KEY to a temporary file if set
+func ageKeyFile() (path string, cleanup func(), err error) {
+	cleanup = func() {}
+
+	if key := os.Getenv("SOPS_AGE_KEY"); key != "" {
+		dir, err := ioutil.TempDir("", "age")
+		if err != nil {
+			return "", cleanup, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+
+		path = filepath.Join(dir, "keys.txt")
+		if err := ioutil.WriteFile(path, []byte(key), 0600); err != nil {
+			cleanup()
+			return "", func() {}, err
+		}
+
+		return path, cleanup, nil
+	}
+
+	if path = os.Getenv("SOPS_AGE_KEY_FILE"); path != "" && FileExists(path) {
+		return path, cleanup, nil
+	}
+
+	return "", cleanup, ErrConfigKeyUnavailable
+}
@@ -0,0 +1,74 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testFileConfig struct {
+	APIBaseURL string   `yaml:"apiBaseUrl"`
+	Timeout    Duration `yaml:"timeout"`
+	Password   string   `yaml:"password"`
+}
+
+const testSopsEncryptedConfig = `apiBaseUrl: ENC[AES256_GCM,data:abc,iv:def,tag:ghi,type:str]
+sops:
+  age:
+    - recipient: age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+  version: 3.7.1
+`
+
+func TestLoadConfigFromFile(t *testing.T) {
+
+	t.Run("UnmarshalsPlainYaml", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		ioutil.WriteFile(path, []byte("apiBaseUrl: http://localhost:5000\ntimeout: 30s\n"), 0644)
+		cfg := testFileConfig{}
+
+		// act
+		err := LoadConfigFromFile(context.Background(), path, &cfg)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "http://localhost:5000", cfg.APIBaseURL)
+	})
+
+	t.Run("DecryptsSopsEncryptedYamlWithAgeKeyFromEnvvar", func(t *testing.T) {
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.enc.yaml")
+		ioutil.WriteFile(path, []byte(testSopsEncryptedConfig), 0644)
+		// fake sops binary that only decrypts if it gets passed the age key file
+		fakeSops := filepath.Join(dir, "sops")
+		ioutil.WriteFile(fakeSops, []byte("#!/bin/sh\ngrep -q AGE-SECRET-KEY \"$SOPS_AGE_KEY_FILE\" && printf 'apiBaseUrl: https://ziplinee.io/api\\npassword: s3cr3t\\n'\n"), 0755)
+		defer func(command string) { sopsCommand = command }(sopsCommand)
+		sopsCommand = fakeSops
+		t.Setenv("SOPS_AGE_KEY", "AGE-SECRET-KEY-1QYQSZQGPQYQSZQGPQYQSZQGPQYQSZQGPQYQSZQGPQYQSZQGPQYQS3WC4W2")
+		cfg := testFileConfig{}
+
+		// act
+		err := LoadConfigFromFile(context.Background(), path, &cfg)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "https://ziplinee.io/api", cfg.APIBaseURL)
+		assert.Equal(t, "s3cr3t", cfg.Password)
+	})
+
+	t.Run("ReturnsErrConfigKeyUnavailableForEncryptedYamlWithoutKey", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "config.enc.yaml")
+		ioutil.WriteFile(path, []byte(testSopsEncryptedConfig), 0644)
+		t.Setenv("SOPS_AGE_KEY", "")
+		t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+		// act
+		err := LoadConfigFromFile(context.Background(), path, &testFileConfig{})
+
+		assert.True(t, errors.Is(err, ErrConfigKeyUnavailable))
+	})
+}
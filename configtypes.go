@@ -0,0 +1,123 @@
+package foundation
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration that unmarshals from strings like 30s or 5m in yaml, json and envvars loaded with LoadConfigFromEnv
+type Duration time.Duration
+
+// UnmarshalText parses a duration string as accepted by time.ParseDuration
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+
+	return nil
+}
+
+// MarshalText returns the duration formatted like 1m30s
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Duration returns the value as time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// ByteSize is a number of bytes that unmarshals from strings like 512, 10MB or 1.5GiB in yaml, json and envvars loaded with LoadConfigFromEnv; units without i are powers of 1000, units with i powers of 1024
+type ByteSize int64
+
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// UnmarshalText parses a size like 512, 10MB or 1.5GiB
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := value, ""
+	if i >= 0 {
+		number, unit = value[:i], strings.ToLower(strings.TrimSpace(value[i:]))
+	}
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return fmt.Errorf("Unknown unit %q in size %q", unit, value)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid size %q: %w", value, err)
+	}
+	bytes := n * multiplier
+	if bytes >= math.MaxInt64 {
+		return fmt.Errorf("Size %q is too large", value)
+	}
+	*s = ByteSize(bytes)
+
+	return nil
+}
+
+// MarshalText returns the size with the largest binary unit it's a whole multiple of, like 10MiB, so it unmarshals to the same value
+func (s ByteSize) MarshalText() ([]byte, error) {
+	for _, unit := range []string{"TiB", "GiB", "MiB", "KiB"} {
+		multiplier := int64(byteSizeUnits[strings.ToLower(unit)])
+		if s != 0 && int64(s)%multiplier == 0 {
+			return []byte(fmt.Sprintf("%d%v", int64(s)/multiplier, unit)), nil
+		}
+	}
+
+	return []byte(strconv.FormatInt(int64(s), 10)), nil
+}
+
+// URL is an absolute url that unmarshals from a string in yaml, json and envvars loaded with LoadConfigFromEnv, failing if it has no scheme or host
+type URL struct {
+	url.URL
+}
+
+// UnmarshalText parses an absolute url
+func (u *URL) UnmarshalText(text []byte) error {
+	parsed, err := url.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("Url %q is not absolute", string(text))
+	}
+	u.URL = *parsed
+
+	return nil
+}
+
+// MarshalText returns the url as string
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.URL.String()), nil
+}
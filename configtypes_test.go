@@ -0,0 +1,92 @@
+package foundation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type testTypedConfig struct {
+	Timeout     Duration `json:"timeout" yaml:"timeout" env:"ESTAFETTE_TIMEOUT"`
+	MaxBodySize ByteSize `json:"maxBodySize" yaml:"maxBodySize" env:"ESTAFETTE_MAX_BODY_SIZE"`
+	APIBaseURL  URL      `json:"apiBaseUrl" yaml:"apiBaseUrl" env:"ESTAFETTE_API_BASE_URL"`
+}
+
+func TestConfigTypes(t *testing.T) {
+
+	t.Run("UnmarshalFromYaml", func(t *testing.T) {
+
+		cfg := testTypedConfig{}
+
+		// act
+		err := yaml.Unmarshal([]byte("timeout: 1m30s\nmaxBodySize: 10MiB\napiBaseUrl: https://ziplinee.io/api\n"), &cfg)
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, 90*time.Second, cfg.Timeout.Duration())
+			assert.Equal(t, ByteSize(10*1024*1024), cfg.MaxBodySize)
+			assert.Equal(t, "ziplinee.io", cfg.APIBaseURL.Host)
+		}
+	})
+
+	t.Run("UnmarshalFromJsonAndMarshalToSameValues", func(t *testing.T) {
+
+		data := []byte(`{"timeout":"5m0s","maxBodySize":"512KiB","apiBaseUrl":"https://ziplinee.io/api"}`)
+		cfg := testTypedConfig{}
+
+		// act
+		err := json.Unmarshal(data, &cfg)
+
+		if assert.Nil(t, err) {
+			marshalled, _ := json.Marshal(cfg)
+			assert.Equal(t, string(data), string(marshalled))
+		}
+	})
+
+	t.Run("LoadFromEnv", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_TIMEOUT", "45s")
+		t.Setenv("ESTAFETTE_MAX_BODY_SIZE", "1.5GB")
+		t.Setenv("ESTAFETTE_API_BASE_URL", "http://localhost:5000")
+		cfg := testTypedConfig{}
+
+		// act
+		err := LoadConfigFromEnv(&cfg)
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, 45*time.Second, cfg.Timeout.Duration())
+			assert.Equal(t, ByteSize(1500000000), cfg.MaxBodySize)
+			assert.Equal(t, "localhost:5000", cfg.APIBaseURL.Host)
+		}
+	})
+
+	t.Run("ReturnErrorForInvalidValues", func(t *testing.T) {
+
+		var duration Duration
+		var size ByteSize
+		var u URL
+
+		assert.NotNil(t, duration.UnmarshalText([]byte("5 minutes")))
+		assert.NotNil(t, size.UnmarshalText([]byte("10XB")))
+		assert.NotNil(t, size.UnmarshalText([]byte("MB")))
+		assert.NotNil(t, size.UnmarshalText([]byte("9223372036854775808")))
+		assert.NotNil(t, u.UnmarshalText([]byte("/api/pipelines")))
+	})
+}
+
+func TestGetConfigFieldsForConfigTypes(t *testing.T) {
+
+	t.Run("ReturnsReadableTypeNames", func(t *testing.T) {
+
+		// act
+		fields, err := GetConfigFields(&testTypedConfig{})
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, "duration", fields[0].Type)
+			assert.Equal(t, "bytesize", fields[1].Type)
+			assert.Equal(t, "url", fields[2].Type)
+		}
+	})
+}
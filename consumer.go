@@ -0,0 +1,188 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Message is a single message received from a queue, which has to be acked or nacked once handled
+type Message interface {
+	// Ack acknowledges the message as successfully handled
+	Ack() error
+	// Nack negatively acknowledges the message so it gets redelivered
+	Nack() error
+}
+
+// ReceiveMessageFunc blocks until the next message is received from a queue or the context is cancelled
+type ReceiveMessageFunc func(ctx context.Context) (Message, error)
+
+// HandleMessageFunc handles a single message; if it returns an error the message gets nacked, otherwise it gets acked
+type HandleMessageFunc func(ctx context.Context, message Message) error
+
+// Consumer is a lifecycle adapter for queue consumers, tracking in-flight messages so they can be drained on graceful shutdown
+type Consumer interface {
+	// Start receives and handles messages until Drain is called or the context is cancelled; it blocks until receiving stops. Cancelling the context doesn't cancel in-flight handlers, only Drain timing out does
+	Start(ctx context.Context) error
+	// Drain stops receiving new messages and waits for in-flight messages to be acked or nacked; if ctx expires first, in-flight handlers get cancelled
+	Drain(ctx context.Context) error
+}
+
+type consumer struct {
+	receive   ReceiveMessageFunc
+	handle    HandleMessageFunc
+	waitGroup *sync.WaitGroup
+	semaphore Semaphore
+	inFlight  sync.WaitGroup
+
+	mutex         sync.Mutex
+	draining      bool
+	stopReceiving context.CancelFunc
+	abortHandling context.CancelFunc
+	stopped       chan struct{}
+}
+
+// NewConsumer returns a Consumer handling at most maxConcurrency messages at the same time; each in-flight message is tracked in the waitGroup returned by InitGracefulShutdownHandling
+func NewConsumer(receive ReceiveMessageFunc, handle HandleMessageFunc, waitGroup *sync.WaitGroup, maxConcurrency int) Consumer {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
+	return &consumer{
+		receive:   receive,
+		handle:    handle,
+		waitGroup: waitGroup,
+		semaphore: NewSemaphore(maxConcurrency),
+	}
+}
+
+func (c *consumer) Start(ctx context.Context) error {
+
+	receiveCtx, stopReceiving := context.WithCancel(ctx)
+	defer stopReceiving()
+	// handlers aren't cancelled with ctx, which is cancelled on SIGTERM, but only when Drain times out
+	handleCtx, abortHandling := context.WithCancel(context.Background())
+
+	c.mutex.Lock()
+	if c.draining {
+		c.mutex.Unlock()
+		abortHandling()
+		return nil
+	}
+	c.stopReceiving = stopReceiving
+	c.abortHandling = abortHandling
+	c.stopped = make(chan struct{})
+	defer close(c.stopped)
+	c.mutex.Unlock()
+
+	// release the handle context once the handlers started by this call have finished, without blocking the return of Start
+	var handlers sync.WaitGroup
+	defer func() {
+		go func() {
+			handlers.Wait()
+			abortHandling()
+		}()
+	}()
+
+	for {
+		// wait for a free slot before receiving, so no message is held that can't be handled
+		select {
+		case c.semaphore.GetAcquireChannel() <- struct{}{}:
+		case <-receiveCtx.Done():
+			return nil
+		}
+
+		message, err := c.receive(receiveCtx)
+		if err != nil {
+			c.semaphore.Release()
+			if receiveCtx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		c.inFlight.Add(1)
+		handlers.Add(1)
+		if c.waitGroup != nil {
+			c.waitGroup.Add(1)
+		}
+
+		go func(message Message) {
+			defer func() {
+				c.semaphore.Release()
+				if c.waitGroup != nil {
+					c.waitGroup.Done()
+				}
+				c.inFlight.Done()
+				handlers.Done()
+			}()
+
+			c.handleMessage(handleCtx, message)
+		}(message)
+	}
+}
+
+func (c *consumer) handleMessage(ctx context.Context, message Message) {
+	var err error
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("Handling message panicked: %v", rec)
+			}
+		}()
+		err = c.handle(ctx, message)
+	}()
+
+	if err != nil {
+		log.Warn().Err(err).Msg("Handling message failed, nacking it")
+		if nackErr := message.Nack(); nackErr != nil {
+			log.Error().Err(nackErr).Msg("Nacking message failed")
+		}
+		return
+	}
+
+	if ackErr := message.Ack(); ackErr != nil {
+		log.Error().Err(ackErr).Msg("Acking message failed")
+	}
+}
+
+func (c *consumer) Drain(ctx context.Context) error {
+
+	c.mutex.Lock()
+	c.draining = true
+	stopReceiving := c.stopReceiving
+	abortHandling := c.abortHandling
+	stopped := c.stopped
+	c.mutex.Unlock()
+
+	if stopReceiving != nil {
+		stopReceiving()
+
+		// make sure no new messages get tracked before waiting for the in-flight ones
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			abortHandling()
+			return ctx.Err()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		log.Warn().Err(ctx.Err()).Msg("Draining consumer timed out, cancelling in-flight messages")
+		if abortHandling != nil {
+			abortHandling()
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,196 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMessage struct {
+	acked  int32
+	nacked int32
+}
+
+func (m *fakeMessage) Ack() error {
+	atomic.AddInt32(&m.acked, 1)
+	return nil
+}
+
+func (m *fakeMessage) Nack() error {
+	atomic.AddInt32(&m.nacked, 1)
+	return nil
+}
+
+func fakeReceiver(messages []*fakeMessage) ReceiveMessageFunc {
+	queue := make(chan *fakeMessage, len(messages))
+	for _, m := range messages {
+		queue <- m
+	}
+
+	return func(ctx context.Context) (Message, error) {
+		select {
+		case m := <-queue:
+			return m, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestConsumer(t *testing.T) {
+
+	t.Run("AcksSuccessfullyHandledMessagesAndNacksFailedOnes", func(t *testing.T) {
+
+		succeeding := &fakeMessage{}
+		failing := &fakeMessage{}
+		handle := func(ctx context.Context, message Message) error {
+			if message == failing {
+				return errors.New("handling failed")
+			}
+			return nil
+		}
+		waitGroup := &sync.WaitGroup{}
+		consumer := NewConsumer(fakeReceiver([]*fakeMessage{succeeding, failing}), handle, waitGroup, 2)
+
+		go consumer.Start(context.Background())
+		time.Sleep(50 * time.Millisecond)
+
+		// act
+		err := consumer.Drain(context.Background())
+
+		assert.Nil(t, err)
+		waitGroup.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&succeeding.acked))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&succeeding.nacked))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&failing.acked))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&failing.nacked))
+	})
+
+	t.Run("NacksMessageIfHandlerPanics", func(t *testing.T) {
+
+		message := &fakeMessage{}
+		handle := func(ctx context.Context, message Message) error {
+			panic("boom")
+		}
+		consumer := NewConsumer(fakeReceiver([]*fakeMessage{message}), handle, nil, 1)
+
+		go consumer.Start(context.Background())
+		time.Sleep(50 * time.Millisecond)
+
+		// act
+		err := consumer.Drain(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&message.nacked))
+	})
+
+	t.Run("WaitsForInFlightMessagesWhenDraining", func(t *testing.T) {
+
+		message := &fakeMessage{}
+		handle := func(ctx context.Context, message Message) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}
+		consumer := NewConsumer(fakeReceiver([]*fakeMessage{message}), handle, nil, 1)
+
+		go consumer.Start(context.Background())
+		time.Sleep(20 * time.Millisecond)
+
+		// act
+		err := consumer.Drain(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&message.acked))
+	})
+
+	t.Run("CancelsInFlightMessagesIfDrainTimesOut", func(t *testing.T) {
+
+		message := &fakeMessage{}
+		handle := func(ctx context.Context, message Message) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		waitGroup := &sync.WaitGroup{}
+		consumer := NewConsumer(fakeReceiver([]*fakeMessage{message}), handle, waitGroup, 1)
+
+		go consumer.Start(context.Background())
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// act
+		err := consumer.Drain(ctx)
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		waitGroup.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&message.nacked))
+	})
+
+	t.Run("ReleasesHandleContextOnceHandlersHaveFinished", func(t *testing.T) {
+
+		handleCtxs := make(chan context.Context, 1)
+		handle := func(ctx context.Context, message Message) error {
+			handleCtxs <- ctx
+			return nil
+		}
+		consumer := NewConsumer(fakeReceiver([]*fakeMessage{{}}), handle, nil, 1)
+
+		go consumer.Start(context.Background())
+		handleCtx := <-handleCtxs
+
+		// act
+		err := consumer.Drain(context.Background())
+
+		assert.Nil(t, err)
+		select {
+		case <-handleCtx.Done():
+		case <-time.After(time.Second):
+			assert.Fail(t, "Handle context wasn't cancelled after Start returned")
+		}
+	})
+
+	t.Run("KeepsHandlingInFlightMessagesWhenStartContextIsCancelled", func(t *testing.T) {
+
+		message := &fakeMessage{}
+		started := make(chan struct{})
+		handle := func(ctx context.Context, message Message) error {
+			close(started)
+			select {
+			case <-time.After(100 * time.Millisecond):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		consumer := NewConsumer(fakeReceiver([]*fakeMessage{message}), handle, nil, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go consumer.Start(ctx)
+		<-started
+
+		// act
+		cancel()
+		err := consumer.Drain(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&message.acked))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&message.nacked))
+	})
+
+	t.Run("StartReturnsImmediatelyIfAlreadyDrained", func(t *testing.T) {
+
+		consumer := NewConsumer(fakeReceiver(nil), func(ctx context.Context, message Message) error { return nil }, nil, 1)
+		consumer.Drain(context.Background())
+
+		// act
+		err := consumer.Start(context.Background())
+
+		assert.Nil(t, err)
+	})
+}
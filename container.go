@@ -0,0 +1,174 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrComponentUnknown is returned when getting a component or dependency that isn't registered
+var ErrComponentUnknown = errors.New("Component is not registered")
+
+// ErrComponentDependencyCycle is returned when components depend on each other in a cycle
+var ErrComponentDependencyCycle = errors.New("Components depend on each other in a cycle")
+
+// ComponentConstructor creates a component from its constructed dependencies, keyed by name
+type ComponentConstructor func(ctx context.Context, dependencies map[string]interface{}) (interface{}, error)
+
+// Container wires named components together in the order derived from their dependencies and shuts them down in reverse order
+type Container interface {
+	// Register registers a constructor for a component that depends on the named components
+	Register(name string, constructor ComponentConstructor, dependencies ...string)
+	// Get returns the component, constructing it and its dependencies on first use
+	Get(ctx context.Context, name string) (interface{}, error)
+	// Start constructs all registered components that haven't been constructed yet
+	Start(ctx context.Context) error
+	// Shutdown shuts down constructed components that have a Shutdown(ctx) error or Close() error method, in reverse order of construction
+	Shutdown(ctx context.Context) error
+}
+
+type registeredComponent struct {
+	constructor  ComponentConstructor
+	dependencies []string
+}
+
+type container struct {
+	mutex       sync.Mutex
+	registered  map[string]registeredComponent
+	constructed map[string]interface{}
+	order       []string
+}
+
+// NewContainer returns an empty Container
+func NewContainer() Container {
+	return &container{
+		registered:  map[string]registeredComponent{},
+		constructed: map[string]interface{}{},
+	}
+}
+
+// GetComponent returns the component from the container as type T
+func GetComponent[T any](ctx context.Context, c Container, name string) (T, error) {
+	var t T
+
+	component, err := c.Get(ctx, name)
+	if err != nil {
+		return t, err
+	}
+
+	t, ok := component.(T)
+	if !ok {
+		return t, fmt.Errorf("Component %v is of type %T, not %T", name, component, t)
+	}
+
+	return t, nil
+}
+
+func (c *container) Register(name string, constructor ComponentConstructor, dependencies ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.registered[name] = registeredComponent{
+		constructor:  constructor,
+		dependencies: dependencies,
+	}
+}
+
+func (c *container) Get(ctx context.Context, name string) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.construct(ctx, name, nil)
+}
+
+func (c *container) Start(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// sort for a deterministic order between components that don't depend on each other
+	names := make([]string, 0, len(c.registered))
+	for name := range c.registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := c.construct(ctx, name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *container) Shutdown(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var firstErr error
+	for i := len(c.order) - 1; i >= 0; i-- {
+		name := c.order[i]
+
+		var err error
+		switch component := c.constructed[name].(type) {
+		case interface{ Shutdown(context.Context) error }:
+			err = component.Shutdown(ctx)
+		case interface{ Close() error }:
+			err = component.Close()
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("Shutting down component %v failed", name)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	c.constructed = map[string]interface{}{}
+	c.order = nil
+
+	return firstErr
+}
+
+// construct constructs the component after its dependencies; path holds the components being constructed to detect cycles; must be called with the mutex held
+func (c *container) construct(ctx context.Context, name string, path []string) (interface{}, error) {
+	if component, ok := c.constructed[name]; ok {
+		return component, nil
+	}
+
+	for _, p := range path {
+		if p == name {
+			return nil, fmt.Errorf("%w: %v", ErrComponentDependencyCycle, strings.Join(append(path, name), " -> "))
+		}
+	}
+
+	registered, ok := c.registered[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrComponentUnknown, name)
+	}
+
+	path = append(path, name)
+	dependencies := make(map[string]interface{}, len(registered.dependencies))
+	for _, dependency := range registered.dependencies {
+		component, err := c.construct(ctx, dependency, path)
+		if err != nil {
+			return nil, err
+		}
+		dependencies[dependency] = component
+	}
+
+	component, err := registered.constructor(ctx, dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("Constructing component %v failed: %w", name, err)
+	}
+
+	c.constructed[name] = component
+	c.order = append(c.order, name)
+
+	return component, nil
+}
@@ -0,0 +1,99 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testComponent struct {
+	name     string
+	shutdown *[]string
+}
+
+func (c *testComponent) Close() error {
+	*c.shutdown = append(*c.shutdown, c.name)
+	return nil
+}
+
+func TestContainer(t *testing.T) {
+
+	t.Run("ConstructsDependenciesFirstAndShutsDownInReverseOrder", func(t *testing.T) {
+
+		constructed := []string{}
+		shutdown := []string{}
+		newComponent := func(name string) ComponentConstructor {
+			return func(ctx context.Context, dependencies map[string]interface{}) (interface{}, error) {
+				constructed = append(constructed, name)
+				return &testComponent{name: name, shutdown: &shutdown}, nil
+			}
+		}
+		container := NewContainer()
+		container.Register("api", newComponent("api"), "database", "queue")
+		container.Register("queue", newComponent("queue"))
+		container.Register("database", newComponent("database"))
+
+		// act
+		err := container.Start(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"database", "queue", "api"}, constructed)
+		container.Shutdown(context.Background())
+		assert.Equal(t, []string{"api", "queue", "database"}, shutdown)
+	})
+
+	t.Run("ConstructsComponentsLazilyOnlyOnce", func(t *testing.T) {
+
+		constructions := 0
+		container := NewContainer()
+		container.Register("database", func(ctx context.Context, dependencies map[string]interface{}) (interface{}, error) {
+			constructions++
+			return "db", nil
+		})
+
+		// act
+		container.Get(context.Background(), "database")
+		db, err := GetComponent[string](context.Background(), container, "database")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "db", db)
+		assert.Equal(t, 1, constructions)
+	})
+
+	t.Run("ReturnsErrorForDependencyCycle", func(t *testing.T) {
+
+		noop := func(ctx context.Context, dependencies map[string]interface{}) (interface{}, error) { return nil, nil }
+		container := NewContainer()
+		container.Register("a", noop, "b")
+		container.Register("b", noop, "a")
+
+		// act
+		_, err := container.Get(context.Background(), "a")
+
+		assert.True(t, errors.Is(err, ErrComponentDependencyCycle))
+	})
+
+	t.Run("ReturnsErrorForUnknownDependency", func(t *testing.T) {
+
+		container := NewContainer()
+		container.Register("api", func(ctx context.Context, dependencies map[string]interface{}) (interface{}, error) { return nil, nil }, "cache")
+
+		// act
+		err := container.Start(context.Background())
+
+		assert.True(t, errors.Is(err, ErrComponentUnknown))
+	})
+
+	t.Run("ReturnsErrorIfComponentHasOtherType", func(t *testing.T) {
+
+		container := NewContainer()
+		container.Register("workers", func(ctx context.Context, dependencies map[string]interface{}) (interface{}, error) { return 5, nil })
+
+		// act
+		_, err := GetComponent[string](context.Background(), container, "workers")
+
+		assert.NotNil(t, err)
+	})
+}
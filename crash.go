@@ -0,0 +1,89 @@
+package foundation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime/debug"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// exit is a variable so tests can intercept exiting the process
+var exit = os.Exit
+
+// CrashReportFunc sends a recovered panic to an error tracker like Sentry; it should return once the report has been sent
+type CrashReportFunc func(recovered interface{}, stack []byte)
+
+// CrashOption allows to override the crash handler config
+type CrashOption func(*CrashConfig)
+
+// CrashConfig is used to configure HandleCrash
+type CrashConfig struct {
+	ExitCode               int
+	TerminationMessagePath string
+	Report                 CrashReportFunc
+}
+
+// WithCrashExitCode sets the exit code used when the process crashes
+// default is 2, the same code the go runtime uses for an unrecovered panic
+func WithCrashExitCode(exitCode int) CrashOption {
+	return func(c *CrashConfig) {
+		c.ExitCode = exitCode
+	}
+}
+
+// WithTerminationMessagePath sets the file the panic message is written to, so it shows up as the reason of the terminated container; an empty path skips writing it
+// default is /dev/termination-log
+func WithTerminationMessagePath(path string) CrashOption {
+	return func(c *CrashConfig) {
+		c.TerminationMessagePath = path
+	}
+}
+
+// WithCrashReporter sets a function to send the panic to an error tracker before exiting
+func WithCrashReporter(report CrashReportFunc) CrashOption {
+	return func(c *CrashConfig) {
+		c.Report = report
+	}
+}
+
+// HandleCrash converts a panic into a structured fatal log message, writes a termination message, reports it and exits with the configured exit code; defer it at the top of main and of any long-running goroutine
+func HandleCrash(opts ...CrashOption) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	config := &CrashConfig{
+		ExitCode:               2,
+		TerminationMessagePath: "/dev/termination-log",
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	stack := debug.Stack()
+	message := fmt.Sprintf("Panic: %v", recovered)
+
+	log.WithLevel(zerolog.FatalLevel).
+		Str("stack", string(stack)).
+		Msg(message)
+
+	if config.TerminationMessagePath != "" {
+		if err := ioutil.WriteFile(config.TerminationMessagePath, []byte(message), 0644); err != nil {
+			log.Warn().Err(err).Msgf("Writing termination message to %v failed", config.TerminationMessagePath)
+		}
+	}
+
+	if config.Report != nil {
+		config.Report(recovered, stack)
+	}
+
+	runFlushers()
+
+	exit(config.ExitCode)
+}
@@ -0,0 +1,50 @@
+package foundation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleCrash(t *testing.T) {
+
+	t.Run("WritesTerminationMessageReportsAndExitsWithConfiguredCode", func(t *testing.T) {
+
+		exitCode := -1
+		exit = func(code int) { exitCode = code }
+		defer func() { exit = os.Exit }()
+
+		terminationMessagePath := filepath.Join(t.TempDir(), "termination-log")
+		var reported interface{}
+
+		// act
+		func() {
+			defer HandleCrash(WithCrashExitCode(3), WithTerminationMessagePath(terminationMessagePath), WithCrashReporter(func(recovered interface{}, stack []byte) {
+				reported = recovered
+			}))
+			panic("index out of range")
+		}()
+
+		assert.Equal(t, 3, exitCode)
+		assert.Equal(t, "index out of range", reported)
+		message, _ := ioutil.ReadFile(terminationMessagePath)
+		assert.Equal(t, "Panic: index out of range", string(message))
+	})
+
+	t.Run("DoesNothingWithoutPanic", func(t *testing.T) {
+
+		exited := false
+		exit = func(code int) { exited = true }
+		defer func() { exit = os.Exit }()
+
+		// act
+		func() {
+			defer HandleCrash()
+		}()
+
+		assert.False(t, exited)
+	})
+}
@@ -0,0 +1,120 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DatabaseOption allows to override the database config
+type DatabaseOption func(*DatabaseConfig)
+
+// DatabaseConfig is used to configure the connection pool returned by InitDatabase
+type DatabaseConfig struct {
+	DriverName         string
+	MaxOpenConnections int
+	MaxIdleConnections int
+	ConnMaxLifetime    time.Duration
+	ConnMaxIdleTime    time.Duration
+	RetryOptions       []RetryOption
+}
+
+// WithDatabaseDriver sets the name of the registered database/sql driver to use
+// default is postgres
+func WithDatabaseDriver(driverName string) DatabaseOption {
+	return func(c *DatabaseConfig) {
+		c.DriverName = driverName
+	}
+}
+
+// WithMaxOpenConnections sets the maximum number of open connections in the pool
+// default is 10
+func WithMaxOpenConnections(maxOpenConnections int) DatabaseOption {
+	return func(c *DatabaseConfig) {
+		c.MaxOpenConnections = maxOpenConnections
+	}
+}
+
+// WithMaxIdleConnections sets the maximum number of idle connections in the pool
+// default is 5
+func WithMaxIdleConnections(maxIdleConnections int) DatabaseOption {
+	return func(c *DatabaseConfig) {
+		c.MaxIdleConnections = maxIdleConnections
+	}
+}
+
+// WithConnMaxLifetime sets how long a connection is reused before it gets closed, so connections get rebalanced over database nodes
+// default is 5 minutes
+func WithConnMaxLifetime(connMaxLifetime time.Duration) DatabaseOption {
+	return func(c *DatabaseConfig) {
+		c.ConnMaxLifetime = connMaxLifetime
+	}
+}
+
+// WithConnMaxIdleTime sets how long a connection can be idle before it gets closed
+// default is 1 minute
+func WithConnMaxIdleTime(connMaxIdleTime time.Duration) DatabaseOption {
+	return func(c *DatabaseConfig) {
+		c.ConnMaxIdleTime = connMaxIdleTime
+	}
+}
+
+// WithConnectRetryOptions overrides the options for retrying the initial connection
+// default is 5 attempts with exponential backoff with jitter starting at 1 second
+func WithConnectRetryOptions(opts ...RetryOption) DatabaseOption {
+	return func(c *DatabaseConfig) {
+		c.RetryOptions = opts
+	}
+}
+
+// InitDatabase opens a connection pool to the database and retries connecting until it succeeds; it registers a readiness check pinging the database and closes the pool on graceful shutdown
+func InitDatabase(ctx context.Context, dsn string, opts ...DatabaseOption) (*sql.DB, error) {
+
+	config := &DatabaseConfig{
+		DriverName:         "postgres",
+		MaxOpenConnections: 10,
+		MaxIdleConnections: 5,
+		ConnMaxLifetime:    5 * time.Minute,
+		ConnMaxIdleTime:    time.Minute,
+		RetryOptions:       []RetryOption{Attempts(5), DelayMillisecond(1000), ExponentialJitterBackoff()},
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	db, err := sql.Open(config.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(config.MaxOpenConnections)
+	db.SetMaxIdleConns(config.MaxIdleConnections)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	err = Retry(func() error {
+		err := db.PingContext(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Connecting to database failed, retrying...")
+		}
+		return err
+	}, config.RetryOptions...)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Info().Msgf("Connected to %v database", config.DriverName)
+
+	RegisterReadinessCheck("database", db.PingContext)
+	RegisterShutdownHook("database", db.Close)
+
+	return db, nil
+}
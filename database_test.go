@@ -0,0 +1,90 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDatabaseDriver struct {
+	failures int32
+}
+
+func (d *fakeDatabaseDriver) Open(name string) (driver.Conn, error) {
+	if atomic.AddInt32(&d.failures, -1) >= 0 {
+		return nil, errors.New("connection refused")
+	}
+	return &fakeDatabaseConn{}, nil
+}
+
+type fakeDatabaseConn struct{}
+
+func (c *fakeDatabaseConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c *fakeDatabaseConn) Close() error              { return nil }
+func (c *fakeDatabaseConn) Begin() (driver.Tx, error) { return &fakeDatabaseTx{}, nil }
+
+type fakeDatabaseTx struct{}
+
+func (tx *fakeDatabaseTx) Commit() error   { return nil }
+func (tx *fakeDatabaseTx) Rollback() error { return nil }
+
+var fakeDatabase = &fakeDatabaseDriver{}
+
+func init() {
+	sql.Register("fake", fakeDatabase)
+}
+
+func TestInitDatabase(t *testing.T) {
+
+	t.Run("RetriesUntilConnected", func(t *testing.T) {
+
+		defer resetReadinessChecks()
+		atomic.StoreInt32(&fakeDatabase.failures, 2)
+
+		// act
+		db, err := InitDatabase(context.Background(), "fake://", WithDatabaseDriver("fake"), WithConnectRetryOptions(Attempts(3), DelayMillisecond(1), Fixed()))
+
+		if assert.Nil(t, err) {
+			defer db.Close()
+			assert.Equal(t, 10, db.Stats().MaxOpenConnections)
+		}
+	})
+
+	t.Run("ReturnsErrorWhenAllAttemptsFail", func(t *testing.T) {
+
+		defer resetReadinessChecks()
+		atomic.StoreInt32(&fakeDatabase.failures, 5)
+
+		// act
+		_, err := InitDatabase(context.Background(), "fake://", WithDatabaseDriver("fake"), WithConnectRetryOptions(Attempts(2), DelayMillisecond(1), Fixed()))
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReportsNotReadyWhenDatabaseIsUnreachable", func(t *testing.T) {
+
+		defer resetReadinessChecks()
+		atomic.StoreInt32(&fakeDatabase.failures, 0)
+		db, _ := InitDatabase(context.Background(), "fake://", WithDatabaseDriver("fake"), WithMaxIdleConnections(0))
+		defer db.Close()
+		atomic.StoreInt32(&fakeDatabase.failures, 1)
+		recorder := httptest.NewRecorder()
+
+		// act
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+}
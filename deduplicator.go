@@ -0,0 +1,187 @@
+package foundation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Deduplicator remembers keys for a time window, to drop duplicate webhook deliveries and repeated events
+type Deduplicator interface {
+	// Seen returns true if key has been seen before within its ttl; otherwise it remembers key for ttl and returns false
+	Seen(key string, ttl time.Duration) bool
+	// Snapshot writes the keys that haven't expired to the snapshot file
+	Snapshot() error
+	// Close stops periodic snapshots and writes a final snapshot
+	Close() error
+}
+
+// deduplicatorMinCompactSize avoids compacting the order over and over while only few keys are remembered
+const deduplicatorMinCompactSize = 64
+
+type deduplicatorEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+type deduplicator struct {
+	mutex   sync.Mutex
+	maxKeys int
+	keys    map[string]time.Time
+	// order holds the keys in the order they were added, so the oldest can be evicted and expired keys pruned without scanning all keys
+	order []deduplicatorEntry
+
+	snapshotPath string
+	stop         chan struct{}
+	stopped      sync.WaitGroup
+	closeOnce    sync.Once
+}
+
+// NewDeduplicator returns a Deduplicator remembering at most maxKeys keys, evicting the oldest when full; with a snapshotPath the keys are loaded from and snapshotted to that file every snapshotInterval, so duplicates are still detected after a restart
+func NewDeduplicator(maxKeys int, snapshotPath string, snapshotInterval time.Duration) (Deduplicator, error) {
+	if maxKeys < 1 {
+		maxKeys = 1
+	}
+
+	d := &deduplicator{
+		maxKeys:      maxKeys,
+		keys:         map[string]time.Time{},
+		snapshotPath: snapshotPath,
+		stop:         make(chan struct{}),
+	}
+
+	if snapshotPath == "" {
+		return d, nil
+	}
+
+	if FileExists(snapshotPath) {
+		data, err := ioutil.ReadFile(snapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		entries := map[string]time.Time{}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		for key, expiresAt := range entries {
+			if expiresAt.After(now) {
+				d.add(key, expiresAt)
+			}
+		}
+		log.Info().Msgf("Loaded %v keys from deduplicator snapshot %v", len(d.keys), snapshotPath)
+	}
+
+	if snapshotInterval > 0 {
+		d.stopped.Add(1)
+		go d.snapshotPeriodically(snapshotInterval)
+	}
+
+	return d, nil
+}
+
+func (d *deduplicator) Seen(key string, ttl time.Duration) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	d.prune(now)
+
+	if expiresAt, ok := d.keys[key]; ok && now.Before(expiresAt) {
+		return true
+	}
+
+	d.add(key, now.Add(ttl))
+
+	return false
+}
+
+// add remembers key until expiresAt, evicting the oldest keys if full; must be called with the mutex held
+func (d *deduplicator) add(key string, expiresAt time.Time) {
+	d.keys[key] = expiresAt
+	d.order = append(d.order, deduplicatorEntry{key: key, expiresAt: expiresAt})
+
+	for len(d.keys) > d.maxKeys {
+		d.removeOldest()
+	}
+
+	// keys that expired and were added again leave stale entries behind a long-lived key at the front, which prune can't reach
+	if len(d.order) > 2*len(d.keys)+deduplicatorMinCompactSize {
+		d.compact()
+	}
+}
+
+// compact drops the entries in the order that no longer match the key's current expiry; must be called with the mutex held
+func (d *deduplicator) compact() {
+	order := make([]deduplicatorEntry, 0, len(d.keys))
+	for _, entry := range d.order {
+		if expiresAt, ok := d.keys[entry.key]; ok && expiresAt.Equal(entry.expiresAt) {
+			order = append(order, entry)
+		}
+	}
+	d.order = order
+}
+
+// prune removes expired keys from the front of the order; keys with a longer ttl than keys added after them are removed once they reach the front; must be called with the mutex held
+func (d *deduplicator) prune(now time.Time) {
+	for len(d.order) > 0 && !now.Before(d.order[0].expiresAt) {
+		d.removeOldest()
+	}
+}
+
+func (d *deduplicator) removeOldest() {
+	oldest := d.order[0]
+	d.order = d.order[1:]
+
+	// the key might have been added again after expiring, in which case a later entry in the order is the current one
+	if expiresAt, ok := d.keys[oldest.key]; ok && expiresAt.Equal(oldest.expiresAt) {
+		delete(d.keys, oldest.key)
+	}
+}
+
+func (d *deduplicator) Snapshot() error {
+	if d.snapshotPath == "" {
+		return nil
+	}
+
+	d.mutex.Lock()
+	d.prune(time.Now())
+	data, err := json.Marshal(d.keys)
+	d.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(d.snapshotPath, data, 0644)
+}
+
+func (d *deduplicator) Close() (err error) {
+	d.closeOnce.Do(func() {
+		close(d.stop)
+		d.stopped.Wait()
+		err = d.Snapshot()
+	})
+
+	return err
+}
+
+func (d *deduplicator) snapshotPeriodically(snapshotInterval time.Duration) {
+	defer d.stopped.Done()
+
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := d.Snapshot(); err != nil {
+				log.Warn().Err(err).Msgf("Writing deduplicator snapshot %v failed", d.snapshotPath)
+			}
+		case <-d.stop:
+			return
+		}
+	}
+}
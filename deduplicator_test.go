@@ -0,0 +1,99 @@
+package foundation
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduplicator(t *testing.T) {
+
+	t.Run("ReturnsFalseForFirstAndTrueForRepeatedKey", func(t *testing.T) {
+
+		d, _ := NewDeduplicator(10, "", 0)
+
+		// act
+		first := d.Seen("delivery-1", time.Hour)
+		second := d.Seen("delivery-1", time.Hour)
+
+		assert.False(t, first)
+		assert.True(t, second)
+	})
+
+	t.Run("ReturnsFalseAfterTTLExpired", func(t *testing.T) {
+
+		d, _ := NewDeduplicator(10, "", 0)
+		d.Seen("delivery-1", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		// act
+		seen := d.Seen("delivery-1", time.Hour)
+
+		assert.False(t, seen)
+		assert.True(t, d.Seen("delivery-1", time.Hour))
+	})
+
+	t.Run("EvictsOldestKeyWhenFull", func(t *testing.T) {
+
+		d, _ := NewDeduplicator(2, "", 0)
+		d.Seen("delivery-1", time.Hour)
+		d.Seen("delivery-2", time.Hour)
+		d.Seen("delivery-3", time.Hour)
+
+		// act
+		seen := d.Seen("delivery-1", time.Hour)
+
+		assert.False(t, seen)
+		assert.Equal(t, 2, len(d.(*deduplicator).keys))
+	})
+
+	t.Run("KeepsMemoryBoundedWhenKeysExpire", func(t *testing.T) {
+
+		d, _ := NewDeduplicator(1000, "", 0)
+		for i := 0; i < 100; i++ {
+			d.Seen(fmt.Sprintf("delivery-%v", i), time.Nanosecond)
+		}
+		time.Sleep(time.Millisecond)
+
+		// act
+		d.Seen("delivery-1", time.Hour)
+
+		assert.Equal(t, 1, len(d.(*deduplicator).keys))
+		assert.Equal(t, 1, len(d.(*deduplicator).order))
+	})
+
+	t.Run("KeepsOrderBoundedWhenShortLivedKeysExpireBehindLongLivedKey", func(t *testing.T) {
+
+		d, _ := NewDeduplicator(1000, "", 0)
+		d.Seen("long-lived", time.Hour)
+
+		// act
+		for i := 0; i < 1000; i++ {
+			d.Seen("short-lived", -time.Nanosecond)
+		}
+
+		assert.Equal(t, 2, len(d.(*deduplicator).keys))
+		assert.LessOrEqual(t, len(d.(*deduplicator).order), 2*2+deduplicatorMinCompactSize)
+	})
+
+	t.Run("RestoresKeysFromSnapshotWrittenOnClose", func(t *testing.T) {
+
+		snapshotPath := filepath.Join(t.TempDir(), "deliveries.json")
+		d, _ := NewDeduplicator(10, snapshotPath, time.Hour)
+		d.Seen("delivery-1", time.Hour)
+		d.Seen("delivery-2", time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		d.Close()
+
+		// act
+		restored, err := NewDeduplicator(10, snapshotPath, 0)
+
+		if assert.Nil(t, err) {
+			assert.True(t, restored.Seen("delivery-1", time.Hour))
+			assert.False(t, restored.Seen("delivery-2", time.Hour))
+		}
+	})
+}
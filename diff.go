@@ -0,0 +1,192 @@
+package foundation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces the old and new value of fields tagged with secret:"true", and of added or removed values holding such a field
+const redactedValue = "[redacted]"
+
+// Change is a single difference found by Diff
+type Change struct {
+	// Path is the json name of the changed field, like builder.track, labels[team] or args[1]
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// Diff returns the paths that differ between a and b with their old and new values, for logging config reloads or emitting audit events when settings change; structs are compared field by field, maps key by key and slices item by item. Fields tagged with secret:"true" are reported as changed without their values, as are added, removed or retyped values holding a set secret field
+func Diff(a, b interface{}) []Change {
+	changes := []Change{}
+	diffValues(reflect.ValueOf(a), reflect.ValueOf(b), "", false, &changes)
+
+	return changes
+}
+
+func diffValues(a, b reflect.Value, path string, secret bool, changes *[]Change) {
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		if a.IsValid() != b.IsValid() || (a.IsValid() && !reflect.DeepEqual(a.Interface(), b.Interface())) {
+			addChange(changes, path, a, b, secret)
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				addChange(changes, path, a, b, secret)
+			}
+			return
+		}
+		diffValues(a.Elem(), b.Elem(), path, secret, changes)
+		return
+	case reflect.Struct:
+		if isTextUnmarshaler(a.Type()) || hasNoExportedFields(a.Type()) {
+			break
+		}
+		for i := 0; i < a.NumField(); i++ {
+			structField := a.Type().Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+			name := strings.Split(structField.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = structField.Name
+			}
+			fieldSecret, _ := strconv.ParseBool(structField.Tag.Get("secret"))
+			diffValues(a.Field(i), b.Field(i), joinDiffPath(path, name), secret || fieldSecret, changes)
+		}
+		return
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, key := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(key.Interface())] = key
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			diffValues(a.MapIndex(keys[name]), b.MapIndex(keys[name]), fmt.Sprintf("%v[%v]", path, name), secret, changes)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		length := a.Len()
+		if b.Len() > length {
+			length = b.Len()
+		}
+		for i := 0; i < length; i++ {
+			var itemA, itemB reflect.Value
+			if i < a.Len() {
+				itemA = a.Index(i)
+			}
+			if i < b.Len() {
+				itemB = b.Index(i)
+			}
+			diffValues(itemA, itemB, fmt.Sprintf("%v[%v]", path, i), secret, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		addChange(changes, path, a, b, secret)
+	}
+}
+
+func addChange(changes *[]Change, path string, a, b reflect.Value, secret bool) {
+	change := Change{Path: path}
+	if secret {
+		change.Old = redactedValue
+		change.New = redactedValue
+	} else {
+		change.Old = redactedInterface(a)
+		change.New = redactedInterface(b)
+	}
+
+	*changes = append(*changes, change)
+}
+
+// redactedInterface returns the value of v, or redactedValue if v holds a set secret field, since only one side of a pointer, map value or slice item that is added or removed is walked field by field
+func redactedInterface(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if containsSecret(v, map[uintptr]bool{}) {
+		return redactedValue
+	}
+
+	return v.Interface()
+}
+
+// containsSecret returns true if v holds a non-zero field tagged with secret:"true"; visited guards against cyclic pointers
+func containsSecret(v reflect.Value, visited map[uintptr]bool) bool {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return false
+		}
+		visited[v.Pointer()] = true
+		return containsSecret(v.Elem(), visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			return false
+		}
+		return containsSecret(v.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			structField := v.Type().Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+			if secret, _ := strconv.ParseBool(structField.Tag.Get("secret")); secret && !v.Field(i).IsZero() {
+				return true
+			}
+			if containsSecret(v.Field(i), visited) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if containsSecret(iter.Value(), visited) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if containsSecret(v.Index(i), visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func joinDiffPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// hasNoExportedFields returns true for structs like time.Time that can only be compared as a whole
+func hasNoExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,95 @@
+package foundation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type diffTestConfig struct {
+	Name     string            `json:"name"`
+	Timeout  time.Duration     `json:"timeout"`
+	Since    time.Time         `json:"since"`
+	Password string            `json:"password" secret:"true"`
+	Labels   map[string]string `json:"labels"`
+	Args     []string          `json:"args"`
+	Builder  diffTestBuilder   `json:"builder"`
+	Registry *diffTestBuilder  `json:"registry"`
+	Ignored  string            `json:"-"`
+}
+
+type diffTestBuilder struct {
+	Track string `json:"track"`
+	Token string `json:"token" secret:"true"`
+}
+
+func TestDiff(t *testing.T) {
+
+	t.Run("ReturnsNoChangesForEqualStructs", func(t *testing.T) {
+
+		a := diffTestConfig{Name: "api", Labels: map[string]string{"team": "ci"}, Args: []string{"--verbose"}, Registry: &diffTestBuilder{Track: "stable"}}
+		b := diffTestConfig{Name: "api", Labels: map[string]string{"team": "ci"}, Args: []string{"--verbose"}, Registry: &diffTestBuilder{Track: "stable"}}
+
+		// act
+		changes := Diff(a, b)
+
+		assert.Equal(t, []Change{}, changes)
+	})
+
+	t.Run("ReturnsChangedPathsWithOldAndNewValues", func(t *testing.T) {
+
+		since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		a := diffTestConfig{Name: "api", Timeout: time.Minute, Labels: map[string]string{"team": "ci", "tier": "1"}, Args: []string{"--verbose"}, Builder: diffTestBuilder{Track: "stable"}, Ignored: "a"}
+		b := diffTestConfig{Name: "api", Timeout: time.Hour, Since: since, Labels: map[string]string{"team": "cd"}, Args: []string{"--verbose", "--race"}, Builder: diffTestBuilder{Track: "dev"}, Registry: &diffTestBuilder{}, Ignored: "b"}
+
+		// act
+		changes := Diff(a, b)
+
+		assert.Equal(t, []Change{
+			{Path: "timeout", Old: time.Minute, New: time.Hour},
+			{Path: "since", Old: time.Time{}, New: since},
+			{Path: "labels[team]", Old: "ci", New: "cd"},
+			{Path: "labels[tier]", Old: "1", New: nil},
+			{Path: "args[1]", Old: nil, New: "--race"},
+			{Path: "builder.track", Old: "stable", New: "dev"},
+			{Path: "registry", Old: (*diffTestBuilder)(nil), New: &diffTestBuilder{}},
+		}, changes)
+	})
+
+	t.Run("RedactsFieldsTaggedAsSecret", func(t *testing.T) {
+
+		a := diffTestConfig{Password: "old", Builder: diffTestBuilder{Token: "old"}}
+		b := &diffTestConfig{Password: "new", Builder: diffTestBuilder{Token: "new"}}
+
+		// act
+		changes := Diff(&a, b)
+
+		data, _ := json.Marshal(changes)
+		assert.JSONEq(t, `[{"path":"password","old":"[redacted]","new":"[redacted]"},{"path":"builder.token","old":"[redacted]","new":"[redacted]"}]`, string(data))
+	})
+
+	t.Run("RedactsAddedOrRemovedValuesHoldingSecretFields", func(t *testing.T) {
+
+		a := diffTestConfig{Args: []string{"--verbose"}}
+		b := diffTestConfig{Registry: &diffTestBuilder{Track: "stable", Token: "s3cr3t"}, Labels: map[string]string{"team": "ci"}}
+
+		// act
+		changes := Diff(a, b)
+
+		data, _ := json.Marshal(changes)
+		assert.JSONEq(t, `[{"path":"labels[team]","old":null,"new":"ci"},{"path":"args[0]","old":"--verbose","new":null},{"path":"registry","old":null,"new":"[redacted]"}]`, string(data))
+	})
+
+	t.Run("RedactsSecretFieldsInValuesOfDifferentTypes", func(t *testing.T) {
+
+		a := map[string]interface{}{"vault": "none"}
+		b := map[string]interface{}{"vault": []diffTestBuilder{{Token: "s3cr3t"}}}
+
+		// act
+		changes := Diff(a, b)
+
+		assert.Equal(t, []Change{{Path: "[vault]", Old: "none", New: redactedValue}}, changes)
+	})
+}
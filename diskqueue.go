@@ -0,0 +1,366 @@
+package foundation
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrDiskQueueEmpty is returned by Dequeue when there are no items waiting in the queue
+var ErrDiskQueueEmpty = errors.New("Disk queue is empty")
+
+// ErrDiskQueueItemTooLarge is returned by Enqueue for an item larger than 16MiB
+var ErrDiskQueueItemTooLarge = errors.New("Disk queue item is too large")
+
+const (
+	diskQueueOpEnqueue byte = 'E'
+	diskQueueOpAck     byte = 'A'
+
+	// op + id + length + checksum
+	diskQueueRecordHeaderSize = 1 + 8 + 4 + 4
+
+	// rewrite the file once this many acked records have piled up
+	diskQueueCompactionThreshold = 1000
+
+	// records claiming to be larger are treated as corrupt, so a damaged length doesn't cause a huge allocation
+	diskQueueMaxRecordSize = 16 << 20
+)
+
+// DiskQueueItem is an item stored in a DiskQueue
+type DiskQueueItem struct {
+	ID   uint64
+	Data []byte
+}
+
+// DiskQueue is a durable queue backed by an append-only file on local disk, to buffer items like log lines or status updates while an upstream is unreachable
+type DiskQueue interface {
+	// Enqueue durably appends an item of at most 16MiB to the queue
+	Enqueue(data []byte) error
+	// Dequeue returns the oldest item that isn't in flight; it stays in the queue until acked and gets redelivered after a restart if it never is
+	Dequeue() (*DiskQueueItem, error)
+	// Ack removes a dequeued item from the queue
+	Ack(id uint64) error
+	// Nack returns a dequeued item to the front of the queue
+	Nack(id uint64) error
+	// Len returns the number of items that haven't been acked yet
+	Len() int
+	// Replay sends all queued items in order with retries, acking each item that is sent successfully and stopping at the first one that keeps failing
+	Replay(ctx context.Context, send func(ctx context.Context, data []byte) error, opts ...RetryOption) error
+	// Close closes the underlying file
+	Close() error
+}
+
+type diskQueue struct {
+	mutex    sync.Mutex
+	path     string
+	file     *os.File
+	nextID   uint64
+	pending  []DiskQueueItem
+	inFlight map[uint64]DiskQueueItem
+	acked    int
+}
+
+// NewDiskQueue opens or creates the queue file at path and restores all items that weren't acked before
+func NewDiskQueue(path string) (DiskQueue, error) {
+	q := &diskQueue{
+		path:     path,
+		nextID:   1,
+		inFlight: map[uint64]DiskQueueItem{},
+	}
+
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+
+	// start with a file containing only the items still queued
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+func (q *diskQueue) Enqueue(data []byte) error {
+	if len(data) > diskQueueMaxRecordSize {
+		return ErrDiskQueueItemTooLarge
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	item := DiskQueueItem{ID: q.nextID, Data: append([]byte(nil), data...)}
+
+	if err := q.writeRecord(diskQueueOpEnqueue, item.ID, item.Data); err != nil {
+		return err
+	}
+
+	q.nextID++
+	q.pending = append(q.pending, item)
+
+	return nil
+}
+
+func (q *diskQueue) Dequeue() (*DiskQueueItem, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil, ErrDiskQueueEmpty
+	}
+
+	item := q.pending[0]
+	q.pending = q.pending[1:]
+	q.inFlight[item.ID] = item
+
+	return &item, nil
+}
+
+func (q *diskQueue) Ack(id uint64) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, ok := q.inFlight[id]; !ok {
+		return nil
+	}
+
+	if err := q.writeRecord(diskQueueOpAck, id, nil); err != nil {
+		return err
+	}
+
+	delete(q.inFlight, id)
+	q.acked++
+
+	if q.acked >= diskQueueCompactionThreshold {
+		return q.compact()
+	}
+
+	return nil
+}
+
+func (q *diskQueue) Nack(id uint64) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	item, ok := q.inFlight[id]
+	if !ok {
+		return nil
+	}
+
+	delete(q.inFlight, id)
+	q.pending = append([]DiskQueueItem{item}, q.pending...)
+
+	return nil
+}
+
+func (q *diskQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.pending) + len(q.inFlight)
+}
+
+func (q *diskQueue) Replay(ctx context.Context, send func(ctx context.Context, data []byte) error, opts ...RetryOption) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		item, err := q.Dequeue()
+		if errors.Is(err, ErrDiskQueueEmpty) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = Retry(func() error { return send(ctx, item.Data) }, opts...)
+		if err != nil {
+			if nackErr := q.Nack(item.ID); nackErr != nil {
+				log.Warn().Err(nackErr).Msgf("Returning item %v to disk queue %v failed", item.ID, q.path)
+			}
+			return err
+		}
+
+		if err = q.Ack(item.ID); err != nil {
+			return err
+		}
+	}
+}
+
+func (q *diskQueue) Close() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.file.Close()
+}
+
+// load reads all records from the queue file; corrupt records are skipped by resyncing at the next valid record, a partially written record at the end is ignored
+func (q *diskQueue) load() error {
+	content, err := ioutil.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	items := map[uint64]DiskQueueItem{}
+	var order []uint64
+
+	// corruptFrom is the offset of the first byte that isn't part of a valid record, or -1; lastEnqueuedID is used to estimate how many items were lost in a corrupt region
+	corruptFrom := -1
+	var lastEnqueuedID uint64
+
+	for offset := 0; offset < len(content); {
+		op, id, data, ok := decodeDiskQueueRecord(content[offset:])
+		if !ok {
+			if corruptFrom < 0 {
+				corruptFrom = offset
+			}
+			offset++
+			continue
+		}
+
+		if corruptFrom >= 0 {
+			lost := uint64(0)
+			if op == diskQueueOpEnqueue && id > lastEnqueuedID+1 {
+				lost = id - lastEnqueuedID - 1
+			}
+			log.Warn().Msgf("Skipped %v corrupt bytes in disk queue %v, losing an estimated %v items", offset-corruptFrom, q.path, lost)
+			corruptFrom = -1
+		}
+
+		switch op {
+		case diskQueueOpEnqueue:
+			items[id] = DiskQueueItem{ID: id, Data: data}
+			order = append(order, id)
+			lastEnqueuedID = id
+		case diskQueueOpAck:
+			delete(items, id)
+		}
+
+		if id >= q.nextID {
+			q.nextID = id + 1
+		}
+		offset += diskQueueRecordHeaderSize + len(data)
+	}
+
+	if corruptFrom >= 0 {
+		log.Warn().Msgf("Ignoring %v bytes of truncated or corrupt data at the end of disk queue %v", len(content)-corruptFrom, q.path)
+	}
+
+	for _, id := range order {
+		if item, ok := items[id]; ok {
+			q.pending = append(q.pending, item)
+		}
+	}
+
+	return nil
+}
+
+// decodeDiskQueueRecord decodes the record at the start of b; ok is false if b doesn't start with a complete and valid record
+func decodeDiskQueueRecord(b []byte) (op byte, id uint64, data []byte, ok bool) {
+	if len(b) < diskQueueRecordHeaderSize {
+		return
+	}
+
+	op = b[0]
+	id = binary.BigEndian.Uint64(b[1:9])
+	length := binary.BigEndian.Uint32(b[9:13])
+	checksum := binary.BigEndian.Uint32(b[13:17])
+
+	switch {
+	case op == diskQueueOpAck && length != 0,
+		op != diskQueueOpEnqueue && op != diskQueueOpAck,
+		length > diskQueueMaxRecordSize,
+		int(length) > len(b)-diskQueueRecordHeaderSize:
+		return
+	}
+
+	data = b[diskQueueRecordHeaderSize : diskQueueRecordHeaderSize+int(length)]
+	if crc32.ChecksumIEEE(append(b[:13:13], data...)) != checksum {
+		return
+	}
+
+	return op, id, append([]byte(nil), data...), true
+}
+
+// compact atomically replaces the queue file with one containing only the unacked items
+func (q *diskQueue) compact() error {
+	// the temporary file is opened for appending, so after the rename it becomes the queue file without reopening
+	tmpPath := q.path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	// in-flight items go first, they were dequeued before the pending ones
+	remaining := make([]DiskQueueItem, 0, len(q.inFlight)+len(q.pending))
+	for _, item := range q.inFlight {
+		remaining = append(remaining, item)
+	}
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i].ID < remaining[j].ID })
+	remaining = append(remaining, q.pending...)
+
+	writer := bufio.NewWriter(tmpFile)
+	for _, item := range remaining {
+		if _, err := writer.Write(encodeDiskQueueRecord(diskQueueOpEnqueue, item.ID, item.Data)); err != nil {
+			return discardTmpFile(tmpFile, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return discardTmpFile(tmpFile, err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		return discardTmpFile(tmpFile, err)
+	}
+
+	// only swap files once the rename succeeded, so the queue keeps appending to the current file otherwise
+	if err := os.Rename(tmpPath, q.path); err != nil {
+		return discardTmpFile(tmpFile, err)
+	}
+
+	if q.file != nil {
+		q.file.Close()
+	}
+	q.file = tmpFile
+	q.acked = 0
+
+	return nil
+}
+
+func discardTmpFile(tmpFile *os.File, err error) error {
+	tmpFile.Close()
+	os.Remove(tmpFile.Name())
+
+	return err
+}
+
+func (q *diskQueue) writeRecord(op byte, id uint64, data []byte) error {
+	if _, err := q.file.Write(encodeDiskQueueRecord(op, id, data)); err != nil {
+		return err
+	}
+
+	return q.file.Sync()
+}
+
+func encodeDiskQueueRecord(op byte, id uint64, data []byte) []byte {
+	record := make([]byte, diskQueueRecordHeaderSize+len(data))
+	record[0] = op
+	binary.BigEndian.PutUint64(record[1:9], id)
+	binary.BigEndian.PutUint32(record[9:13], uint32(len(data)))
+	copy(record[diskQueueRecordHeaderSize:], data)
+
+	checksum := crc32.ChecksumIEEE(append(record[:13:13], data...))
+	binary.BigEndian.PutUint32(record[13:17], checksum)
+
+	return record
+}
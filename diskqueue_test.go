@@ -0,0 +1,173 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskQueue(t *testing.T) {
+
+	t.Run("DequeuesItemsInOrderOfEnqueueing", func(t *testing.T) {
+
+		queue, err := NewDiskQueue(filepath.Join(t.TempDir(), "queue"))
+		assert.Nil(t, err)
+		defer queue.Close()
+
+		queue.Enqueue([]byte("first"))
+		queue.Enqueue([]byte("second"))
+
+		// act
+		first, err := queue.Dequeue()
+		assert.Nil(t, err)
+		second, err := queue.Dequeue()
+		assert.Nil(t, err)
+		_, err = queue.Dequeue()
+
+		assert.Equal(t, "first", string(first.Data))
+		assert.Equal(t, "second", string(second.Data))
+		assert.True(t, errors.Is(err, ErrDiskQueueEmpty))
+	})
+
+	t.Run("RestoresUnackedItemsAfterReopening", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "queue")
+		queue, err := NewDiskQueue(path)
+		assert.Nil(t, err)
+
+		queue.Enqueue([]byte("acked"))
+		queue.Enqueue([]byte("in-flight"))
+		queue.Enqueue([]byte("pending"))
+		item, _ := queue.Dequeue()
+		queue.Ack(item.ID)
+		queue.Dequeue()
+		queue.Close()
+
+		// act
+		queue, err = NewDiskQueue(path)
+		assert.Nil(t, err)
+		defer queue.Close()
+
+		assert.Equal(t, 2, queue.Len())
+		first, _ := queue.Dequeue()
+		second, _ := queue.Dequeue()
+		assert.Equal(t, "in-flight", string(first.Data))
+		assert.Equal(t, "pending", string(second.Data))
+	})
+
+	t.Run("IgnoresTruncatedRecordAtTheEnd", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "queue")
+		queue, _ := NewDiskQueue(path)
+		queue.Enqueue([]byte("complete"))
+		queue.Close()
+
+		file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+		file.Write(encodeDiskQueueRecord(diskQueueOpEnqueue, 2, []byte("partial"))[:20])
+		file.Close()
+
+		// act
+		queue, err := NewDiskQueue(path)
+
+		assert.Nil(t, err)
+		defer queue.Close()
+		assert.Equal(t, 1, queue.Len())
+	})
+
+	t.Run("SkipsCorruptRecordAndRestoresLaterOnes", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "queue")
+		corrupt := encodeDiskQueueRecord(diskQueueOpEnqueue, 2, []byte("corrupt"))
+		corrupt[len(corrupt)-1] ^= 0xff
+		content := append(encodeDiskQueueRecord(diskQueueOpEnqueue, 1, []byte("first")), corrupt...)
+		content = append(content, encodeDiskQueueRecord(diskQueueOpEnqueue, 3, []byte("third"))...)
+		os.WriteFile(path, content, 0600)
+
+		// act
+		queue, err := NewDiskQueue(path)
+
+		assert.Nil(t, err)
+		defer queue.Close()
+		assert.Equal(t, 2, queue.Len())
+		first, _ := queue.Dequeue()
+		second, _ := queue.Dequeue()
+		assert.Equal(t, "first", string(first.Data))
+		assert.Equal(t, "third", string(second.Data))
+	})
+
+	t.Run("SkipsRecordClaimingMoreThanMaxRecordSize", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "queue")
+		huge := encodeDiskQueueRecord(diskQueueOpEnqueue, 1, nil)
+		huge[9], huge[10], huge[11], huge[12] = 0xff, 0xff, 0xff, 0xff
+		os.WriteFile(path, append(huge, encodeDiskQueueRecord(diskQueueOpEnqueue, 2, []byte("second"))...), 0600)
+
+		// act
+		queue, err := NewDiskQueue(path)
+
+		assert.Nil(t, err)
+		defer queue.Close()
+		assert.Equal(t, 1, queue.Len())
+	})
+
+	t.Run("RejectsItemsLargerThanMaxRecordSize", func(t *testing.T) {
+
+		queue, _ := NewDiskQueue(filepath.Join(t.TempDir(), "queue"))
+		defer queue.Close()
+
+		// act
+		err := queue.Enqueue(make([]byte, diskQueueMaxRecordSize+1))
+
+		assert.True(t, errors.Is(err, ErrDiskQueueItemTooLarge))
+		assert.Equal(t, 0, queue.Len())
+	})
+
+	t.Run("KeepsAppendingToCurrentFileIfCompactionFails", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "queue")
+		queue, _ := NewDiskQueue(path)
+		defer queue.Close()
+		// a non-empty directory at the queue path makes the rename fail
+		os.Remove(path)
+		os.Mkdir(path, 0700)
+		os.WriteFile(filepath.Join(path, "file"), nil, 0600)
+
+		// act
+		err := queue.(*diskQueue).compact()
+
+		assert.NotNil(t, err)
+		assert.Nil(t, queue.Enqueue([]byte("item")))
+		assert.Equal(t, 1, queue.Len())
+	})
+
+	t.Run("ReplayAcksSentItemsAndStopsAtFirstFailingItem", func(t *testing.T) {
+
+		queue, _ := NewDiskQueue(filepath.Join(t.TempDir(), "queue"))
+		defer queue.Close()
+		queue.Enqueue([]byte("ok"))
+		queue.Enqueue([]byte("fail"))
+		queue.Enqueue([]byte("never-sent"))
+
+		var sent []string
+		send := func(ctx context.Context, data []byte) error {
+			sent = append(sent, string(data))
+			if string(data) == "fail" {
+				return ErrToRetry
+			}
+			return nil
+		}
+
+		// act
+		err := queue.Replay(context.Background(), send, Attempts(2), DelayMillisecond(1), Fixed())
+
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"ok", "fail", "fail"}, sent)
+		assert.Equal(t, 2, queue.Len())
+		next, _ := queue.Dequeue()
+		assert.Equal(t, "fail", string(next.Data))
+	})
+}
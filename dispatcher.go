@@ -0,0 +1,242 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrCircuitOpen is returned when a destination is skipped because it failed too often recently
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+// DispatcherDestination is an url events get posted to
+type DispatcherDestination struct {
+	Name string
+	URL  string
+	// Secret signs the payload with HMAC SHA-256 in the SignatureHeader; not signed if empty
+	Secret []byte
+	// SignatureHeader defaults to X-Signature-256
+	SignatureHeader string
+}
+
+// Dispatcher posts json events to a set of destinations with retries and a circuit breaker per destination
+type Dispatcher interface {
+	// Dispatch posts the event to all destinations and returns an error listing the destinations it failed for
+	Dispatch(ctx context.Context, eventType string, event interface{}) error
+}
+
+type dispatcher struct {
+	destinations []DispatcherDestination
+	client       *http.Client
+	retryOptions []RetryOption
+	breakers     map[string]*circuitBreaker
+}
+
+// NewDispatcher returns a Dispatcher posting to the destinations with client, retrying failed posts according to the retry options; a destination is skipped for 30 seconds after 5 consecutive failed dispatches
+func NewDispatcher(destinations []DispatcherDestination, client *http.Client, opts ...RetryOption) Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	breakers := map[string]*circuitBreaker{}
+	for _, d := range destinations {
+		breakers[d.Name] = newCircuitBreaker(5, 30*time.Second)
+	}
+
+	// only retry server errors, rate limiting and transport errors by default
+	retryOptions := append([]RetryOption{
+		func(c *RetryConfig) { c.IsRetryableError = isRetryableDispatchError },
+		LastErrorOnly(true),
+	}, opts...)
+
+	return &dispatcher{
+		destinations: destinations,
+		client:       client,
+		retryOptions: retryOptions,
+		breakers:     breakers,
+	}
+}
+
+func (d *dispatcher) Dispatch(ctx context.Context, eventType string, event interface{}) error {
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	failed := map[string]error{}
+
+	for _, destination := range d.destinations {
+		wg.Add(1)
+		go func(destination DispatcherDestination) {
+			defer wg.Done()
+
+			if err := d.dispatchTo(ctx, destination, eventType, payload); err != nil {
+				mutex.Lock()
+				failed[destination.Name] = err
+				mutex.Unlock()
+			}
+		}(destination)
+	}
+	wg.Wait()
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(failed))
+	for name, err := range failed {
+		messages = append(messages, fmt.Sprintf("%v: %v", name, err))
+	}
+	sort.Strings(messages)
+
+	return fmt.Errorf("Dispatching %v event failed for %v destination(s):\n%v", eventType, len(failed), strings.Join(messages, "\n"))
+}
+
+func (d *dispatcher) dispatchTo(ctx context.Context, destination DispatcherDestination, eventType string, payload []byte) error {
+
+	breaker := d.breakers[destination.Name]
+	if !breaker.Allow() {
+		d.logDeadLetter(destination, eventType, payload, ErrCircuitOpen)
+		return ErrCircuitOpen
+	}
+
+	err := Retry(func() error {
+		return d.post(ctx, destination, eventType, payload)
+	}, d.retryOptions...)
+
+	breaker.Record(err == nil)
+
+	if err != nil {
+		d.logDeadLetter(destination, eventType, payload, err)
+	}
+
+	return err
+}
+
+func (d *dispatcher) post(ctx context.Context, destination DispatcherDestination, eventType string, payload []byte) error {
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, destination.URL, bytes.NewReader(payload))
+	if err != nil {
+		return unrecoverableError{err}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-Type", eventType)
+	if len(destination.Secret) > 0 {
+		signatureHeader := destination.SignatureHeader
+		if signatureHeader == "" {
+			signatureHeader = "X-Signature-256"
+		}
+		request.Header.Set(signatureHeader, SignHMACSHA256(destination.Secret, payload))
+	}
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &dispatchStatusError{StatusCode: response.StatusCode}
+	}
+
+	return nil
+}
+
+func (d *dispatcher) logDeadLetter(destination DispatcherDestination, eventType string, payload []byte, err error) {
+	log.Error().
+		Err(err).
+		Str("destination", destination.Name).
+		Str("url", destination.URL).
+		Str("eventType", eventType).
+		RawJSON("payload", payload).
+		Msgf("Dispatching %v event to %v failed, dropping it", eventType, destination.Name)
+}
+
+type dispatchStatusError struct {
+	StatusCode int
+}
+
+func (e *dispatchStatusError) Error() string {
+	return fmt.Sprintf("Destination responded with status code %v", e.StatusCode)
+}
+
+func isRetryableDispatchError(err error) bool {
+	if _, ok := err.(unrecoverableError); ok {
+		return false
+	}
+
+	var statusErr *dispatchStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	return err != nil
+}
+
+// circuitBreaker stops calls after a number of consecutive failures and lets a single call through once the cooldown has passed
+type circuitBreaker struct {
+	mutex               sync.Mutex
+	failureThreshold    int
+	cooldown            time.Duration
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+}
+
+func newCircuitBreaker(failureThreshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+	}
+}
+
+// Allow returns whether a call can be made
+func (cb *circuitBreaker) Allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.consecutiveFailures < cb.failureThreshold {
+		return true
+	}
+
+	// half-open: let a single call through to probe whether the destination recovered
+	if !cb.probing && time.Since(cb.openedAt) >= cb.cooldown {
+		cb.probing = true
+		return true
+	}
+
+	return false
+}
+
+// Record registers the outcome of an allowed call
+func (cb *circuitBreaker) Record(success bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.probing = false
+
+	if success {
+		cb.consecutiveFailures = 0
+		return
+	}
+
+	cb.consecutiveFailures++
+	if cb.consecutiveFailures >= cb.failureThreshold {
+		cb.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,131 @@
+package foundation
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+
+	t.Run("PostsSignedEventToAllDestinations", func(t *testing.T) {
+
+		var received int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.True(t, VerifyHMACSHA256([]byte("secret"), body, r.Header.Get("X-Signature-256")))
+			assert.Equal(t, "build.finished", r.Header.Get("X-Event-Type"))
+			assert.Equal(t, `{"status":"succeeded"}`, string(body))
+			atomic.AddInt32(&received, 1)
+		}))
+		defer server.Close()
+
+		dispatcher := NewDispatcher([]DispatcherDestination{
+			{Name: "slack", URL: server.URL, Secret: []byte("secret")},
+			{Name: "github", URL: server.URL, Secret: []byte("secret")},
+		}, nil)
+
+		// act
+		err := dispatcher.Dispatch(context.Background(), "build.finished", map[string]string{"status": "succeeded"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&received))
+	})
+
+	t.Run("RetriesServerErrors", func(t *testing.T) {
+
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer server.Close()
+
+		dispatcher := NewDispatcher([]DispatcherDestination{{Name: "slack", URL: server.URL}}, nil, Attempts(3), DelayMillisecond(1), Fixed())
+
+		// act
+		err := dispatcher.Dispatch(context.Background(), "build.finished", struct{}{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("DoesNotRetryClientErrors", func(t *testing.T) {
+
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		dispatcher := NewDispatcher([]DispatcherDestination{{Name: "slack", URL: server.URL}}, nil, Attempts(3), DelayMillisecond(1), Fixed())
+
+		// act
+		err := dispatcher.Dispatch(context.Background(), "build.finished", struct{}{})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("SkipsDestinationOnceCircuitIsOpen", func(t *testing.T) {
+
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		dispatcher := NewDispatcher([]DispatcherDestination{{Name: "slack", URL: server.URL}}, nil, Attempts(1))
+		for i := 0; i < 5; i++ {
+			dispatcher.Dispatch(context.Background(), "build.finished", struct{}{})
+		}
+
+		// act
+		err := dispatcher.Dispatch(context.Background(), "build.finished", struct{}{})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(5), atomic.LoadInt32(&attempts))
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+
+	t.Run("LetsSingleCallThroughAfterCooldown", func(t *testing.T) {
+
+		breaker := newCircuitBreaker(2, 10*time.Millisecond)
+		breaker.Record(false)
+		breaker.Record(false)
+		assert.False(t, breaker.Allow())
+		time.Sleep(20 * time.Millisecond)
+
+		// act
+		first := breaker.Allow()
+		second := breaker.Allow()
+
+		assert.True(t, first)
+		assert.False(t, second)
+	})
+
+	t.Run("ClosesAfterSuccessfulCall", func(t *testing.T) {
+
+		breaker := newCircuitBreaker(1, 10*time.Millisecond)
+		breaker.Record(false)
+		time.Sleep(20 * time.Millisecond)
+		breaker.Allow()
+
+		// act
+		breaker.Record(true)
+
+		assert.True(t, breaker.Allow())
+		assert.True(t, breaker.Allow())
+	})
+}
@@ -0,0 +1,56 @@
+package foundation
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+// drainStatus is returned by the drain endpoint on GET
+type drainStatus struct {
+	Draining bool           `json:"draining"`
+	Pending  map[string]int `json:"pending"`
+}
+
+// NewDrainHandler returns an http.Handler that on GET returns whether the application is draining and the pending work tracked with TrackedWaitGroup by name, and on POST marks the application as not ready until it exits, so it stops receiving traffic without being killed; with ?shutdown=true it also starts the graceful shutdown by sending SIGTERM to gracefulShutdown. Requests have to be authenticated with the auth options, see NewAuthMiddleware. Register it on the probes port with WithHandler("/drain", handler)
+func NewDrainHandler(gracefulShutdown chan os.Signal, opts ...AuthOption) http.Handler {
+	return NewAuthMiddleware(opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(drainStatus{Draining: IsDraining(), Pending: pendingWork()})
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		shutdown, _ := strconv.ParseBool(r.URL.Query().Get("shutdown"))
+		if shutdown && gracefulShutdown == nil {
+			http.Error(w, "Shutdown is not supported", http.StatusBadRequest)
+			return
+		}
+
+		identity, _ := GetAuthIdentity(r.Context())
+		log.Info().Msgf("Draining requested by %v, marking application as not ready", identity.Name)
+		startDraining()
+
+		if shutdown {
+			log.Info().Msgf("Shutdown requested by %v", identity.Name)
+			select {
+			case gracefulShutdown <- syscall.SIGTERM:
+			default:
+				// a shutdown signal is already pending
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"draining": true, "shutdown": shutdown})
+	}))
+}
@@ -0,0 +1,93 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDrainHandler(t *testing.T) {
+
+	t.Run("MarksApplicationAsNotReady", func(t *testing.T) {
+
+		defer resetReadinessChecks()
+		gracefulShutdown := make(chan os.Signal, 1)
+		handler := NewDrainHandler(gracefulShutdown, WithAPIKey("autoscaler", "secret"))
+		request := httptest.NewRequest(http.MethodPost, "/drain", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, IsDraining())
+		assert.Equal(t, 0, len(gracefulShutdown))
+	})
+
+	t.Run("KeepsApplicationNotReadyWhenReadinessIsSetAgain", func(t *testing.T) {
+
+		defer resetReadinessChecks()
+		handler := NewDrainHandler(nil, WithAPIKey("autoscaler", "secret"))
+		request := httptest.NewRequest(http.MethodPost, "/drain", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		// act
+		SetReadiness(true)
+
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+
+	t.Run("StartsGracefulShutdownIfRequested", func(t *testing.T) {
+
+		defer resetReadinessChecks()
+		gracefulShutdown := make(chan os.Signal, 1)
+		handler := NewDrainHandler(gracefulShutdown, WithAPIKey("operator", "secret"))
+		request := httptest.NewRequest(http.MethodPost, "/drain?shutdown=true", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+
+		// act
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		assert.Equal(t, syscall.SIGTERM, <-gracefulShutdown)
+	})
+
+	t.Run("RejectsUnauthenticatedRequests", func(t *testing.T) {
+
+		handler := NewDrainHandler(nil, WithAPIKey("operator", "secret"))
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/drain", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.False(t, IsDraining())
+	})
+
+	t.Run("ReturnsPendingWorkOnGet", func(t *testing.T) {
+
+		defer resetTrackedWaitGroups()
+		SetReadiness(false)
+		defer SetReadiness(true)
+		trackedWaitGroup := NewTrackedWaitGroup(&sync.WaitGroup{})
+		trackedWaitGroup.Add("webhook", 2)
+		handler := NewDrainHandler(nil, WithAPIKey("operator", "secret"))
+		request := httptest.NewRequest(http.MethodGet, "/drain", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"draining":false,"pending":{"webhook":2}}`, recorder.Body.String())
+	})
+}
@@ -0,0 +1,84 @@
+package foundation
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrNoEndpoints is returned when picking from an empty list of endpoints
+var ErrNoEndpoints = errors.New("No endpoints to pick from")
+
+// Endpoint is an address of a service instance with the zone it runs in
+type Endpoint struct {
+	Address string
+	Zone    string
+}
+
+// EndpointPicker picks an endpoint to send a request to
+type EndpointPicker interface {
+	// Pick returns a random healthy endpoint in the local zone, falling back to healthy endpoints in other zones and then to any endpoint
+	Pick() (Endpoint, error)
+	// MarkFailed excludes the endpoint from being picked for the failure cooldown
+	MarkFailed(endpoint Endpoint)
+}
+
+type zoneAwarePicker struct {
+	mutex           sync.Mutex
+	endpoints       []Endpoint
+	localZone       string
+	failureCooldown time.Duration
+	failedUntil     map[string]time.Time
+}
+
+// NewZoneAwarePicker returns an EndpointPicker preferring endpoints in localZone to reduce cross-zone traffic; failed endpoints are skipped for failureCooldown with +-25% jitter so they don't all get retried at the same time
+func NewZoneAwarePicker(endpoints []Endpoint, localZone string, failureCooldown time.Duration) EndpointPicker {
+	return &zoneAwarePicker{
+		endpoints:       endpoints,
+		localZone:       localZone,
+		failureCooldown: failureCooldown,
+		failedUntil:     map[string]time.Time{},
+	}
+}
+
+func (p *zoneAwarePicker) Pick() (Endpoint, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.endpoints) == 0 {
+		return Endpoint{}, ErrNoEndpoints
+	}
+
+	now := time.Now()
+	local := []Endpoint{}
+	remote := []Endpoint{}
+	for _, endpoint := range p.endpoints {
+		if now.Before(p.failedUntil[endpoint.Address]) {
+			continue
+		}
+		if endpoint.Zone == p.localZone {
+			local = append(local, endpoint)
+		} else {
+			remote = append(remote, endpoint)
+		}
+	}
+
+	switch {
+	case len(local) > 0:
+		return local[rand.Intn(len(local))], nil
+	case len(remote) > 0:
+		return remote[rand.Intn(len(remote))], nil
+	}
+
+	// all endpoints failed recently, any of them is better than none
+	return p.endpoints[rand.Intn(len(p.endpoints))], nil
+}
+
+func (p *zoneAwarePicker) MarkFailed(endpoint Endpoint) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	cooldown := time.Duration(ApplyJitter(int(p.failureCooldown/time.Millisecond))) * time.Millisecond
+	p.failedUntil[endpoint.Address] = time.Now().Add(cooldown)
+}
@@ -0,0 +1,76 @@
+package foundation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoneAwarePicker(t *testing.T) {
+
+	endpoints := []Endpoint{
+		{Address: "10.0.1.1:5000", Zone: "europe-west1-b"},
+		{Address: "10.0.2.1:5000", Zone: "europe-west1-c"},
+		{Address: "10.0.2.2:5000", Zone: "europe-west1-c"},
+	}
+
+	t.Run("PicksEndpointInLocalZone", func(t *testing.T) {
+
+		picker := NewZoneAwarePicker(endpoints, "europe-west1-b", time.Minute)
+
+		for i := 0; i < 10; i++ {
+			// act
+			endpoint, err := picker.Pick()
+
+			assert.Nil(t, err)
+			assert.Equal(t, "10.0.1.1:5000", endpoint.Address)
+		}
+	})
+
+	t.Run("FallsBackToOtherZonesIfLocalEndpointFailed", func(t *testing.T) {
+
+		picker := NewZoneAwarePicker(endpoints, "europe-west1-b", time.Minute)
+		picker.MarkFailed(endpoints[0])
+
+		// act
+		endpoint, err := picker.Pick()
+
+		assert.Nil(t, err)
+		assert.Equal(t, "europe-west1-c", endpoint.Zone)
+	})
+
+	t.Run("PicksLocalEndpointAgainAfterCooldown", func(t *testing.T) {
+
+		picker := NewZoneAwarePicker(endpoints, "europe-west1-b", 4*time.Millisecond)
+		picker.MarkFailed(endpoints[0])
+		time.Sleep(10 * time.Millisecond)
+
+		// act
+		endpoint, _ := picker.Pick()
+
+		assert.Equal(t, "10.0.1.1:5000", endpoint.Address)
+	})
+
+	t.Run("PicksAnyEndpointIfAllFailed", func(t *testing.T) {
+
+		picker := NewZoneAwarePicker(endpoints[:1], "europe-west1-b", time.Minute)
+		picker.MarkFailed(endpoints[0])
+
+		// act
+		endpoint, err := picker.Pick()
+
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0.1.1:5000", endpoint.Address)
+	})
+
+	t.Run("ReturnsErrorWithoutEndpoints", func(t *testing.T) {
+
+		picker := NewZoneAwarePicker(nil, "europe-west1-b", time.Minute)
+
+		// act
+		_, err := picker.Pick()
+
+		assert.Equal(t, ErrNoEndpoints, err)
+	})
+}
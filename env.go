@@ -0,0 +1,36 @@
+package foundation
+
+import (
+	"os"
+)
+
+// SetEnv sets the envvars and returns a function restoring their previous values, unsetting the ones that weren't set before; on error the envvars set so far are restored
+func SetEnv(env map[string]string) (restore func(), err error) {
+	previous := map[string]*string{}
+
+	restore = func() {
+		for name, value := range previous {
+			if value == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *value)
+			}
+		}
+	}
+
+	for name, value := range env {
+		if _, ok := previous[name]; !ok {
+			if old, isSet := os.LookupEnv(name); isSet {
+				previous[name] = &old
+			} else {
+				previous[name] = nil
+			}
+		}
+		if err = os.Setenv(name, value); err != nil {
+			restore()
+			return func() {}, err
+		}
+	}
+
+	return restore, nil
+}
@@ -0,0 +1,43 @@
+package foundation
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetEnv(t *testing.T) {
+
+	t.Run("RestoresPreviousValues", func(t *testing.T) {
+
+		os.Setenv("ESTAFETTE_TEST_SET_ENV", "before")
+		defer os.Unsetenv("ESTAFETTE_TEST_SET_ENV")
+
+		// act
+		restore, err := SetEnv(map[string]string{"ESTAFETTE_TEST_SET_ENV": "during"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "during", os.Getenv("ESTAFETTE_TEST_SET_ENV"))
+		restore()
+		assert.Equal(t, "before", os.Getenv("ESTAFETTE_TEST_SET_ENV"))
+	})
+
+	t.Run("UnsetsEnvvarsThatWerentSetBefore", func(t *testing.T) {
+
+		// act
+		restore, _ := SetEnv(map[string]string{"ESTAFETTE_TEST_SET_ENV": "during"})
+
+		restore()
+		_, isSet := os.LookupEnv("ESTAFETTE_TEST_SET_ENV")
+		assert.False(t, isSet)
+	})
+
+	t.Run("ReturnsErrorForInvalidName", func(t *testing.T) {
+
+		// act
+		_, err := SetEnv(map[string]string{"": "value"})
+
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,125 @@
+package foundation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ComputeETag returns a strong etag for the response body, for responses that are byte-for-byte identical whenever the etag matches
+func ComputeETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// ComputeWeakETag returns a weak etag for a version of a resource, for responses that are semantically equivalent but not necessarily byte-for-byte identical, like json with a random map order
+func ComputeWeakETag(version string) string {
+	return `W/` + ComputeETag([]byte(version))
+}
+
+// CheckNotModified sets the ETag and Last-Modified response headers if not empty or zero and writes a 304 Not Modified response if the If-None-Match or If-Modified-Since request headers show the client already has this version; in that case it returns true and the handler should return without writing a body
+//
+//	if foundation.CheckNotModified(w, r, foundation.ComputeWeakETag(config.Version), config.UpdatedAt) {
+//		return
+//	}
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if !isNotModified(r, etag, lastModified) {
+		return false
+	}
+
+	// a 304 response shouldn't carry headers describing a body
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+// NewConditionalMiddleware buffers successful GET responses, sets a strong ETag computed from the body and answers with 304 Not Modified if it matches If-None-Match; meant for small endpoints that are polled frequently, like static config
+func NewConditionalMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buffered := &bufferedResponseWriter{header: http.Header{}, status: http.StatusOK}
+			next.ServeHTTP(buffered, r)
+
+			for key, values := range buffered.header {
+				w.Header()[key] = values
+			}
+
+			if buffered.status == http.StatusOK && w.Header().Get("ETag") == "" {
+				if CheckNotModified(w, r, ComputeETag(buffered.body.Bytes()), time.Time{}) {
+					return
+				}
+			}
+
+			w.WriteHeader(buffered.status)
+			w.Write(buffered.body.Bytes())
+		})
+	}
+}
+
+// isNotModified evaluates the conditional request headers; If-None-Match takes precedence over If-Modified-Since
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// If-None-Match uses the weak comparison
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		// the header has second precision
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedResponseWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
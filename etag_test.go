@@ -0,0 +1,122 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeETag(t *testing.T) {
+
+	t.Run("ReturnsSameQuotedETagForSameBody", func(t *testing.T) {
+
+		// act
+		etag := ComputeETag([]byte(`{"builds":[]}`))
+
+		assert.Equal(t, ComputeETag([]byte(`{"builds":[]}`)), etag)
+		assert.NotEqual(t, ComputeETag([]byte(`{"builds":[1]}`)), etag)
+		assert.Equal(t, `"`, etag[:1])
+	})
+
+	t.Run("ReturnsWeakETagForVersion", func(t *testing.T) {
+
+		// act
+		etag := ComputeWeakETag("42")
+
+		assert.Equal(t, `W/"`, etag[:3])
+	})
+}
+
+func TestCheckNotModified(t *testing.T) {
+
+	t.Run("ReturnsTrueAndWritesNotModifiedIfETagMatchesWeakly", func(t *testing.T) {
+
+		etag := ComputeWeakETag("42")
+		request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		request.Header.Set("If-None-Match", `"abc", `+etag[2:])
+		recorder := httptest.NewRecorder()
+
+		// act
+		notModified := CheckNotModified(recorder, request, etag, time.Time{})
+
+		assert.True(t, notModified)
+		assert.Equal(t, http.StatusNotModified, recorder.Code)
+		assert.Equal(t, etag, recorder.Header().Get("ETag"))
+	})
+
+	t.Run("ReturnsFalseIfETagDoesNotMatch", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		request.Header.Set("If-None-Match", `"abc"`)
+		recorder := httptest.NewRecorder()
+
+		// act
+		notModified := CheckNotModified(recorder, request, ComputeETag([]byte("config")), time.Time{})
+
+		assert.False(t, notModified)
+	})
+
+	t.Run("ReturnsTrueIfNotModifiedSince", func(t *testing.T) {
+
+		lastModified := time.Date(2021, 3, 1, 12, 0, 0, 500, time.UTC)
+		request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		request.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
+		recorder := httptest.NewRecorder()
+
+		// act
+		notModified := CheckNotModified(recorder, request, "", lastModified)
+
+		assert.True(t, notModified)
+		assert.Equal(t, lastModified.Format(http.TimeFormat), recorder.Header().Get("Last-Modified"))
+	})
+
+	t.Run("ReturnsFalseIfModifiedSince", func(t *testing.T) {
+
+		lastModified := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+		request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		request.Header.Set("If-Modified-Since", lastModified.Add(-time.Minute).Format(http.TimeFormat))
+		recorder := httptest.NewRecorder()
+
+		// act
+		notModified := CheckNotModified(recorder, request, "", lastModified)
+
+		assert.False(t, notModified)
+	})
+}
+
+func TestNewConditionalMiddleware(t *testing.T) {
+
+	handler := NewConditionalMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"builds":[]}`))
+	}))
+
+	t.Run("SetsETagOnResponse", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, ComputeETag([]byte(`{"builds":[]}`)), recorder.Header().Get("ETag"))
+		assert.Equal(t, `{"builds":[]}`, recorder.Body.String())
+	})
+
+	t.Run("WritesNotModifiedWithoutBodyIfETagMatches", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		request.Header.Set("If-None-Match", ComputeETag([]byte(`{"builds":[]}`)))
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotModified, recorder.Code)
+		assert.Equal(t, 0, recorder.Body.Len())
+	})
+}
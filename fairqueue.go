@@ -0,0 +1,173 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ErrFairQueueClosed is returned when pushing to or popping from a closed fair queue that has no items left
+var ErrFairQueueClosed = errors.New("Fair queue is closed")
+
+var (
+	fairQueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "foundation_fair_queue_depth",
+			Help: "Number of items waiting in a fair queue by queue and key.",
+		},
+		[]string{"queue", "key"},
+	)
+	fairQueueWaitSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "foundation_fair_queue_wait_seconds",
+			Help:    "Time items waited in a fair queue by queue.",
+			Buckets: DefaultDurationBuckets(),
+		},
+		// no key label, so every key ever seen doesn't keep a histogram series for the life of the process; per key detail is on the depth gauge
+		[]string{"queue"},
+	)
+)
+
+// FairQueue is a concurrency-safe queue that interleaves items across keys, like repository or organization, so a single noisy key can't starve the others
+type FairQueue[T any] interface {
+	// Push adds an item to the back of the queue for key
+	Push(key string, item T) error
+	// Pop returns the oldest item of the next key in round-robin order, blocking until an item is available or ctx is done
+	Pop(ctx context.Context) (key string, item T, err error)
+	// Len returns the total number of items in the queue
+	Len() int
+	// Close wakes up blocked Pop calls; items still in the queue can be popped, after that Pop returns ErrFairQueueClosed
+	Close()
+}
+
+type fairQueueItem[T any] struct {
+	item     T
+	queuedAt time.Time
+}
+
+type fairQueue[T any] struct {
+	name   string
+	mutex  sync.Mutex
+	items  map[string][]fairQueueItem[T]
+	keys   []string
+	length int
+	closed bool
+	// available is signaled, without blocking, whenever an item is pushed or the queue is closed
+	available chan struct{}
+}
+
+// NewFairQueue returns an empty FairQueue; name is used as label for the queue depth and wait time metrics
+func NewFairQueue[T any](name string) FairQueue[T] {
+	return &fairQueue[T]{
+		name:      name,
+		items:     map[string][]fairQueueItem[T]{},
+		available: make(chan struct{}, 1),
+	}
+}
+
+func (q *fairQueue[T]) Push(key string, item T) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return ErrFairQueueClosed
+	}
+
+	if len(q.items[key]) == 0 {
+		// a key gets in line behind the keys already waiting
+		q.keys = append(q.keys, key)
+	}
+	q.items[key] = append(q.items[key], fairQueueItem[T]{item: item, queuedAt: time.Now()})
+	q.length++
+	fairQueueDepth.WithLabelValues(q.name, key).Inc()
+
+	q.signal()
+
+	return nil
+}
+
+func (q *fairQueue[T]) Pop(ctx context.Context) (string, T, error) {
+	for {
+		q.mutex.Lock()
+		if len(q.keys) > 0 {
+			key, item := q.popLocked()
+			q.mutex.Unlock()
+			return key, item, nil
+		}
+		closed := q.closed
+		q.mutex.Unlock()
+
+		var empty T
+		if closed {
+			return "", empty, ErrFairQueueClosed
+		}
+
+		select {
+		case <-q.available:
+		case <-ctx.Done():
+			return "", empty, ctx.Err()
+		}
+	}
+}
+
+// popLocked takes the first item of the first key and moves the key to the back of the line if it has more items; must be called with the mutex held
+func (q *fairQueue[T]) popLocked() (string, T) {
+	key := q.keys[0]
+	q.keys = q.keys[1:]
+
+	queued := q.items[key][0]
+	q.items[key] = q.items[key][1:]
+	if len(q.items[key]) > 0 {
+		q.keys = append(q.keys, key)
+		// another Pop might be waiting
+		q.signal()
+	} else {
+		delete(q.items, key)
+	}
+	q.length--
+
+	if len(q.items[key]) == 0 {
+		fairQueueDepth.DeleteLabelValues(q.name, key)
+	} else {
+		fairQueueDepth.WithLabelValues(q.name, key).Dec()
+	}
+	fairQueueWaitSeconds.WithLabelValues(q.name).Observe(time.Since(queued.queuedAt).Seconds())
+
+	return key, queued.item
+}
+
+func (q *fairQueue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.length
+}
+
+func (q *fairQueue[T]) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.available)
+}
+
+// signal wakes up a waiting Pop without blocking; must be called with the mutex held
+func (q *fairQueue[T]) signal() {
+	if q.closed {
+		return
+	}
+	select {
+	case q.available <- struct{}{}:
+	default:
+	}
+}
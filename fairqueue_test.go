@@ -0,0 +1,98 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairQueue(t *testing.T) {
+
+	t.Run("InterleavesItemsAcrossKeys", func(t *testing.T) {
+
+		queue := NewFairQueue[int]("builds")
+		queue.Push("noisy-repo", 1)
+		queue.Push("noisy-repo", 2)
+		queue.Push("noisy-repo", 3)
+		queue.Push("quiet-repo", 4)
+		queue.Push("other-repo", 5)
+
+		// act
+		popped := []int{}
+		for queue.Len() > 0 {
+			_, item, _ := queue.Pop(context.Background())
+			popped = append(popped, item)
+		}
+
+		assert.Equal(t, []int{1, 4, 5, 2, 3}, popped)
+	})
+
+	t.Run("BlocksUntilItemIsPushed", func(t *testing.T) {
+
+		queue := NewFairQueue[string]("builds")
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			queue.Push("ziplinee-ci", "build-1")
+		}()
+
+		// act
+		key, item, err := queue.Pop(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "ziplinee-ci", key)
+		assert.Equal(t, "build-1", item)
+	})
+
+	t.Run("ReturnsContextErrorIfNoItemIsPushedInTime", func(t *testing.T) {
+
+		queue := NewFairQueue[string]("builds")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// act
+		_, _, err := queue.Pop(ctx)
+
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("ReturnsRemainingItemsAndThenErrFairQueueClosedAfterClose", func(t *testing.T) {
+
+		queue := NewFairQueue[string]("builds")
+		queue.Push("ziplinee-ci", "build-1")
+		queue.Close()
+
+		// act
+		_, item, err := queue.Pop(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "build-1", item)
+		_, _, err = queue.Pop(context.Background())
+		assert.Equal(t, ErrFairQueueClosed, err)
+		assert.Equal(t, ErrFairQueueClosed, queue.Push("ziplinee-ci", "build-2"))
+	})
+
+	t.Run("KeepsNoSeriesPerKeyOnceKeysAreEmpty", func(t *testing.T) {
+
+		queue := NewFairQueue[int](fmt.Sprintf("deployments-%v", time.Now().UnixNano()))
+		depthSeries := testutil.CollectAndCount(fairQueueDepth)
+		waitSeries := testutil.CollectAndCount(fairQueueWaitSeconds)
+		for _, key := range []string{"repo-a", "repo-b", "repo-c"} {
+			queue.Push(key, 1)
+		}
+
+		// act
+		for i := 0; i < 3; i++ {
+			queue.Pop(context.Background())
+		}
+
+		assert.Equal(t, depthSeries, testutil.CollectAndCount(fairQueueDepth))
+		assert.Equal(t, waitSeries+1, testutil.CollectAndCount(fairQueueWaitSeconds))
+	})
+}
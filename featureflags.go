@@ -0,0 +1,144 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// FeatureFlag is the state of a single feature flag
+type FeatureFlag struct {
+	Enabled bool   `yaml:"enabled"`
+	Variant string `yaml:"variant"`
+}
+
+// FeatureFlags allows to roll out behaviour gradually and toggle it without redeploying
+type FeatureFlags interface {
+	// IsEnabled returns whether the flag is enabled; unknown flags are disabled
+	IsEnabled(name string) bool
+	// Variant returns the variant of the flag, for example to pick between multiple implementations; empty for unknown flags
+	Variant(name string) string
+}
+
+type featureFlags struct {
+	mutex    sync.RWMutex
+	defaults map[string]FeatureFlag
+	flags    map[string]FeatureFlag
+}
+
+// NewFeatureFlagsFromEnv returns feature flags read from envvars ESTAFETTE_FEATURE_<UPPER_SNAKE_CASED_NAME>, falling back to defaults; 'true' or 'false' toggles a flag, any other value enables it with that value as variant
+func NewFeatureFlagsFromEnv(defaults map[string]FeatureFlag) FeatureFlags {
+
+	flags := map[string]FeatureFlag{}
+	for _, e := range os.Environ() {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], "ESTAFETTE_FEATURE_") {
+			continue
+		}
+
+		// match the envvar against the flag names in defaults to restore the original name
+		envName := strings.TrimPrefix(kv[0], "ESTAFETTE_FEATURE_")
+		name := strings.ToLower(envName)
+		for defaultName := range defaults {
+			if ToUpperSnakeCase(defaultName) == envName {
+				name = defaultName
+				break
+			}
+		}
+
+		flags[name] = parseFeatureFlagValue(kv[1])
+	}
+
+	return &featureFlags{
+		defaults: defaults,
+		flags:    flags,
+	}
+}
+
+// NewFeatureFlagsFromFile returns feature flags read from a yaml file mapping flag names to their enabled state and variant, falling back to defaults; with watch set to true the file is reloaded whenever it changes
+func NewFeatureFlagsFromFile(filePath string, defaults map[string]FeatureFlag, watch bool) (FeatureFlags, error) {
+
+	flags, err := readFeatureFlagsFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ff := &featureFlags{
+		defaults: defaults,
+		flags:    flags,
+	}
+
+	if watch {
+		WatchForFileChanges(filePath, func(event fsnotify.Event) {
+			log.Info().Msgf("Feature flags file %v changed, reloading...", filePath)
+
+			flags, err := readFeatureFlagsFile(filePath)
+			if err != nil {
+				log.Error().Err(err).Msg("Reloading feature flags failed, keeping previous flags")
+				return
+			}
+
+			ff.mutex.Lock()
+			changes := Diff(ff.flags, flags)
+			ff.flags = flags
+			ff.mutex.Unlock()
+
+			log.Info().Interface("changes", changes).Msgf("Reloaded feature flags with %v changes", len(changes))
+		})
+	}
+
+	return ff, nil
+}
+
+func (ff *featureFlags) IsEnabled(name string) bool {
+	return ff.get(name).Enabled
+}
+
+func (ff *featureFlags) Variant(name string) string {
+	return ff.get(name).Variant
+}
+
+func (ff *featureFlags) get(name string) FeatureFlag {
+	ff.mutex.RLock()
+	defer ff.mutex.RUnlock()
+
+	if flag, ok := ff.flags[name]; ok {
+		return flag
+	}
+
+	return ff.defaults[name]
+}
+
+func readFeatureFlagsFile(filePath string) (map[string]FeatureFlag, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := map[string]FeatureFlag{}
+	if err := yaml.Unmarshal(data, &flags); err != nil {
+		return nil, err
+	}
+
+	return flags, nil
+}
+
+func parseFeatureFlagValue(value string) FeatureFlag {
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		return FeatureFlag{Enabled: enabled}
+	}
+	if value == "" {
+		return FeatureFlag{}
+	}
+
+	return FeatureFlag{Enabled: true, Variant: value}
+}
@@ -0,0 +1,83 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFeatureFlagsFromEnv(t *testing.T) {
+
+	t.Run("ReturnsDefaultIfEnvvarIsNotSet", func(t *testing.T) {
+
+		// act
+		flags := NewFeatureFlagsFromEnv(map[string]FeatureFlag{"newScheduler": {Enabled: true, Variant: "b"}})
+
+		assert.True(t, flags.IsEnabled("newScheduler"))
+		assert.Equal(t, "b", flags.Variant("newScheduler"))
+	})
+
+	t.Run("ReturnsDisabledForUnknownFlag", func(t *testing.T) {
+
+		// act
+		flags := NewFeatureFlagsFromEnv(nil)
+
+		assert.False(t, flags.IsEnabled("unknown"))
+		assert.Equal(t, "", flags.Variant("unknown"))
+	})
+
+	t.Run("OverridesDefaultWithBooleanEnvvar", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_FEATURE_NEW_SCHEDULER", "false")
+
+		// act
+		flags := NewFeatureFlagsFromEnv(map[string]FeatureFlag{"newScheduler": {Enabled: true}})
+
+		assert.False(t, flags.IsEnabled("newScheduler"))
+	})
+
+	t.Run("EnablesFlagWithVariantForNonBooleanEnvvar", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_FEATURE_NEW_SCHEDULER", "fair-queue")
+
+		// act
+		flags := NewFeatureFlagsFromEnv(map[string]FeatureFlag{"newScheduler": {}})
+
+		assert.True(t, flags.IsEnabled("newScheduler"))
+		assert.Equal(t, "fair-queue", flags.Variant("newScheduler"))
+	})
+}
+
+func TestNewFeatureFlagsFromFile(t *testing.T) {
+
+	t.Run("ReturnsFlagsFromFileAndFallsBackToDefaults", func(t *testing.T) {
+
+		filePath := filepath.Join(t.TempDir(), "flags.yaml")
+		ioutil.WriteFile(filePath, []byte("newScheduler:\n  enabled: true\n  variant: b\n"), 0644)
+
+		// act
+		flags, err := NewFeatureFlagsFromFile(filePath, map[string]FeatureFlag{"cacheV2": {Enabled: true}}, false)
+
+		if assert.Nil(t, err) {
+			assert.True(t, flags.IsEnabled("newScheduler"))
+			assert.Equal(t, "b", flags.Variant("newScheduler"))
+			assert.True(t, flags.IsEnabled("cacheV2"))
+		}
+	})
+
+	t.Run("ReturnsErrorIfFileIsInvalidYaml", func(t *testing.T) {
+
+		filePath := filepath.Join(t.TempDir(), "flags.yaml")
+		ioutil.WriteFile(filePath, []byte("newScheduler: [\n"), 0644)
+
+		// act
+		_, err := NewFeatureFlagsFromFile(filePath, nil, false)
+
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,106 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchForFileChanges waits for a change to the provided file path and then executes the function; override the logger with WithLogger; HandleShutdown waits for a running function to finish, see WithCallbackTimeout and WithSkipCallbacksOnShutdown
+func WatchForFileChanges(filePath string, functionOnChange func(fsnotify.Event), opts ...InitOption) {
+	config := newInitConfig(0, opts)
+
+	// copied from https://github.com/spf13/viper/blob/v1.3.1/viper.go#L282-L348
+	initWG := sync.WaitGroup{}
+	initWG.Add(1)
+	go func() {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			config.Logger.Fatal().Err(err).Msg("Creating file system watcher failed")
+		}
+		defer watcher.Close()
+
+		// we have to watch the entire directory to pick up renames/atomic saves in a cross-platform way
+		file := filepath.Clean(filePath)
+		fileDir, _ := filepath.Split(file)
+		realFile, _ := filepath.EvalSymlinks(filePath)
+
+		eventsWG := sync.WaitGroup{}
+		eventsWG.Add(1)
+		go func() {
+			for {
+				select {
+				case event, ok := <-watcher.Events:
+					if !ok { // 'Events' channel is closed
+						eventsWG.Done()
+						return
+					}
+					currentFile, _ := filepath.EvalSymlinks(filePath)
+					// we only care about the key file with the following cases:
+					// 1 - if the key file was modified or created
+					// 2 - if the real path to the key file changed (eg: k8s ConfigMap/Secret replacement)
+					const writeOrCreateMask = fsnotify.Write | fsnotify.Create
+					if (filepath.Clean(event.Name) == file &&
+						event.Op&writeOrCreateMask != 0) ||
+						(currentFile != "" && currentFile != realFile) {
+						realFile = currentFile
+
+						if startFileWatchCallback(config.SkipCallbacksOnShutdown) {
+							func() {
+								defer finishFileWatchCallback()
+								functionOnChange(event)
+							}()
+						} else {
+							config.Logger.Info().Msgf("Skipping callback for change to %v during shutdown", event.Name)
+						}
+					} else if filepath.Clean(event.Name) == file &&
+						event.Op&fsnotify.Remove&fsnotify.Remove != 0 {
+						eventsWG.Done()
+						return
+					}
+
+				case err, ok := <-watcher.Errors:
+					if ok { // 'Errors' channel is not closed
+						config.Logger.Warn().Err(err).Msg("Watcher error")
+					}
+					eventsWG.Done()
+					return
+				}
+			}
+		}()
+		watcher.Add(fileDir)
+		initWG.Done()   // done initalizing the watch in this go routine, so the parent routine can move on...
+		eventsWG.Wait() // now, wait for event loop to end in this go-routine...
+	}()
+	initWG.Wait() // make sure that the go routine above fully ended before returning
+}
+
+// ReExecOnChange watches the paths, like the binary itself and critical config files, and when one of them changes starts a new copy of the process with the same arguments, hands over the listeners registered with RegisterListener and sends SIGTERM to the current process so it drains through HandleGracefulShutdown; meant for processes running on vms without an orchestrator to restart them. The new process has to take over the listeners with InheritedListener. Changes are debounced with the period set with WithReExecDebounce, so a binary that's still being written isn't started. If the new process exits within the grace period set with WithReExecGracePeriod, the current process keeps running; once a new process has started, further changes are ignored
+func ReExecOnChange(paths []string, opts ...InitOption) {
+	config := newInitConfig(0, opts)
+
+	trigger := &reExecTrigger{
+		debounce: config.ReExecDebounce,
+		logger:   config.Logger,
+		reExec: func() error {
+			return reExec(config.ReExecGracePeriod)
+		},
+		drain: func() {
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				p.Signal(syscall.SIGTERM)
+			}
+		},
+	}
+
+	for _, path := range paths {
+		WatchForFileChanges(path, func(event fsnotify.Event) {
+			trigger.changed(event.Name)
+		}, opts...)
+	}
+}
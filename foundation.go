@@ -5,28 +5,29 @@ import (
 	"math/rand"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"regexp"
+	"strings"
 	"sync"
 	"unicode"
 
 	"syscall"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/rs/zerolog/log"
 )
 
 var (
 	// seed random number
 	r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	// rand.Rand isn't safe for concurrent use
+	rMutex sync.Mutex
 )
 
 // InitGracefulShutdownHandling generates the channel that listens to SIGTERM and a waitgroup to use for finishing work when shutting down
 func InitGracefulShutdownHandling() (gracefulShutdown chan os.Signal, waitGroup *sync.WaitGroup) {
 
 	// define channel used to gracefully shutdown the application
-	gracefulShutdown = make(chan os.Signal)
+	gracefulShutdown = make(chan os.Signal, 1)
 
 	signal.Notify(gracefulShutdown, syscall.SIGTERM, syscall.SIGINT)
 
@@ -35,21 +36,74 @@ func InitGracefulShutdownHandling() (gracefulShutdown chan os.Signal, waitGroup
 	return gracefulShutdown, waitGroup
 }
 
-// HandleGracefulShutdown waits for SIGTERM to unblock gracefulShutdown and waits for the waitgroup to await pending work
+// HandleGracefulShutdown waits for SIGTERM to unblock gracefulShutdown, waits for the waitgroup to await pending work and then runs the hooks registered with RegisterShutdownHook and flushes the writers registered with FlushOnShutdown
 func HandleGracefulShutdown(gracefulShutdown chan os.Signal, waitGroup *sync.WaitGroup, functionsOnShutdown ...func()) {
+	HandleShutdown(gracefulShutdown, waitGroup, WithFunctionsOnShutdown(functionsOnShutdown...))
+}
+
+// HandleShutdown is HandleGracefulShutdown configurable with InitOption, for example to stop waiting for pending work after a timeout
+func HandleShutdown(gracefulShutdown chan os.Signal, waitGroup *sync.WaitGroup, opts ...InitOption) {
+	config := newInitConfig(0, opts)
 
 	signalReceived := <-gracefulShutdown
-	log.Info().
+	callbacksDone := beginShutdown()
+	config.Logger.Info().
 		Msgf("Received signal %v. Waiting for running tasks to finish...", signalReceived)
 
+	// give a config reload triggered right before the signal the chance to finish, so it doesn't race with teardown
+	select {
+	case <-callbacksDone:
+	case <-config.Clock.After(config.CallbackTimeout):
+		config.Logger.Warn().Msgf("File watch callbacks didn't finish within %v, shutting down anyway", config.CallbackTimeout)
+	}
+
 	// execute any passed function
-	for _, f := range functionsOnShutdown {
+	for _, f := range config.FunctionsOnShutdown {
 		f()
 	}
 
-	waitGroup.Wait()
+	waitForRunningTasks(config, waitGroup)
+
+	runShutdownHooks()
+
+	config.Logger.Info().Msg("Shutting down...")
+
+	// flush last so everything logged or recorded during shutdown is included
+	runFlushers()
+}
+
+// waitForRunningTasks waits for the waitGroup until the shutdown timeout, if any, and meanwhile logs which work tracked with a TrackedWaitGroup is blocking the shutdown
+func waitForRunningTasks(config *InitConfig, waitGroup *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		waitGroup.Wait()
+		close(done)
+	}()
+
+	var timeout <-chan time.Time
+	if config.ShutdownTimeout > 0 {
+		timeout = config.Clock.After(config.ShutdownTimeout)
+	}
+
+	var warn <-chan time.Time
+	if config.BlockedShutdownWarningInterval > 0 {
+		warn = config.Clock.After(config.BlockedShutdownWarningInterval)
+	}
 
-	log.Info().Msg("Shutting down...")
+	for {
+		select {
+		case <-done:
+			return
+		case <-timeout:
+			config.Logger.Warn().Msgf("Running tasks didn't finish within %v, shutting down anyway", config.ShutdownTimeout)
+			return
+		case <-warn:
+			if pending := pendingWork(); len(pending) > 0 {
+				config.Logger.Warn().Msgf("Shutdown is blocked by %v", formatPendingWork(pending))
+			}
+			warn = config.Clock.After(config.BlockedShutdownWarningInterval)
+		}
+	}
 }
 
 // InitCancellationContext adds cancelation to a context and on sigterm triggers the cancel function
@@ -58,7 +112,7 @@ func InitCancellationContext(ctx context.Context) context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// define channel used to trigger cancellation
-	cancelChannel := make(chan os.Signal)
+	cancelChannel := make(chan os.Signal, 1)
 
 	signal.Notify(cancelChannel, syscall.SIGTERM, syscall.SIGINT)
 
@@ -74,68 +128,15 @@ func InitCancellationContext(ctx context.Context) context.Context {
 func ApplyJitter(input int) (output int) {
 
 	deviation := int(0.25 * float64(input))
+	if deviation < 1 {
+		// input too small to apply jitter to
+		return input
+	}
 
-	return input - deviation + r.Intn(2*deviation)
-}
+	rMutex.Lock()
+	defer rMutex.Unlock()
 
-// WatchForFileChanges waits for a change to the provided file path and then executes the function
-func WatchForFileChanges(filePath string, functionOnChange func(fsnotify.Event)) {
-	// copied from https://github.com/spf13/viper/blob/v1.3.1/viper.go#L282-L348
-	initWG := sync.WaitGroup{}
-	initWG.Add(1)
-	go func() {
-		watcher, err := fsnotify.NewWatcher()
-		if err != nil {
-			log.Fatal().Err(err).Msg("Creating file system watcher failed")
-		}
-		defer watcher.Close()
-
-		// we have to watch the entire directory to pick up renames/atomic saves in a cross-platform way
-		file := filepath.Clean(filePath)
-		fileDir, _ := filepath.Split(file)
-		realFile, _ := filepath.EvalSymlinks(filePath)
-
-		eventsWG := sync.WaitGroup{}
-		eventsWG.Add(1)
-		go func() {
-			for {
-				select {
-				case event, ok := <-watcher.Events:
-					if !ok { // 'Events' channel is closed
-						eventsWG.Done()
-						return
-					}
-					currentFile, _ := filepath.EvalSymlinks(filePath)
-					// we only care about the key file with the following cases:
-					// 1 - if the key file was modified or created
-					// 2 - if the real path to the key file changed (eg: k8s ConfigMap/Secret replacement)
-					const writeOrCreateMask = fsnotify.Write | fsnotify.Create
-					if (filepath.Clean(event.Name) == file &&
-						event.Op&writeOrCreateMask != 0) ||
-						(currentFile != "" && currentFile != realFile) {
-						realFile = currentFile
-
-						functionOnChange(event)
-					} else if filepath.Clean(event.Name) == file &&
-						event.Op&fsnotify.Remove&fsnotify.Remove != 0 {
-						eventsWG.Done()
-						return
-					}
-
-				case err, ok := <-watcher.Errors:
-					if ok { // 'Errors' channel is not closed
-						log.Warn().Err(err).Msg("Watcher error")
-					}
-					eventsWG.Done()
-					return
-				}
-			}
-		}()
-		watcher.Add(fileDir)
-		initWG.Done()   // done initalizing the watch in this go routine, so the parent routine can move on...
-		eventsWG.Wait() // now, wait for event loop to end in this go-routine...
-	}()
-	initWG.Wait() // make sure that the go routine above fully ended before returning
+	return input - deviation + r.Intn(2*deviation)
 }
 
 // FileExists checks if a file exists
@@ -185,20 +186,36 @@ func IntArrayContains(array []int, search int) bool {
 	return false
 }
 
+// DigitBoundary sets where snake casing separates digits from letters
+type DigitBoundary int
+
+const (
+	// DigitBoundaryNone keeps digits attached to the surrounding letters, like v2beta1 to v2beta1
+	DigitBoundaryNone DigitBoundary = iota
+	// DigitBoundaryAfter separates letters following digits, like sha256sum to sha256_sum
+	DigitBoundaryAfter
+	// DigitBoundaryBoth separates digits from letters on both sides, like sha256sum to sha_256_sum
+	DigitBoundaryBoth
+)
+
+// SnakeCaseOptions configures ToUpperSnakeCaseWithOptions and ToLowerSnakeCaseWithOptions; the zero value behaves like ToUpperSnakeCase and ToLowerSnakeCase
+type SnakeCaseOptions struct {
+	// DigitBoundaries sets where digits are separated from letters
+	DigitBoundaries DigitBoundary
+	// Acronyms are split off runs of capitals they start, so APIURL becomes api_url given API; matched case-insensitively, longest first
+	Acronyms []string
+	// TrimSeparators removes leading and trailing underscores, like for -kubernetes-engine-
+	TrimSeparators bool
+}
+
 // ToUpperSnakeCase turns any input string into an upper snake cased string
 func ToUpperSnakeCase(in string) string {
-	runes := []rune(in)
-	length := len(runes)
-
-	var out []rune
-	for i := 0; i < length; i++ {
-		if i > 0 && unicode.IsUpper(runes[i]) && ((i+1 < length && unicode.IsLower(runes[i+1])) || unicode.IsLower(runes[i-1])) {
-			out = append(out, '_')
-		}
-		out = append(out, unicode.ToUpper(runes[i]))
-	}
+	return ToUpperSnakeCaseWithOptions(in, SnakeCaseOptions{})
+}
 
-	snake := string(out)
+// ToUpperSnakeCaseWithOptions turns any input string into an upper snake cased string, with the edge cases handled according to opts
+func ToUpperSnakeCaseWithOptions(in string, opts SnakeCaseOptions) string {
+	snake := toSnakeCase(in, opts, unicode.ToUpper)
 
 	// make sure nothing but alphanumeric characters and underscores are returned
 	reg, err := regexp.Compile("[^A-Z0-9]+")
@@ -207,30 +224,93 @@ func ToUpperSnakeCase(in string) string {
 	}
 	cleanSnake := reg.ReplaceAllString(snake, "_")
 
+	if opts.TrimSeparators {
+		cleanSnake = strings.Trim(cleanSnake, "_")
+	}
+
 	return cleanSnake
 }
 
 // ToLowerSnakeCase turns any input string into an lower snake cased string
 func ToLowerSnakeCase(in string) string {
+	return ToLowerSnakeCaseWithOptions(in, SnakeCaseOptions{})
+}
+
+// ToLowerSnakeCaseWithOptions turns any input string into an lower snake cased string, with the edge cases handled according to opts
+func ToLowerSnakeCaseWithOptions(in string, opts SnakeCaseOptions) string {
+	snake := toSnakeCase(in, opts, unicode.ToLower)
+
+	// make sure nothing but alphanumeric characters and underscores are returned
+	reg, err := regexp.Compile("[^a-z0-9]+")
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed converting %v to lower snake case", in)
+	}
+	cleanSnake := reg.ReplaceAllString(snake, "_")
+
+	if opts.TrimSeparators {
+		cleanSnake = strings.Trim(cleanSnake, "_")
+	}
+
+	return cleanSnake
+}
+
+// toSnakeCase inserts underscores at word boundaries and maps all runes with toCase
+func toSnakeCase(in string, opts SnakeCaseOptions, toCase func(rune) rune) string {
 	runes := []rune(in)
 	length := len(runes)
 
 	var out []rune
+	wordStart := 0
 	for i := 0; i < length; i++ {
-		if i > 0 && unicode.IsUpper(runes[i]) && ((i+1 < length && unicode.IsLower(runes[i+1])) || unicode.IsLower(runes[i-1])) {
+		if i > 0 && isSnakeCaseBoundary(runes, i, wordStart, opts.DigitBoundaries, opts.Acronyms) {
 			out = append(out, '_')
+			wordStart = i
 		}
-		out = append(out, unicode.ToLower(runes[i]))
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			wordStart = i + 1
+		}
+		out = append(out, toCase(runes[i]))
 	}
 
-	snake := string(out)
+	return string(out)
+}
 
-	// make sure nothing but alphanumeric characters and underscores are returned
-	reg, err := regexp.Compile("[^a-z0-9]+")
-	if err != nil {
-		log.Fatal().Err(err).Msgf("Failed converting %v to lower snake case", in)
+func isSnakeCaseBoundary(runes []rune, i, wordStart int, digitBoundaries DigitBoundary, acronyms []string) bool {
+	current, previous := runes[i], runes[i-1]
+
+	if unicode.IsUpper(current) && ((i+1 < len(runes) && unicode.IsLower(runes[i+1])) || unicode.IsLower(previous)) {
+		return true
 	}
-	cleanSnake := reg.ReplaceAllString(snake, "_")
 
-	return cleanSnake
+	if digitBoundaries >= DigitBoundaryAfter && unicode.IsLetter(current) && unicode.IsDigit(previous) {
+		return true
+	}
+	if digitBoundaries == DigitBoundaryBoth && unicode.IsDigit(current) && unicode.IsLetter(previous) {
+		return true
+	}
+
+	if unicode.IsUpper(current) && unicode.IsUpper(previous) {
+		return endsAcronym(runes, wordStart, i, acronyms)
+	}
+
+	return false
+}
+
+// endsAcronym returns whether runes[wordStart:i] is one of the acronyms and no longer acronym continues past i, so given URL and URLS the input URLSAPI splits as URLS_API
+func endsAcronym(runes []rune, wordStart, i int, acronyms []string) bool {
+	matched := false
+	for _, acronym := range acronyms {
+		acronymLength := len([]rune(acronym))
+		end := wordStart + acronymLength
+		if end > len(runes) || !strings.EqualFold(string(runes[wordStart:end]), acronym) {
+			continue
+		}
+		if end == i {
+			matched = true
+		} else if end > i {
+			return false
+		}
+	}
+
+	return matched
 }
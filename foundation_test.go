@@ -84,6 +84,68 @@ func TestToLowerSnakeCase(t *testing.T) {
 	})
 }
 
+func TestToLowerSnakeCaseWithOptions(t *testing.T) {
+
+	t.Run("BehavesLikeToLowerSnakeCaseWithoutOptions", func(t *testing.T) {
+
+		// act
+		snake := ToLowerSnakeCaseWithOptions("APIURL-v2beta1", SnakeCaseOptions{})
+
+		assert.Equal(t, ToLowerSnakeCase("APIURL-v2beta1"), snake)
+	})
+
+	t.Run("SeparatesLettersAfterDigits", func(t *testing.T) {
+
+		// act
+		snake := ToLowerSnakeCaseWithOptions("sha256sum", SnakeCaseOptions{DigitBoundaries: DigitBoundaryAfter})
+
+		assert.Equal(t, "sha256_sum", snake)
+	})
+
+	t.Run("SeparatesDigitsOnBothSides", func(t *testing.T) {
+
+		// act
+		snake := ToLowerSnakeCaseWithOptions("SHA256Sum", SnakeCaseOptions{DigitBoundaries: DigitBoundaryBoth})
+
+		assert.Equal(t, "sha_256_sum", snake)
+	})
+
+	t.Run("SplitsConsecutiveAcronyms", func(t *testing.T) {
+
+		// act
+		snake := ToLowerSnakeCaseWithOptions("APIURL", SnakeCaseOptions{Acronyms: []string{"API", "URL"}})
+
+		assert.Equal(t, "api_url", snake)
+	})
+
+	t.Run("PrefersLongerAcronym", func(t *testing.T) {
+
+		// act
+		snake := ToLowerSnakeCaseWithOptions("URLSAPI", SnakeCaseOptions{Acronyms: []string{"URL", "URLS"}})
+
+		assert.Equal(t, "urls_api", snake)
+	})
+
+	t.Run("TrimsLeadingAndTrailingSeparators", func(t *testing.T) {
+
+		// act
+		snake := ToLowerSnakeCaseWithOptions("-kubernetes-engine-", SnakeCaseOptions{TrimSeparators: true})
+
+		assert.Equal(t, "kubernetes_engine", snake)
+	})
+}
+
+func TestToUpperSnakeCaseWithOptions(t *testing.T) {
+
+	t.Run("SplitsAcronymsAndTrimsSeparators", func(t *testing.T) {
+
+		// act
+		snake := ToUpperSnakeCaseWithOptions("_APIURL_", SnakeCaseOptions{Acronyms: []string{"api"}, TrimSeparators: true})
+
+		assert.Equal(t, "API_URL", snake)
+	})
+}
+
 func TestFileExists(t *testing.T) {
 
 	t.Run("ReturnsTrueIfFileExists", func(t *testing.T) {
@@ -172,3 +234,23 @@ func TestPathExists(t *testing.T) {
 		assert.False(t, exists)
 	})
 }
+
+func TestApplyJitter(t *testing.T) {
+
+	t.Run("ReturnsValueWithin25PercentOfInput", func(t *testing.T) {
+
+		// act
+		output := ApplyJitter(100)
+
+		assert.True(t, output >= 75)
+		assert.True(t, output < 125)
+	})
+
+	t.Run("ReturnsInputIfTooSmallToApplyJitter", func(t *testing.T) {
+
+		// act
+		output := ApplyJitter(3)
+
+		assert.Equal(t, 3, output)
+	})
+}
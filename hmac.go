@@ -0,0 +1,34 @@
+package foundation
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ComputeHMACSHA256 returns the hex encoded HMAC SHA-256 of the payload using secret
+func ComputeHMACSHA256(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignHMACSHA256 returns a signature for the payload in the 'sha256=<hex>' format used by github and most other webhook senders
+func SignHMACSHA256(secret, payload []byte) string {
+	return "sha256=" + ComputeHMACSHA256(secret, payload)
+}
+
+// VerifyHMACSHA256 checks in constant time whether signature, hex encoded and optionally prefixed with 'sha256=', is the HMAC SHA-256 of the payload using secret
+func VerifyHMACSHA256(secret, payload []byte, signature string) bool {
+	signatureBytes, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	return hmac.Equal(mac.Sum(nil), signatureBytes)
+}
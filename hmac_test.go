@@ -0,0 +1,48 @@
+package foundation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyHMACSHA256(t *testing.T) {
+
+	t.Run("ReturnsTrueForSignatureWithPrefix", func(t *testing.T) {
+
+		signature := SignHMACSHA256([]byte("secret"), []byte("payload"))
+
+		// act
+		valid := VerifyHMACSHA256([]byte("secret"), []byte("payload"), signature)
+
+		assert.True(t, valid)
+	})
+
+	t.Run("ReturnsTrueForSignatureWithoutPrefix", func(t *testing.T) {
+
+		signature := ComputeHMACSHA256([]byte("secret"), []byte("payload"))
+
+		// act
+		valid := VerifyHMACSHA256([]byte("secret"), []byte("payload"), signature)
+
+		assert.True(t, valid)
+	})
+
+	t.Run("ReturnsFalseForSignatureWithOtherSecret", func(t *testing.T) {
+
+		signature := SignHMACSHA256([]byte("other"), []byte("payload"))
+
+		// act
+		valid := VerifyHMACSHA256([]byte("secret"), []byte("payload"), signature)
+
+		assert.False(t, valid)
+	})
+
+	t.Run("ReturnsFalseForEmptySignature", func(t *testing.T) {
+
+		// act
+		valid := VerifyHMACSHA256([]byte("secret"), []byte("payload"), "")
+
+		assert.False(t, valid)
+	})
+}
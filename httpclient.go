@@ -0,0 +1,183 @@
+package foundation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPClientOption allows to override the http client config
+type HTTPClientOption func(*HTTPClientConfig)
+
+// HTTPClientConfig is used to configure the client returned by NewHTTPClient
+type HTTPClientConfig struct {
+	Timeout             time.Duration
+	DialTimeout         time.Duration
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	RetryOptions        []RetryOption
+	Tracing             bool
+}
+
+// WithHTTPTimeout sets the timeout for a request including retries and reading the response body
+// default is 30 seconds
+func WithHTTPTimeout(timeout time.Duration) HTTPClientOption {
+	return func(c *HTTPClientConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// WithDialTimeout sets the timeout for establishing a connection
+// default is 5 seconds
+func WithDialTimeout(timeout time.Duration) HTTPClientOption {
+	return func(c *HTTPClientConfig) {
+		c.DialTimeout = timeout
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the number of connections kept open for reuse per host
+// default is 10
+func WithMaxIdleConnsPerHost(maxIdleConnsPerHost int) HTTPClientOption {
+	return func(c *HTTPClientConfig) {
+		c.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept open for reuse
+// default is 90 seconds
+func WithIdleConnTimeout(timeout time.Duration) HTTPClientOption {
+	return func(c *HTTPClientConfig) {
+		c.IdleConnTimeout = timeout
+	}
+}
+
+// WithHTTPRetryOptions overrides the options for retrying idempotent requests failing with a transport error, 429 or 5xx status
+// default is 3 attempts with exponential backoff with jitter starting at 100ms
+func WithHTTPRetryOptions(opts ...RetryOption) HTTPClientOption {
+	return func(c *HTTPClientConfig) {
+		c.RetryOptions = opts
+	}
+}
+
+// WithHTTPTracing starts a span for each request with the opentracing global tracer, as a child of the span in the request context, and propagates it in the request headers
+func WithHTTPTracing() HTTPClientOption {
+	return func(c *HTTPClientConfig) {
+		c.Tracing = true
+	}
+}
+
+// NewHTTPClient returns an http client for calling the named target, with timeouts, connection pooling, proxy settings from envvars HTTP_PROXY, HTTPS_PROXY and NO_PROXY, retries for idempotent requests and prometheus metrics labeled with the target name
+func NewHTTPClient(target string, opts ...HTTPClientOption) *http.Client {
+
+	config := &HTTPClientConfig{
+		Timeout:             30 * time.Second,
+		DialTimeout:         5 * time.Second,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	var transport http.RoundTripper = &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   config.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	transport = &instrumentedRoundTripper{target: target, next: transport}
+	transport = &retryingRoundTripper{next: transport, retryOptions: config.RetryOptions}
+	if config.Tracing {
+		transport = &tracingRoundTripper{target: target, next: transport}
+	}
+
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}
+}
+
+type retryingRoundTripper struct {
+	next         http.RoundTripper
+	retryOptions []RetryOption
+}
+
+var errRetryableHTTPStatus = errors.New("Retryable http status")
+
+func (rt *retryingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotentHTTPMethod(req.Method) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return rt.next.RoundTrip(req)
+	}
+
+	// the response with a retryable status is kept, so it can be returned if all attempts fail
+	var lastResp *http.Response
+	var resp *http.Response
+
+	opts := append([]RetryOption{
+		LastErrorOnly(true),
+		func(c *RetryConfig) {
+			c.IsRetryableError = func(err error) bool {
+				_, unrecoverable := err.(unrecoverableError)
+				return !unrecoverable && req.Context().Err() == nil
+			}
+		},
+	}, rt.retryOptions...)
+
+	err := Retry(func() error {
+		attempt := req
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return unrecoverableError{err}
+			}
+			attempt = req.Clone(req.Context())
+			attempt.Body = body
+		}
+
+		r, err := rt.next.RoundTrip(attempt)
+		if lastResp != nil {
+			lastResp.Body.Close()
+			lastResp = nil
+		}
+		if err != nil {
+			return err
+		}
+		if r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= 500 {
+			lastResp = r
+			return fmt.Errorf("%w: %v", errRetryableHTTPStatus, r.StatusCode)
+		}
+
+		resp = r
+		return nil
+	}, opts...)
+
+	if resp != nil {
+		return resp, nil
+	}
+	if lastResp != nil && errors.Is(err, errRetryableHTTPStatus) {
+		return lastResp, nil
+	}
+
+	return nil, err
+}
+
+func isIdempotentHTTPMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
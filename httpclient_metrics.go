@@ -0,0 +1,50 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpClientRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "foundation_http_client_requests_total",
+			Help: "Total number of outgoing http requests by target, method and status code.",
+		},
+		[]string{"target", "method", "code"},
+	)
+	httpClientRequestDurationSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "foundation_http_client_request_duration_seconds",
+			Help:    "Duration of outgoing http requests by target and method.",
+			Buckets: DefaultDurationBuckets(),
+		},
+		[]string{"target", "method"},
+	)
+)
+
+type instrumentedRoundTripper struct {
+	target string
+	next   http.RoundTripper
+}
+
+func (rt *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	httpClientRequestsTotal.WithLabelValues(rt.target, req.Method, code).Inc()
+	httpClientRequestDurationSeconds.WithLabelValues(rt.target, req.Method).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
@@ -0,0 +1,82 @@
+package foundation
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+
+	t.Run("RetriesIdempotentRequestsOnServerErrors", func(t *testing.T) {
+
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			if atomic.AddInt32(&requests, 1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write(body)
+		}))
+		defer server.Close()
+		client := NewHTTPClient("test", WithHTTPRetryOptions(DelayMillisecond(1), Fixed()))
+		request, _ := http.NewRequest(http.MethodPut, server.URL, bytes.NewReader([]byte("payload")))
+
+		// act
+		resp, err := client.Do(request)
+
+		if assert.Nil(t, err) {
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "payload", string(body))
+			assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+		}
+	})
+
+	t.Run("ReturnsLastResponseWhenAllAttemptsFail", func(t *testing.T) {
+
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		client := NewHTTPClient("test", WithHTTPRetryOptions(Attempts(2), DelayMillisecond(1), Fixed()))
+
+		// act
+		resp, err := client.Get(server.URL)
+
+		if assert.Nil(t, err) {
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+			assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+		}
+	})
+
+	t.Run("DoesNotRetryPostRequests", func(t *testing.T) {
+
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		client := NewHTTPClient("test", WithHTTPRetryOptions(DelayMillisecond(1), Fixed()))
+
+		// act
+		resp, err := client.Post(server.URL, "application/json", bytes.NewReader([]byte("{}")))
+
+		if assert.Nil(t, err) {
+			defer resp.Body.Close()
+			assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+		}
+	})
+
+}
@@ -0,0 +1,68 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	intervalIterationDurationSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "foundation_interval_iteration_duration_seconds",
+			Help:    "Duration of iterations run by RunOnInterval by function and result.",
+			Buckets: DefaultDurationBuckets(),
+		},
+		[]string{"function", "result"},
+	)
+)
+
+// RunOnInterval runs fn immediately and then every interval, with +-25% jitter applied to each interval if withJitter is true, until ctx is cancelled; errors and panics are logged per iteration without stopping the loop, and iteration durations are recorded in a histogram labeled by the function name
+func RunOnInterval(ctx context.Context, interval time.Duration, withJitter bool, fn func(ctx context.Context) error) {
+
+	function := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+
+	for {
+		runIteration(ctx, function, fn)
+
+		wait := interval
+		if withJitter {
+			wait = time.Duration(ApplyJitter(int(interval/time.Millisecond))) * time.Millisecond
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func runIteration(ctx context.Context, function string, fn func(ctx context.Context) error) {
+	start := time.Now()
+	result := "succeeded"
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = "panicked"
+			log.Error().Err(fmt.Errorf("%v", rec)).Msgf("Iteration of %v panicked", function)
+		}
+		intervalIterationDurationSeconds.WithLabelValues(function, result).Observe(time.Since(start).Seconds())
+	}()
+
+	if err := fn(ctx); err != nil {
+		result = "failed"
+		log.Warn().Err(err).Msgf("Iteration of %v failed", function)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunOnInterval(t *testing.T) {
+
+	t.Run("RunsImmediatelyAndThenOnIntervalUntilCancelled", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var iterations int32
+
+		// act
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+		RunOnInterval(ctx, 20*time.Millisecond, true, func(ctx context.Context) error {
+			atomic.AddInt32(&iterations, 1)
+			return nil
+		})
+
+		assert.True(t, atomic.LoadInt32(&iterations) >= 2)
+	})
+
+	t.Run("KeepsRunningAfterErrorsAndPanics", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var iterations int32
+
+		// act
+		RunOnInterval(ctx, time.Millisecond, false, func(ctx context.Context) error {
+			switch atomic.AddInt32(&iterations, 1) {
+			case 1:
+				return errors.New("upstream unavailable")
+			case 2:
+				panic("nil pointer")
+			default:
+				cancel()
+				return nil
+			}
+		})
+
+		assert.Equal(t, int32(3), atomic.LoadInt32(&iterations))
+	})
+}
@@ -0,0 +1,118 @@
+package foundation
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewIPFilterMiddleware returns middleware only letting through requests from ip addresses within the allowed CIDR ranges (any if empty) and outside of the denied ones; with trustedProxyDepth > 0 the client ip is taken from the X-Forwarded-For header, skipping the addresses appended by that many trusted proxies
+func NewIPFilterMiddleware(allowedCIDRs, deniedCIDRs []string, trustedProxyDepth int) (func(http.Handler) http.Handler, error) {
+
+	allowed, err := parseCIDRs(allowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := parseCIDRs(deniedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			clientIP := GetClientIP(r, trustedProxyDepth)
+			if clientIP == nil || !isIPAllowed(clientIP, allowed, denied) {
+				log.Debug().Str("remoteAddr", r.RemoteAddr).Str("forwardedFor", r.Header.Get("X-Forwarded-For")).Msgf("Denied request from ip %v", clientIP)
+				writeAuthProblem(w, r, http.StatusForbidden, "Access from this ip address is not allowed")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// GetClientIP returns the ip of the client making the request; with trustedProxyDepth > 0 it's taken from the X-Forwarded-For header, skipping the addresses appended by that many trusted proxies
+func GetClientIP(r *http.Request, trustedProxyDepth int) net.IP {
+
+	remoteHost, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteHost = r.RemoteAddr
+	}
+
+	// the chain of hops from the original client to this server, where only the last trustedProxyDepth hops can be trusted to not be spoofed
+	chain := []string{}
+	if trustedProxyDepth > 0 {
+		for _, forwardedFor := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(forwardedFor, ",") {
+				if hop = strings.TrimSpace(hop); hop != "" {
+					chain = append(chain, hop)
+				}
+			}
+		}
+	}
+	chain = append(chain, remoteHost)
+
+	index := len(chain) - 1 - trustedProxyDepth
+	if index < 0 {
+		index = 0
+	}
+
+	return net.ParseIP(chain[index])
+}
+
+func isIPAllowed(ip net.IP, allowed, denied []*net.IPNet) bool {
+	for _, n := range denied {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, n := range allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseCIDRs parses CIDR ranges, accepting single ip addresses as well
+func parseCIDRs(cidrs []string) (networks []*net.IPNet, err error) {
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+
+		if !strings.Contains(c, "/") {
+			ip := net.ParseIP(c)
+			if ip == nil {
+				return nil, fmt.Errorf("Ip address %v is invalid", c)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, parseErr := net.ParseCIDR(c)
+		if parseErr != nil {
+			return nil, fmt.Errorf("CIDR range %v is invalid: %w", c, parseErr)
+		}
+		networks = append(networks, network)
+	}
+
+	return
+}
@@ -0,0 +1,93 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIPFilterMiddleware(t *testing.T) {
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	serve := func(middleware func(http.Handler) http.Handler, remoteAddr, forwardedFor string) int {
+		request := httptest.NewRequest(http.MethodPost, "/webhooks/github", nil)
+		request.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			request.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		recorder := httptest.NewRecorder()
+		middleware(handler).ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	t.Run("ReturnsErrorForInvalidCIDR", func(t *testing.T) {
+
+		// act
+		_, err := NewIPFilterMiddleware([]string{"10.0.0.0/33"}, nil, 0)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("AllowsRequestFromAllowedRange", func(t *testing.T) {
+
+		middleware, _ := NewIPFilterMiddleware([]string{"140.82.112.0/20"}, nil, 0)
+
+		// act
+		statusCode := serve(middleware, "140.82.115.10:43210", "")
+
+		assert.Equal(t, http.StatusOK, statusCode)
+	})
+
+	t.Run("DeniesRequestOutsideAllowedRange", func(t *testing.T) {
+
+		middleware, _ := NewIPFilterMiddleware([]string{"140.82.112.0/20"}, nil, 0)
+
+		// act
+		statusCode := serve(middleware, "10.1.2.3:43210", "")
+
+		assert.Equal(t, http.StatusForbidden, statusCode)
+	})
+
+	t.Run("DeniesRequestFromDeniedRangeEvenIfAllowed", func(t *testing.T) {
+
+		middleware, _ := NewIPFilterMiddleware([]string{"10.0.0.0/8"}, []string{"10.1.2.3"}, 0)
+
+		// act
+		statusCode := serve(middleware, "10.1.2.3:43210", "")
+
+		assert.Equal(t, http.StatusForbidden, statusCode)
+	})
+
+	t.Run("IgnoresForwardedForHeaderWithoutTrustedProxies", func(t *testing.T) {
+
+		middleware, _ := NewIPFilterMiddleware([]string{"140.82.112.0/20"}, nil, 0)
+
+		// act
+		statusCode := serve(middleware, "10.1.2.3:43210", "140.82.115.10")
+
+		assert.Equal(t, http.StatusForbidden, statusCode)
+	})
+
+	t.Run("UsesForwardedForHeaderBehindTrustedProxy", func(t *testing.T) {
+
+		middleware, _ := NewIPFilterMiddleware([]string{"140.82.112.0/20"}, nil, 1)
+
+		// act
+		statusCode := serve(middleware, "10.1.2.3:43210", "140.82.115.10")
+
+		assert.Equal(t, http.StatusOK, statusCode)
+	})
+
+	t.Run("IgnoresSpoofedForwardedForEntriesBeforeTrustedProxies", func(t *testing.T) {
+
+		middleware, _ := NewIPFilterMiddleware([]string{"140.82.112.0/20"}, nil, 1)
+
+		// act
+		statusCode := serve(middleware, "10.1.2.3:43210", "140.82.115.10, 192.168.1.1")
+
+		assert.Equal(t, http.StatusForbidden, statusCode)
+	})
+}
@@ -0,0 +1,442 @@
+package foundation
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// JWTAlgorithmHS256 signs tokens with HMAC SHA-256 using a shared secret
+	JWTAlgorithmHS256 = "HS256"
+	// JWTAlgorithmRS256 signs tokens with RSA SHA-256 using a private key and validates them with the public key
+	JWTAlgorithmRS256 = "RS256"
+)
+
+var (
+	// ErrJWTMalformed is returned when a token can't be parsed
+	ErrJWTMalformed = errors.New("Token is malformed")
+	// ErrJWTUnknownKey is returned when no key in the keyring matches the token's key id and algorithm
+	ErrJWTUnknownKey = errors.New("Token is signed with an unknown key")
+	// ErrJWTSignatureInvalid is returned when the token signature doesn't match
+	ErrJWTSignatureInvalid = errors.New("Token signature is invalid")
+	// ErrJWTExpired is returned when the token expiry lies in the past
+	ErrJWTExpired = errors.New("Token is expired")
+	// ErrJWTMissingExpiry is returned when the token has no expiry, unless disabled with JWTRequireExpiry
+	ErrJWTMissingExpiry = errors.New("Token has no expiry")
+	// ErrJWTNotYetValid is returned when the token's not-before time lies in the future
+	ErrJWTNotYetValid = errors.New("Token is not valid yet")
+	// ErrJWTIssuerMismatch is returned when the token isn't issued by the expected issuer
+	ErrJWTIssuerMismatch = errors.New("Token issuer does not match")
+	// ErrJWTAudienceMismatch is returned when the token isn't intended for the expected audience
+	ErrJWTAudienceMismatch = errors.New("Token audience does not match")
+)
+
+// JWTKey is a key to sign and/or validate tokens with
+type JWTKey struct {
+	// ID is set as the kid header of issued tokens to pick the right key when validating
+	ID        string
+	Algorithm string
+	// Secret is used for HS256
+	Secret []byte
+	// PrivateKey is used for signing with RS256
+	PrivateKey *rsa.PrivateKey
+	// PublicKey is used for validating with RS256; derived from PrivateKey if not set
+	PublicKey *rsa.PublicKey
+}
+
+// JWTKeyring holds an active key to issue tokens with and older keys that are still accepted for validation, so keys can be rotated without invalidating tokens in use
+type JWTKeyring struct {
+	mutex       sync.RWMutex
+	keys        map[string]JWTKey
+	activeKeyID string
+}
+
+// NewJWTKeyring returns a keyring issuing tokens with the active key and accepting tokens signed by any of the keys
+func NewJWTKeyring(active JWTKey, others ...JWTKey) *JWTKeyring {
+	keyring := &JWTKeyring{
+		keys: map[string]JWTKey{},
+	}
+	for _, k := range others {
+		keyring.add(k)
+	}
+	keyring.Rotate(active)
+
+	return keyring
+}
+
+// Rotate adds a key and makes it the active key for issuing tokens; previous keys remain valid for validation until removed
+func (k *JWTKeyring) Rotate(active JWTKey) {
+	k.add(active)
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.activeKeyID = active.ID
+}
+
+func (k *JWTKeyring) add(key JWTKey) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if key.PublicKey == nil && key.PrivateKey != nil {
+		key.PublicKey = &key.PrivateKey.PublicKey
+	}
+
+	k.keys[key.ID] = key
+}
+
+// Remove removes a key so tokens signed by it are no longer accepted; the active key can't be removed
+func (k *JWTKeyring) Remove(id string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if id == k.activeKeyID {
+		return
+	}
+
+	delete(k.keys, id)
+}
+
+func (k *JWTKeyring) activeKey() JWTKey {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	return k.keys[k.activeKeyID]
+}
+
+func (k *JWTKeyring) validationKeys(id, algorithm string) (keys []JWTKey) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	if id != "" {
+		if key, ok := k.keys[id]; ok && key.Algorithm == algorithm {
+			keys = append(keys, key)
+		}
+		return
+	}
+
+	for _, key := range k.keys {
+		if key.Algorithm == algorithm {
+			keys = append(keys, key)
+		}
+	}
+
+	return
+}
+
+// JWTClaims contains the registered claims of a token plus any additional claims in Extra
+type JWTClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+	Extra     map[string]interface{}
+}
+
+// MarshalJSON flattens the registered claims and extra claims into a single json object
+func (c JWTClaims) MarshalJSON() ([]byte, error) {
+	claims := map[string]interface{}{}
+	for k, v := range c.Extra {
+		claims[k] = v
+	}
+
+	setIfNotEmpty := func(name, value string) {
+		if value != "" {
+			claims[name] = value
+		}
+	}
+	setTimeIfNotZero := func(name string, value time.Time) {
+		if !value.IsZero() {
+			claims[name] = value.Unix()
+		}
+	}
+
+	setIfNotEmpty("iss", c.Issuer)
+	setIfNotEmpty("sub", c.Subject)
+	setIfNotEmpty("jti", c.ID)
+	setTimeIfNotZero("exp", c.ExpiresAt)
+	setTimeIfNotZero("nbf", c.NotBefore)
+	setTimeIfNotZero("iat", c.IssuedAt)
+	if len(c.Audience) == 1 {
+		claims["aud"] = c.Audience[0]
+	} else if len(c.Audience) > 1 {
+		claims["aud"] = c.Audience
+	}
+
+	return json.Marshal(claims)
+}
+
+// UnmarshalJSON splits a json object into the registered claims and extra claims
+func (c *JWTClaims) UnmarshalJSON(data []byte) error {
+	var claims map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&claims); err != nil {
+		return err
+	}
+
+	*c = JWTClaims{Extra: map[string]interface{}{}}
+	for k, v := range claims {
+		var err error
+		switch k {
+		case "iss":
+			c.Issuer, err = jwtClaimString(k, v)
+		case "sub":
+			c.Subject, err = jwtClaimString(k, v)
+		case "jti":
+			c.ID, err = jwtClaimString(k, v)
+		case "exp":
+			c.ExpiresAt, err = jwtClaimTime(k, v)
+		case "nbf":
+			c.NotBefore, err = jwtClaimTime(k, v)
+		case "iat":
+			c.IssuedAt, err = jwtClaimTime(k, v)
+		case "aud":
+			switch aud := v.(type) {
+			case string:
+				c.Audience = []string{aud}
+			case []interface{}:
+				for _, a := range aud {
+					s, sErr := jwtClaimString(k, a)
+					if sErr != nil {
+						return sErr
+					}
+					c.Audience = append(c.Audience, s)
+				}
+			default:
+				err = fmt.Errorf("Claim %v has unexpected type %T", k, v)
+			}
+		default:
+			c.Extra[k] = v
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func jwtClaimString(name string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Claim %v has unexpected type %T", name, value)
+	}
+	return s, nil
+}
+
+// jwtMaxClaimTime is the last second of the year 9999, to reject numeric dates that would overflow when converted to int64 or compared
+const jwtMaxClaimTime = 253402300799
+
+func jwtClaimTime(name string, value interface{}) (time.Time, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("Claim %v has unexpected type %T", name, value)
+	}
+	seconds, err := n.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	// the negated check also rejects NaN
+	if !(seconds >= 0 && seconds <= jwtMaxClaimTime) {
+		return time.Time{}, fmt.Errorf("Claim %v is out of range", name)
+	}
+	return time.Unix(int64(seconds), 0), nil
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// IssueJWT returns a token with the claims, signed by the keyring's active key
+func IssueJWT(keyring *JWTKeyring, claims JWTClaims) (string, error) {
+
+	key := keyring.activeKey()
+
+	header, err := json.Marshal(jwtHeader{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := signJWT(key, signingInput)
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JWTValidationOption allows to override the defaults for validating tokens
+type JWTValidationOption func(*JWTValidationConfig)
+
+// JWTValidationConfig is used to configure the ValidateJWT function
+type JWTValidationConfig struct {
+	Issuer        string
+	Audience      string
+	ClockSkew     time.Duration
+	RequireExpiry bool
+	Now           func() time.Time
+}
+
+// JWTExpectIssuer makes validation fail for tokens not issued by issuer
+func JWTExpectIssuer(issuer string) JWTValidationOption {
+	return func(c *JWTValidationConfig) {
+		c.Issuer = issuer
+	}
+}
+
+// JWTExpectAudience makes validation fail for tokens not including audience
+func JWTExpectAudience(audience string) JWTValidationOption {
+	return func(c *JWTValidationConfig) {
+		c.Audience = audience
+	}
+}
+
+// JWTClockSkew sets the tolerance for expiry and not-before checks
+// default is 1 minute
+func JWTClockSkew(skew time.Duration) JWTValidationOption {
+	return func(c *JWTValidationConfig) {
+		c.ClockSkew = skew
+	}
+}
+
+// JWTRequireExpiry sets whether validation fails for tokens without expiry, which would otherwise be valid forever
+// default is true
+func JWTRequireExpiry(require bool) JWTValidationOption {
+	return func(c *JWTValidationConfig) {
+		c.RequireExpiry = require
+	}
+}
+
+// ValidateJWT checks the token's signature against the keyring and its time, issuer and audience claims; it returns the claims if the token is valid. Tokens without expiry are rejected unless allowed with JWTRequireExpiry(false)
+func ValidateJWT(keyring *JWTKeyring, token string, opts ...JWTValidationOption) (*JWTClaims, error) {
+
+	config := &JWTValidationConfig{
+		ClockSkew:     time.Minute,
+		RequireExpiry: true,
+		Now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var header jwtHeader
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+
+	// only keys configured for the algorithm in the header are tried, so 'none' or alg confusion attacks are rejected
+	keys := keyring.validationKeys(header.KeyID, header.Algorithm)
+	if len(keys) == 0 {
+		return nil, ErrJWTUnknownKey
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	verified := false
+	for _, key := range keys {
+		if verifyJWT(key, signingInput, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrJWTSignatureInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var claims JWTClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTMalformed, err)
+	}
+
+	now := config.Now()
+	if config.RequireExpiry && claims.ExpiresAt.IsZero() {
+		return nil, ErrJWTMissingExpiry
+	}
+	if !claims.ExpiresAt.IsZero() && now.After(claims.ExpiresAt.Add(config.ClockSkew)) {
+		return nil, ErrJWTExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Add(config.ClockSkew).Before(claims.NotBefore) {
+		return nil, ErrJWTNotYetValid
+	}
+	if config.Issuer != "" && claims.Issuer != config.Issuer {
+		return nil, ErrJWTIssuerMismatch
+	}
+	if config.Audience != "" && !StringArrayContains(claims.Audience, config.Audience) {
+		return nil, ErrJWTAudienceMismatch
+	}
+
+	return &claims, nil
+}
+
+func signJWT(key JWTKey, signingInput string) ([]byte, error) {
+	switch key.Algorithm {
+	case JWTAlgorithmHS256:
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write([]byte(signingInput))
+		return mac.Sum(nil), nil
+
+	case JWTAlgorithmRS256:
+		if key.PrivateKey == nil {
+			return nil, fmt.Errorf("Key %v has no private key to sign with", key.ID)
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		return rsa.SignPKCS1v15(rand.Reader, key.PrivateKey, crypto.SHA256, hash[:])
+	}
+
+	return nil, fmt.Errorf("Algorithm %v is not supported", key.Algorithm)
+}
+
+func verifyJWT(key JWTKey, signingInput string, signature []byte) bool {
+	switch key.Algorithm {
+	case JWTAlgorithmHS256:
+		expected, _ := signJWT(key, signingInput)
+		return hmac.Equal(expected, signature)
+
+	case JWTAlgorithmRS256:
+		if key.PublicKey == nil {
+			return false
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(key.PublicKey, crypto.SHA256, hash[:], signature) == nil
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package foundation
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueAndValidateJWT(t *testing.T) {
+
+	hs256Key := JWTKey{ID: "hs-1", Algorithm: JWTAlgorithmHS256, Secret: []byte("very-secret")}
+
+	t.Run("ReturnsClaimsForValidHS256Token", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, err := IssueJWT(keyring, JWTClaims{
+			Issuer:    "ziplinee-api",
+			Subject:   "agent-1",
+			Audience:  []string{"ziplinee-agent"},
+			ExpiresAt: time.Now().Add(time.Hour),
+			Extra:     map[string]interface{}{"role": "builder"},
+		})
+		assert.Nil(t, err)
+
+		// act
+		claims, err := ValidateJWT(keyring, token, JWTExpectIssuer("ziplinee-api"), JWTExpectAudience("ziplinee-agent"))
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, "agent-1", claims.Subject)
+			assert.Equal(t, "builder", claims.Extra["role"])
+		}
+	})
+
+	t.Run("ReturnsClaimsForValidRS256Token", func(t *testing.T) {
+
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.Nil(t, err)
+		keyring := NewJWTKeyring(JWTKey{ID: "rs-1", Algorithm: JWTAlgorithmRS256, PrivateKey: privateKey})
+		token, err := IssueJWT(keyring, JWTClaims{Subject: "agent-1", ExpiresAt: time.Now().Add(time.Hour)})
+		assert.Nil(t, err)
+
+		// act
+		claims, err := ValidateJWT(NewJWTKeyring(JWTKey{ID: "rs-1", Algorithm: JWTAlgorithmRS256, PublicKey: &privateKey.PublicKey}), token)
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, "agent-1", claims.Subject)
+		}
+	})
+
+	t.Run("ReturnsErrorForTamperedToken", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{Subject: "agent-1"})
+		otherToken, _ := IssueJWT(keyring, JWTClaims{Subject: "admin"})
+		parts := strings.Split(token, ".")
+		otherParts := strings.Split(otherToken, ".")
+
+		// act
+		_, err := ValidateJWT(keyring, parts[0]+"."+otherParts[1]+"."+parts[2])
+
+		assert.True(t, errors.Is(err, ErrJWTSignatureInvalid))
+	})
+
+	t.Run("ReturnsErrorForExpiredTokenBeyondClockSkew", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{ExpiresAt: time.Now().Add(-2 * time.Minute)})
+
+		// act
+		_, err := ValidateJWT(keyring, token, JWTClockSkew(time.Minute))
+
+		assert.True(t, errors.Is(err, ErrJWTExpired))
+	})
+
+	t.Run("AcceptsExpiredTokenWithinClockSkew", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{ExpiresAt: time.Now().Add(-30 * time.Second)})
+
+		// act
+		_, err := ValidateJWT(keyring, token, JWTClockSkew(time.Minute))
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorForWrongAudience", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{Audience: []string{"ziplinee-ui"}, ExpiresAt: time.Now().Add(time.Hour)})
+
+		// act
+		_, err := ValidateJWT(keyring, token, JWTExpectAudience("ziplinee-agent"))
+
+		assert.True(t, errors.Is(err, ErrJWTAudienceMismatch))
+	})
+
+	t.Run("ReturnsErrorForUnsignedToken", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+
+		// act
+		_, err := ValidateJWT(keyring, "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJhZG1pbiJ9.")
+
+		assert.True(t, errors.Is(err, ErrJWTUnknownKey))
+	})
+
+	t.Run("ReturnsErrorForTokenWithoutExpiry", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{Subject: "agent-1"})
+
+		// act
+		_, err := ValidateJWT(keyring, token)
+
+		assert.True(t, errors.Is(err, ErrJWTMissingExpiry))
+	})
+
+	t.Run("AcceptsTokenWithoutExpiryIfNotRequired", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{Subject: "agent-1"})
+
+		// act
+		_, err := ValidateJWT(keyring, token, JWTRequireExpiry(false))
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorForOutOfRangeExpiry", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{Subject: "agent-1", Extra: map[string]interface{}{"exp": 1e30}})
+
+		// act
+		_, err := ValidateJWT(keyring, token)
+
+		assert.True(t, errors.Is(err, ErrJWTMalformed))
+	})
+
+	t.Run("AcceptsTokensSignedByPreviousKeyAfterRotation", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{Subject: "agent-1", ExpiresAt: time.Now().Add(time.Hour)})
+		keyring.Rotate(JWTKey{ID: "hs-2", Algorithm: JWTAlgorithmHS256, Secret: []byte("new-secret")})
+
+		// act
+		_, err := ValidateJWT(keyring, token)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("RejectsTokensSignedByRemovedKey", func(t *testing.T) {
+
+		keyring := NewJWTKeyring(hs256Key)
+		token, _ := IssueJWT(keyring, JWTClaims{Subject: "agent-1"})
+		keyring.Rotate(JWTKey{ID: "hs-2", Algorithm: JWTAlgorithmHS256, Secret: []byte("new-secret")})
+		keyring.Remove("hs-1")
+
+		// act
+		_, err := ValidateJWT(keyring, token)
+
+		assert.True(t, errors.Is(err, ErrJWTUnknownKey))
+	})
+}
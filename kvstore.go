@@ -0,0 +1,214 @@
+package foundation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KeyValueStore is a concurrency-safe in-memory key/value store that can be persisted to a snapshot file
+type KeyValueStore interface {
+	// Get returns the value for key and whether it exists and hasn't expired
+	Get(key string) ([]byte, bool)
+	// Set stores the value for key; a ttl of 0 means it never expires
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes key from the store
+	Delete(key string)
+	// Keys returns the sorted keys of all entries that haven't expired
+	Keys() []string
+	// Snapshot writes all entries that haven't expired to the snapshot file if they changed since the last snapshot
+	Snapshot() error
+	// Close stops periodic snapshots and writes a final snapshot
+	Close() error
+}
+
+type keyValueEntry struct {
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+func (e keyValueEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+type keyValueStore struct {
+	mutex        sync.RWMutex
+	entries      map[string]keyValueEntry
+	snapshotPath string
+	dirty        bool
+	stop         chan struct{}
+	stopped      sync.WaitGroup
+	closeOnce    sync.Once
+}
+
+// NewKeyValueStore returns a KeyValueStore loaded from the snapshot file at snapshotPath if it exists and snapshotted to it every snapshotInterval; with an empty snapshotPath the store is in-memory only, with a snapshotInterval of 0 snapshots are only written by calling Snapshot or Close
+func NewKeyValueStore(snapshotPath string, snapshotInterval time.Duration) (KeyValueStore, error) {
+
+	s := &keyValueStore{
+		entries:      map[string]keyValueEntry{},
+		snapshotPath: snapshotPath,
+		stop:         make(chan struct{}),
+	}
+
+	if snapshotPath == "" {
+		return s, nil
+	}
+
+	if FileExists(snapshotPath) {
+		data, err := ioutil.ReadFile(snapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			return nil, err
+		}
+		log.Info().Msgf("Loaded %v entries from key/value store snapshot %v", len(s.entries), snapshotPath)
+	}
+
+	if snapshotInterval > 0 {
+		s.stopped.Add(1)
+		go s.snapshotPeriodically(snapshotInterval)
+	}
+
+	return s, nil
+}
+
+func (s *keyValueStore) Get(key string) ([]byte, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, false
+	}
+
+	return entry.Value, true
+}
+
+func (s *keyValueStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry := keyValueEntry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	s.entries[key] = entry
+	s.dirty = true
+}
+
+func (s *keyValueStore) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.entries[key]; ok {
+		delete(s.entries, key)
+		s.dirty = true
+	}
+}
+
+func (s *keyValueStore) Keys() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	keys := make([]string, 0, len(s.entries))
+	for key, entry := range s.entries {
+		if !entry.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (s *keyValueStore) Snapshot() error {
+	if s.snapshotPath == "" {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// drop expired entries so they don't end up in the snapshot
+	now := time.Now()
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+			s.dirty = true
+		}
+	}
+
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomically(s.snapshotPath, data, 0600); err != nil {
+		return err
+	}
+	s.dirty = false
+
+	return nil
+}
+
+func (s *keyValueStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	s.stopped.Wait()
+
+	return s.Snapshot()
+}
+
+func (s *keyValueStore) snapshotPeriodically(interval time.Duration) {
+	defer s.stopped.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Snapshot(); err != nil {
+				log.Warn().Err(err).Msgf("Writing key/value store snapshot %v failed", s.snapshotPath)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// writeFileAtomically writes data to a temporary file and renames it to filePath, so readers never see a partially written file
+func writeFileAtomically(filePath string, data []byte, perm os.FileMode) error {
+	tmpPath := filePath + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filePath)
+}
@@ -0,0 +1,68 @@
+package foundation
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyValueStore(t *testing.T) {
+
+	t.Run("ReturnsStoredValue", func(t *testing.T) {
+
+		store, _ := NewKeyValueStore("", 0)
+		store.Set("agent-1", []byte("busy"), 0)
+
+		// act
+		value, ok := store.Get("agent-1")
+
+		assert.True(t, ok)
+		assert.Equal(t, "busy", string(value))
+	})
+
+	t.Run("DoesNotReturnExpiredOrDeletedValues", func(t *testing.T) {
+
+		store, _ := NewKeyValueStore("", 0)
+		store.Set("expired", []byte("x"), time.Nanosecond)
+		store.Set("deleted", []byte("x"), 0)
+		store.Set("kept", []byte("x"), time.Hour)
+		store.Delete("deleted")
+		time.Sleep(time.Millisecond)
+
+		// act
+		_, ok := store.Get("expired")
+
+		assert.False(t, ok)
+		assert.Equal(t, []string{"kept"}, store.Keys())
+	})
+
+	t.Run("RestoresEntriesFromSnapshotWrittenOnClose", func(t *testing.T) {
+
+		snapshotPath := filepath.Join(t.TempDir(), "state.json")
+		store, _ := NewKeyValueStore(snapshotPath, time.Hour)
+		store.Set("delivery-1", []byte("seen"), time.Hour)
+		store.Set("delivery-2", []byte("seen"), 0)
+		store.Close()
+
+		// act
+		restored, err := NewKeyValueStore(snapshotPath, 0)
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, []string{"delivery-1", "delivery-2"}, restored.Keys())
+		}
+	})
+
+	t.Run("WritesSnapshotPeriodically", func(t *testing.T) {
+
+		snapshotPath := filepath.Join(t.TempDir(), "state.json")
+		store, _ := NewKeyValueStore(snapshotPath, 5*time.Millisecond)
+		defer store.Close()
+
+		// act
+		store.Set("agent-1", []byte("idle"), 0)
+
+		assert.Eventually(t, func() bool { return FileExists(snapshotPath) }, time.Second, 5*time.Millisecond)
+	})
+}
@@ -0,0 +1,356 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotFlushable is returned by FlushOnShutdown for a value that has no Flush or Sync method
+var ErrNotFlushable = errors.New("Value has no Flush or Sync method")
+
+// ErrStartupChecksFailed is returned by Startup when one or more of the registered startup checks failed
+var ErrStartupChecksFailed = errors.New("One or more startup checks failed")
+
+// StartupCheck verifies a precondition for serving traffic, like valid config, applied migrations or reachable dependencies
+type StartupCheck func(ctx context.Context) error
+
+// StartupCheckResult contains the outcome of a single startup check
+type StartupCheckResult struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// StartupReport contains the outcome of all registered startup checks
+type StartupReport struct {
+	Passed bool                 `json:"passed"`
+	Checks []StartupCheckResult `json:"checks"`
+}
+
+type namedStartupCheck struct {
+	name  string
+	check StartupCheck
+}
+
+type namedShutdownHook struct {
+	name   string
+	hook   func() error
+	dryRun func() error
+}
+
+var (
+	startupChecks      []namedStartupCheck
+	startupChecksMutex sync.Mutex
+
+	shutdownHooks      []namedShutdownHook
+	shutdownHooksMutex sync.Mutex
+	// shutdownHookDurations holds how long each shutdown hook took the last time it ran, for real or as a dry run during a shutdown rehearsal
+	shutdownHookDurations map[string]time.Duration
+
+	flushers      []func() error
+	flushersMutex sync.Mutex
+
+	// shuttingDown is set as soon as HandleShutdown receives the signal; fileWatchCallbacksIdle is closed once no file watch callbacks are running anymore
+	shuttingDown           bool
+	fileWatchCallbacks     int
+	fileWatchCallbacksIdle chan struct{}
+	fileWatchMutex         sync.Mutex
+)
+
+// RegisterStartupCheck registers a named check to be run by Startup; readiness is withheld until Startup has run with all checks passing
+func RegisterStartupCheck(name string, check StartupCheck) {
+	startupChecksMutex.Lock()
+	defer startupChecksMutex.Unlock()
+
+	startupChecks = append(startupChecks, namedStartupCheck{name: name, check: check})
+
+	SetReadiness(false)
+}
+
+// Startup runs all registered startup checks in order of registration, logs a single structured report and marks the application as ready if all checks pass
+func Startup(ctx context.Context) error {
+
+	report := runStartupChecks(ctx)
+
+	logStartupReport(report)
+
+	if !report.Passed {
+		SetReadiness(false)
+		return ErrStartupChecksFailed
+	}
+
+	SetReadiness(true)
+
+	return nil
+}
+
+func runStartupChecks(ctx context.Context) (report StartupReport) {
+	startupChecksMutex.Lock()
+	checks := make([]namedStartupCheck, len(startupChecks))
+	copy(checks, startupChecks)
+	startupChecksMutex.Unlock()
+
+	report.Passed = true
+	report.Checks = make([]StartupCheckResult, 0, len(checks))
+
+	for _, c := range checks {
+		start := time.Now()
+		err := c.check(ctx)
+
+		result := StartupCheckResult{
+			Name:     c.name,
+			Passed:   err == nil,
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			report.Passed = false
+		}
+
+		report.Checks = append(report.Checks, result)
+	}
+
+	return
+}
+
+func logStartupReport(report StartupReport) {
+	if report.Passed {
+		log.Info().
+			Interface("checks", report.Checks).
+			Msgf("All %v startup checks passed", len(report.Checks))
+		return
+	}
+
+	failed := 0
+	for _, c := range report.Checks {
+		if !c.Passed {
+			failed++
+		}
+	}
+
+	log.Error().
+		Interface("checks", report.Checks).
+		Msgf("%v out of %v startup checks failed", failed, len(report.Checks))
+}
+
+// RegisterShutdownHook registers a named function to be run by HandleGracefulShutdown after pending work has finished, like closing database connections; hooks run in reverse order of registration
+func RegisterShutdownHook(name string, hook func() error) {
+	shutdownHooksMutex.Lock()
+	defer shutdownHooksMutex.Unlock()
+
+	shutdownHooks = append(shutdownHooks, namedShutdownHook{name: name, hook: hook})
+}
+
+// RegisterShutdownHookWithDryRun is RegisterShutdownHook with a dryRun function that is run instead of hook by RehearseShutdown; dryRun should verify hook would succeed without changing anything, like pinging the database instead of closing the connection
+func RegisterShutdownHookWithDryRun(name string, hook func() error, dryRun func() error) {
+	shutdownHooksMutex.Lock()
+	defer shutdownHooksMutex.Unlock()
+
+	shutdownHooks = append(shutdownHooks, namedShutdownHook{name: name, hook: hook, dryRun: dryRun})
+}
+
+func runShutdownHooks() {
+	shutdownHooksMutex.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	shutdownHooksMutex.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		start := time.Now()
+		err := hooks[i].hook()
+		recordShutdownHookDuration(hooks[i].name, time.Since(start))
+		if err != nil {
+			log.Warn().Err(err).Msgf("Shutdown hook %v failed", hooks[i].name)
+		}
+	}
+}
+
+func recordShutdownHookDuration(name string, duration time.Duration) {
+	shutdownHooksMutex.Lock()
+	defer shutdownHooksMutex.Unlock()
+
+	if shutdownHookDurations == nil {
+		shutdownHookDurations = map[string]time.Duration{}
+	}
+	shutdownHookDurations[name] = duration
+}
+
+// beginShutdown marks the start of the shutdown and returns a channel that is closed once all running file watch callbacks have finished
+func beginShutdown() <-chan struct{} {
+	fileWatchMutex.Lock()
+	defer fileWatchMutex.Unlock()
+
+	shuttingDown = true
+	if fileWatchCallbacksIdle == nil {
+		fileWatchCallbacksIdle = make(chan struct{})
+		if fileWatchCallbacks == 0 {
+			close(fileWatchCallbacksIdle)
+		}
+	}
+
+	return fileWatchCallbacksIdle
+}
+
+// startFileWatchCallback tracks a file watch callback about to run, so shutdown waits for it; returns false if the callback should be skipped because shutdown has begun
+func startFileWatchCallback(skipOnShutdown bool) bool {
+	fileWatchMutex.Lock()
+	defer fileWatchMutex.Unlock()
+
+	if shuttingDown && skipOnShutdown {
+		return false
+	}
+	if fileWatchCallbacks == 0 && fileWatchCallbacksIdle != nil {
+		// the channel was already closed during shutdown, replace it so it isn't closed twice
+		fileWatchCallbacksIdle = make(chan struct{})
+	}
+	fileWatchCallbacks++
+
+	return true
+}
+
+func finishFileWatchCallback() {
+	fileWatchMutex.Lock()
+	defer fileWatchMutex.Unlock()
+
+	fileWatchCallbacks--
+	if fileWatchCallbacks == 0 && fileWatchCallbacksIdle != nil {
+		close(fileWatchCallbacksIdle)
+	}
+}
+
+// resetShutdown undoes beginShutdown; for usage in tests
+func resetShutdown() {
+	fileWatchMutex.Lock()
+	defer fileWatchMutex.Unlock()
+
+	shuttingDown = false
+	fileWatchCallbacks = 0
+	fileWatchCallbacksIdle = nil
+}
+
+// ResetLifecycle forgets all registered startup checks, readiness checks, shutdown hooks, flushers, tracked wait groups and warm-ups, undoes draining and the start of the shutdown and marks the application as ready; for usage in tests that run the lifecycle more than once in the same process
+func ResetLifecycle() {
+	resetShutdown()
+	resetStartupChecks()
+	resetReadinessChecks()
+	resetTrackedWaitGroups()
+	resetWarmUp()
+
+	shutdownHooksMutex.Lock()
+	shutdownHooks = nil
+	shutdownHookDurations = nil
+	shutdownHooksMutex.Unlock()
+
+	flushersMutex.Lock()
+	flushers = nil
+	flushersMutex.Unlock()
+}
+
+// FlushOnShutdown registers a buffered writer, metrics pusher or trace exporter to be flushed as the very last step of HandleGracefulShutdown, after pending work has finished and the shutdown hooks have run; w needs a Flush() error, Flush() or Sync() error method, like *bufio.Writer, http.Flusher or *os.File
+func FlushOnShutdown(w interface{}) error {
+	var flush func() error
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		flush = f.Flush
+	case interface{ Flush() }:
+		flush = func() error { f.Flush(); return nil }
+	case interface{ Sync() error }:
+		flush = f.Sync
+	default:
+		return ErrNotFlushable
+	}
+
+	flushersMutex.Lock()
+	defer flushersMutex.Unlock()
+
+	flushers = append(flushers, flush)
+
+	return nil
+}
+
+func runFlushers() {
+	flushersMutex.Lock()
+	toFlush := flushers
+	flushers = nil
+	flushersMutex.Unlock()
+
+	for _, flush := range toFlush {
+		if err := flush(); err != nil {
+			log.Warn().Err(err).Msg("Flushing on shutdown failed")
+		}
+	}
+}
+
+// resetStartupChecks clears all registered startup checks and marks the application as ready; for usage in tests
+func resetStartupChecks() {
+	startupChecksMutex.Lock()
+	defer startupChecksMutex.Unlock()
+
+	startupChecks = nil
+
+	SetReadiness(true)
+}
+
+type checkOnlyContextKey struct{}
+
+// IsCheckOnly returns whether startup checks are run by ValidateConfigIfRequested, in which case they should only verify and not change anything, like applying migrations
+func IsCheckOnly(ctx context.Context) bool {
+	checkOnly, _ := ctx.Value(checkOnlyContextKey{}).(bool)
+	return checkOnly
+}
+
+// ConfigValidator is implemented by config structs that need validation beyond required envvars
+type ConfigValidator interface {
+	Validate() error
+}
+
+// ValidateConfigIfRequested loads the config from envvars, validates it, runs the registered startup checks in check-only mode, prints a report and exits if the application is started with --validate-config, so config changes can be validated in CI before they get deployed
+func ValidateConfigIfRequested(ctx context.Context, cfg interface{}) {
+	if !StringArrayContains(os.Args[1:], "--validate-config") {
+		return
+	}
+
+	report := validateConfig(ctx, cfg)
+
+	data, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(data))
+
+	if !report.Passed {
+		exit(1)
+		return
+	}
+	exit(0)
+}
+
+func validateConfig(ctx context.Context, cfg interface{}) StartupReport {
+	start := time.Now()
+	err := LoadConfigFromEnv(cfg)
+	if err == nil {
+		if validator, ok := cfg.(ConfigValidator); ok {
+			err = validator.Validate()
+		}
+	}
+
+	configResult := StartupCheckResult{
+		Name:     "config",
+		Passed:   err == nil,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		configResult.Error = err.Error()
+	}
+
+	report := runStartupChecks(context.WithValue(ctx, checkOnlyContextKey{}, true))
+	report.Checks = append([]StartupCheckResult{configResult}, report.Checks...)
+	report.Passed = report.Passed && configResult.Passed
+
+	return report
+}
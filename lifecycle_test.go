@@ -0,0 +1,155 @@
+package foundation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartup(t *testing.T) {
+
+	t.Run("ReturnsNilAndMarksReadyIfAllChecksPass", func(t *testing.T) {
+
+		defer resetStartupChecks()
+		RegisterStartupCheck("config", func(ctx context.Context) error { return nil })
+		RegisterStartupCheck("migrations", func(ctx context.Context) error { return nil })
+
+		assert.False(t, IsReady())
+
+		// act
+		err := Startup(context.Background())
+
+		assert.Nil(t, err)
+		assert.True(t, IsReady())
+	})
+
+	t.Run("ReturnsErrorAndDoesNotMarkReadyIfAnyCheckFails", func(t *testing.T) {
+
+		defer resetStartupChecks()
+		RegisterStartupCheck("config", func(ctx context.Context) error { return nil })
+		RegisterStartupCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+
+		// act
+		err := Startup(context.Background())
+
+		assert.True(t, errors.Is(err, ErrStartupChecksFailed))
+		assert.False(t, IsReady())
+	})
+
+	t.Run("RunsAllChecksEvenIfOneFails", func(t *testing.T) {
+
+		defer resetStartupChecks()
+		RegisterStartupCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+		RegisterStartupCheck("config", func(ctx context.Context) error { return nil })
+
+		// act
+		report := runStartupChecks(context.Background())
+
+		assert.False(t, report.Passed)
+		if assert.Equal(t, 2, len(report.Checks)) {
+			assert.Equal(t, "database", report.Checks[0].Name)
+			assert.False(t, report.Checks[0].Passed)
+			assert.Equal(t, "connection refused", report.Checks[0].Error)
+			assert.Equal(t, "config", report.Checks[1].Name)
+			assert.True(t, report.Checks[1].Passed)
+		}
+	})
+}
+
+func TestRunShutdownHooks(t *testing.T) {
+
+	t.Run("RunsHooksInReverseOrderOfRegistration", func(t *testing.T) {
+
+		order := []string{}
+		RegisterShutdownHook("database", func() error { order = append(order, "database"); return nil })
+		RegisterShutdownHook("cache", func() error { order = append(order, "cache"); return errors.New("already closed") })
+
+		// act
+		runShutdownHooks()
+
+		assert.Equal(t, []string{"cache", "database"}, order)
+	})
+}
+
+type fakeSyncer struct {
+	synced bool
+}
+
+func (s *fakeSyncer) Sync() error {
+	s.synced = true
+	return nil
+}
+
+func TestFlushOnShutdown(t *testing.T) {
+
+	t.Run("FlushesBufferedWritersAndSyncsFilesOnShutdown", func(t *testing.T) {
+
+		var buffer bytes.Buffer
+		writer := bufio.NewWriter(&buffer)
+		writer.WriteString("Shutting down...")
+		syncer := &fakeSyncer{}
+		FlushOnShutdown(writer)
+		FlushOnShutdown(syncer)
+
+		// act
+		runFlushers()
+
+		assert.Equal(t, "Shutting down...", buffer.String())
+		assert.True(t, syncer.synced)
+	})
+
+	t.Run("ReturnsErrorForValueWithoutFlushOrSyncMethod", func(t *testing.T) {
+
+		// act
+		err := FlushOnShutdown(&bytes.Buffer{})
+
+		assert.Equal(t, ErrNotFlushable, err)
+	})
+}
+
+type validatedTestConfig struct {
+	Workers int `env:"ESTAFETTE_WORKERS" default:"4"`
+}
+
+func (c *validatedTestConfig) Validate() error {
+	if c.Workers < 1 {
+		return errors.New("Workers has to be at least 1")
+	}
+	return nil
+}
+
+func TestValidateConfig(t *testing.T) {
+
+	t.Run("RunsStartupChecksInCheckOnlyMode", func(t *testing.T) {
+
+		defer resetStartupChecks()
+		checkOnly := false
+		RegisterStartupCheck("migrations", func(ctx context.Context) error {
+			checkOnly = IsCheckOnly(ctx)
+			return nil
+		})
+
+		// act
+		report := validateConfig(context.Background(), &validatedTestConfig{})
+
+		assert.True(t, report.Passed)
+		assert.True(t, checkOnly)
+		assert.Equal(t, "config", report.Checks[0].Name)
+		assert.Equal(t, "migrations", report.Checks[1].Name)
+	})
+
+	t.Run("FailsIfConfigDoesNotValidate", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_WORKERS", "0")
+
+		// act
+		report := validateConfig(context.Background(), &validatedTestConfig{})
+
+		assert.False(t, report.Passed)
+		assert.Equal(t, "Workers has to be at least 1", report.Checks[0].Error)
+	})
+}
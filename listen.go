@@ -0,0 +1,83 @@
+package foundation
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+// systemdListenFDsStart is the file descriptor of the first socket passed by systemd socket activation
+var systemdListenFDsStart = 3
+
+// systemdListenFiles keeps the files for the sockets passed by systemd that haven't been taken, so they aren't closed when garbage collected
+var systemdListenFiles = map[int]*os.File{}
+
+// ListenOrInherit returns a tcp listener for addr, taken over from systemd socket activation (LISTEN_FDS) or from the parent process started by ReExecOnChange if available, and from net.Listen otherwise; the listener is registered with RegisterListener so it's handed over on the next re-exec as well
+func ListenOrInherit(addr string) (net.Listener, error) {
+
+	l, err := systemdListener(addr)
+	if err == ErrNoInheritedListener {
+		l, err = InheritedListener(addr)
+	}
+	if err == ErrNoInheritedListener {
+		l, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	RegisterListener(l)
+
+	return l, nil
+}
+
+// GetFreePort returns a tcp port that is free at the time of the call, for tests that need to pass a port instead of a listener; prefer listening on port 0 where possible, since another process can take the port before it's used
+func GetFreePort() (int, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// systemdListener returns the socket passed by systemd with an address matching addr, or ErrNoInheritedListener
+func systemdListener(addr string) (net.Listener, error) {
+	inheritedListenersMutex.Lock()
+	defer inheritedListenersMutex.Unlock()
+
+	// the sockets are meant for this process only, not for processes it starts
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, ErrNoInheritedListener
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || inheritedListenersTaken[addr] {
+		return nil, ErrNoInheritedListener
+	}
+
+	for fd := systemdListenFDsStart; fd < systemdListenFDsStart+count; fd++ {
+		file, ok := systemdListenFiles[fd]
+		if !ok {
+			file = os.NewFile(uintptr(fd), "systemd")
+			systemdListenFiles[fd] = file
+		}
+		l, err := net.FileListener(file)
+		if err != nil {
+			// not a listening socket, like a datagram socket
+			continue
+		}
+		if !sameListenAddress(l.Addr().String(), addr) {
+			l.Close()
+			continue
+		}
+		// FileListener dups the file descriptor, so the original can be closed
+		file.Close()
+		delete(systemdListenFiles, fd)
+		inheritedListenersTaken[addr] = true
+
+		return l, nil
+	}
+
+	return nil, ErrNoInheritedListener
+}
@@ -0,0 +1,58 @@
+package foundation
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenOrInherit(t *testing.T) {
+
+	t.Run("ReturnsSocketPassedBySystemdForAddress", func(t *testing.T) {
+
+		l, _ := net.Listen("tcp", "127.0.0.1:0")
+		defer l.Close()
+		defer func(fd int) { systemdListenFDsStart = fd }(systemdListenFDsStart)
+		systemdListenFDsStart = dupListenerFD(l)
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		t.Setenv("LISTEN_FDS", "1")
+		defer delete(inheritedListenersTaken, l.Addr().String())
+		defer func() { registeredListeners = nil }()
+
+		// act
+		inherited, err := ListenOrInherit(l.Addr().String())
+
+		if assert.Nil(t, err) {
+			defer inherited.Close()
+			assert.Equal(t, l.Addr().String(), inherited.Addr().String())
+			assert.Equal(t, []net.Listener{inherited}, registeredListeners)
+		}
+	})
+
+	t.Run("IgnoresSocketsPassedBySystemdToOtherProcess", func(t *testing.T) {
+
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+		t.Setenv("LISTEN_FDS", "1")
+
+		// act
+		_, err := systemdListener(":5000")
+
+		assert.Equal(t, ErrNoInheritedListener, err)
+	})
+
+	t.Run("FallsBackToNetListen", func(t *testing.T) {
+
+		defer func() { registeredListeners = nil }()
+
+		// act
+		l, err := ListenOrInherit("127.0.0.1:0")
+
+		if assert.Nil(t, err) {
+			defer l.Close()
+			assert.Equal(t, []net.Listener{l}, registeredListeners)
+		}
+	})
+}
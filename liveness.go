@@ -4,31 +4,37 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-
-	"github.com/rs/zerolog/log"
 )
 
-// InitLiveness initializes the /liveness endpoint on port 5000
-func InitLiveness() {
-	InitLivenessWithPort(5000)
-}
+// InitLiveness initializes the /liveness endpoint on port 5000; override with InitOption
+func InitLiveness(opts ...InitOption) {
+	config := newInitConfig(5000, opts)
 
-// InitLivenessWithPort initializes the /liveness endpoint on specified port
-func InitLivenessWithPort(port int) {
 	// start liveness endpoint
 	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
+		portString := fmt.Sprintf(":%v", config.Port)
+		config.Logger.Debug().
 			Str("port", portString).
-			Msg("Serving /liveness endpoint...")
+			Msgf("Serving %v endpoint...", config.LivenessPath)
 
 		serverMux := http.NewServeMux()
-		serverMux.HandleFunc("/liveness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm alive!\n")
-		})
+		serverMux.HandleFunc(config.LivenessPath, livenessHandler)
+
+		for path, handler := range config.Handlers {
+			serverMux.Handle(path, handler)
+		}
 
 		if err := http.ListenAndServe(portString, serverMux); err != nil {
-			log.Fatal().Err(err).Msg("Starting /liveness listener failed")
+			config.Logger.Fatal().Err(err).Msgf("Starting %v listener failed", config.LivenessPath)
 		}
 	}()
 }
+
+// InitLivenessWithPort initializes the /liveness endpoint on specified port
+func InitLivenessWithPort(port int) {
+	InitLiveness(WithPort(port))
+}
+
+func livenessHandler(w http.ResponseWriter, _ *http.Request) {
+	io.WriteString(w, "I'm alive!\n")
+}
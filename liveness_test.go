@@ -1,6 +1,7 @@
 package foundation
 
 import (
+	"fmt"
 	"io/ioutil"
 	"testing"
 
@@ -12,10 +13,12 @@ func TestInitLiveness(t *testing.T) {
 
 	t.Run("Returns200OK", func(t *testing.T) {
 
+		port, _ := GetFreePort()
+
 		// act
-		InitLivenessWithPort(5001)
+		InitLivenessWithPort(port)
 
-		resp, err := pester.Get("http://localhost:5001/liveness")
+		resp, err := pester.Get(fmt.Sprintf("http://localhost:%v/liveness", port))
 
 		if assert.Nil(t, err) {
 
@@ -0,0 +1,55 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LongPollWaitFunc blocks until there's a response to return or ctx is done; it returns ok false if there's nothing to return
+type LongPollWaitFunc func(ctx context.Context) (response interface{}, ok bool, err error)
+
+// LongPollContext returns a context for a long-poll request that ends when the client disconnects or after timeout with +-25% jitter applied, so clients polling at the same time get spread out
+func LongPollContext(r *http.Request, timeout time.Duration) (context.Context, context.CancelFunc) {
+	jitteredTimeout := timeout
+	if timeout >= 4*time.Millisecond {
+		jitteredTimeout = time.Duration(ApplyJitter(int(timeout/time.Millisecond))) * time.Millisecond
+	}
+
+	return context.WithTimeout(r.Context(), jitteredTimeout)
+}
+
+// NewLongPollHandler returns an http.Handler that waits up to timeout for wait to return a response, which is written as json; if nothing arrives in time it responds with 204 No Content so the client polls again
+func NewLongPollHandler(timeout time.Duration, wait LongPollWaitFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		ctx, cancel := LongPollContext(r, timeout)
+		defer cancel()
+
+		response, ok, err := wait(ctx)
+
+		// the client went away, nobody to respond to
+		if r.Context().Err() != nil {
+			return
+		}
+
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msgf("Long poll for %v failed", r.URL.Path)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if err != nil || !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error().Err(err).Msgf("Writing long poll response for %v failed", r.URL.Path)
+		}
+	})
+}
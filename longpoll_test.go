@@ -0,0 +1,88 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLongPollContext(t *testing.T) {
+
+	t.Run("ReturnsContextWithDeadlineWithinJitterOfTimeout", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodGet, "/api/jobs/poll", nil)
+
+		// act
+		ctx, cancel := LongPollContext(request, 40*time.Second)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.True(t, time.Until(deadline) > 29*time.Second)
+		assert.True(t, time.Until(deadline) <= 50*time.Second)
+	})
+
+	t.Run("KeepsTimeoutTooSmallToApplyJitterTo", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodGet, "/api/jobs/poll", nil)
+		start := time.Now()
+
+		// act
+		ctx, cancel := LongPollContext(request, 500*time.Microsecond)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.True(t, deadline.Sub(start) >= 500*time.Microsecond)
+	})
+}
+
+func TestNewLongPollHandler(t *testing.T) {
+
+	t.Run("RespondsWithJsonWhenEventArrives", func(t *testing.T) {
+
+		handler := NewLongPollHandler(time.Second, func(ctx context.Context) (interface{}, bool, error) {
+			return map[string]string{"job": "build-1"}, true, nil
+		})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs/poll", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "{\"job\":\"build-1\"}\n", recorder.Body.String())
+	})
+
+	t.Run("RespondsWithNoContentWhenTimeoutExpires", func(t *testing.T) {
+
+		handler := NewLongPollHandler(20*time.Millisecond, func(ctx context.Context) (interface{}, bool, error) {
+			<-ctx.Done()
+			return nil, false, ctx.Err()
+		})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs/poll", nil))
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("RespondsWithInternalServerErrorWhenWaitFails", func(t *testing.T) {
+
+		handler := NewLongPollHandler(time.Second, func(ctx context.Context) (interface{}, bool, error) {
+			return nil, false, errors.New("queue unavailable")
+		})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs/poll", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+}
@@ -0,0 +1,132 @@
+package foundation
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrMergeTypeMismatch is returned by MergeStructs if dst isn't a pointer to a struct of the same type as src
+var ErrMergeTypeMismatch = errors.New("Merge requires dst to be a pointer to a struct of the same type as src")
+
+// MergeStrategy sets how slices are merged
+type MergeStrategy int
+
+const (
+	// MergeReplaceSlices replaces a slice in dst with a non-empty slice in src
+	MergeReplaceSlices MergeStrategy = iota
+	// MergeAppendSlices appends the items of a slice in src to the slice in dst
+	MergeAppendSlices
+)
+
+// MergeMaps deep merges src into dst and returns dst, to layer default, global and per-pipeline configuration; nested maps and ordered maps are merged key by key, slices according to strategy, and any other value in src replaces the one in dst
+func MergeMaps(dst, src map[string]interface{}, strategy MergeStrategy) map[string]interface{} {
+	if dst == nil {
+		dst = map[string]interface{}{}
+	}
+
+	for key, srcValue := range src {
+		dst[key] = mergeValues(dst[key], srcValue, strategy)
+	}
+
+	return dst
+}
+
+func mergeValues(dstValue, srcValue interface{}, strategy MergeStrategy) interface{} {
+	switch src := srcValue.(type) {
+	case map[string]interface{}:
+		if dst, ok := dstValue.(map[string]interface{}); ok {
+			return MergeMaps(dst, src, strategy)
+		}
+	case *OrderedMap:
+		if dst, ok := dstValue.(*OrderedMap); ok {
+			for _, key := range src.Keys() {
+				srcItem, _ := src.Get(key)
+				dstItem, _ := dst.Get(key)
+				dst.Set(key, mergeValues(dstItem, srcItem, strategy))
+			}
+			return dst
+		}
+	case []interface{}:
+		if dst, ok := dstValue.([]interface{}); ok && strategy == MergeAppendSlices {
+			return append(append([]interface{}{}, dst...), src...)
+		}
+	}
+
+	return srcValue
+}
+
+// MergeStructs overrides the fields of the struct dst points to with the non-zero fields of src, which is a struct or pointer to a struct of the same type; nested structs and non-nil struct pointers are merged field by field, maps key by key and slices according to strategy. Unexported fields are left untouched
+func MergeStructs(dst, src interface{}, strategy MergeStrategy) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrMergeTypeMismatch
+	}
+
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Kind() == reflect.Ptr {
+		if srcValue.IsNil() {
+			return nil
+		}
+		srcValue = srcValue.Elem()
+	}
+	if srcValue.Type() != dstValue.Elem().Type() {
+		return ErrMergeTypeMismatch
+	}
+
+	mergeStructValues(dstValue.Elem(), srcValue, strategy)
+
+	return nil
+}
+
+func mergeStructValues(dst, src reflect.Value, strategy MergeStrategy) {
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Type().Field(i).PkgPath != "" {
+			// unexported
+			continue
+		}
+		mergeReflectValues(dst.Field(i), src.Field(i), strategy)
+	}
+}
+
+func mergeReflectValues(dst, src reflect.Value, strategy MergeStrategy) {
+	switch src.Kind() {
+	case reflect.Struct:
+		if isTextUnmarshaler(dst.Type()) {
+			// types like time.Time or URL are values, not structs to merge field by field
+			break
+		}
+		mergeStructValues(dst, src, strategy)
+		return
+	case reflect.Ptr:
+		if !src.IsNil() && !dst.IsNil() && src.Elem().Kind() == reflect.Struct {
+			mergeReflectValues(dst.Elem(), src.Elem(), strategy)
+			return
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		if strategy == MergeAppendSlices {
+			// copy, so dst doesn't share its backing array with the slice it was merged from
+			merged := reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len())
+			dst.Set(reflect.AppendSlice(reflect.AppendSlice(merged, dst), src))
+			return
+		}
+	}
+
+	if !src.IsZero() {
+		dst.Set(src)
+	}
+}
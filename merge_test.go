@@ -0,0 +1,116 @@
+package foundation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeMaps(t *testing.T) {
+
+	t.Run("DeepMergesNestedMaps", func(t *testing.T) {
+
+		defaults := map[string]interface{}{"builder": map[string]interface{}{"track": "stable", "os": "linux"}, "timeout": 60}
+		pipeline := map[string]interface{}{"builder": map[string]interface{}{"track": "dev"}}
+
+		// act
+		merged := MergeMaps(defaults, pipeline, MergeReplaceSlices)
+
+		assert.Equal(t, map[string]interface{}{"builder": map[string]interface{}{"track": "dev", "os": "linux"}, "timeout": 60}, merged)
+	})
+
+	t.Run("ReplacesSlicesWithReplaceStrategy", func(t *testing.T) {
+
+		dst := map[string]interface{}{"labels": []interface{}{"a"}}
+
+		// act
+		merged := MergeMaps(dst, map[string]interface{}{"labels": []interface{}{"b"}}, MergeReplaceSlices)
+
+		assert.Equal(t, []interface{}{"b"}, merged["labels"])
+	})
+
+	t.Run("AppendsSlicesWithAppendStrategy", func(t *testing.T) {
+
+		dst := map[string]interface{}{"labels": []interface{}{"a"}}
+
+		// act
+		merged := MergeMaps(dst, map[string]interface{}{"labels": []interface{}{"b"}}, MergeAppendSlices)
+
+		assert.Equal(t, []interface{}{"a", "b"}, merged["labels"])
+	})
+
+	t.Run("MergesOrderedMapsKeepingKeyOrder", func(t *testing.T) {
+
+		dstStages := NewOrderedMap()
+		dstStages.Set("build", "go build")
+		dstStages.Set("test", "go test")
+		srcStages := NewOrderedMap()
+		srcStages.Set("test", "go test -race")
+		srcStages.Set("push", "docker push")
+
+		// act
+		merged := MergeMaps(map[string]interface{}{"stages": dstStages}, map[string]interface{}{"stages": srcStages}, MergeReplaceSlices)
+
+		stages := merged["stages"].(*OrderedMap)
+		assert.Equal(t, []string{"build", "test", "push"}, stages.Keys())
+		test, _ := stages.Get("test")
+		assert.Equal(t, "go test -race", test)
+	})
+}
+
+type mergeTestConfig struct {
+	Name     string
+	Replicas int
+	Timeout  time.Duration
+	Since    time.Time
+	Labels   map[string]string
+	Args     []string
+	Builder  mergeTestBuilder
+	Registry *mergeTestBuilder
+	internal string
+}
+
+type mergeTestBuilder struct {
+	Track string
+	OS    string
+}
+
+func TestMergeStructs(t *testing.T) {
+
+	t.Run("OverridesWithNonZeroFields", func(t *testing.T) {
+
+		since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		dst := mergeTestConfig{Name: "default", Replicas: 2, Timeout: time.Minute, Builder: mergeTestBuilder{Track: "stable", OS: "linux"}, Registry: &mergeTestBuilder{OS: "linux"}, internal: "kept"}
+		src := mergeTestConfig{Replicas: 3, Since: since, Builder: mergeTestBuilder{Track: "dev"}, Registry: &mergeTestBuilder{Track: "beta"}, internal: "ignored"}
+
+		// act
+		err := MergeStructs(&dst, src, MergeReplaceSlices)
+
+		assert.Nil(t, err)
+		assert.Equal(t, mergeTestConfig{Name: "default", Replicas: 3, Timeout: time.Minute, Since: since, Builder: mergeTestBuilder{Track: "dev", OS: "linux"}, Registry: &mergeTestBuilder{Track: "beta", OS: "linux"}, internal: "kept"}, dst)
+	})
+
+	t.Run("MergesMapsAndSlicesAccordingToStrategy", func(t *testing.T) {
+
+		dst := mergeTestConfig{Labels: map[string]string{"team": "ci", "tier": "1"}, Args: []string{"--verbose"}}
+		src := &mergeTestConfig{Labels: map[string]string{"tier": "2"}, Args: []string{"--race"}}
+
+		// act
+		err := MergeStructs(&dst, src, MergeAppendSlices)
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"team": "ci", "tier": "2"}, dst.Labels)
+		assert.Equal(t, []string{"--verbose", "--race"}, dst.Args)
+	})
+
+	t.Run("ReturnsErrorForDifferentTypes", func(t *testing.T) {
+
+		dst := mergeTestConfig{}
+
+		// act
+		err := MergeStructs(&dst, mergeTestBuilder{}, MergeReplaceSlices)
+
+		assert.Equal(t, ErrMergeTypeMismatch, err)
+	})
+}
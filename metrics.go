@@ -1,31 +1,54 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
 package foundation
 
 import (
 	"fmt"
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/rs/zerolog/log"
 )
 
-// InitMetrics initializes the prometheus endpoint /metrics on port 9101
-func InitMetrics() {
-	InitMetricsWithPort(9101)
-}
+// InitMetrics initializes the prometheus endpoint /metrics on port 9101; override with InitOption, for example WithOTLPExport to push the metrics to an OpenTelemetry collector as well
+func InitMetrics(opts ...InitOption) {
+	config := newInitConfig(9101, opts)
+
+	if config.OTLPEndpoint != "" {
+		exporter := NewOTLPExporter(config.OTLPEndpoint, config.OTLPOptions...)
+		FlushOnShutdown(exporter)
+	}
 
-// InitMetricsWithPort initializes the prometheus endpoint /metrics on specified port
-func InitMetricsWithPort(port int) {
 	// start prometheus
 	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
+		portString := fmt.Sprintf(":%v", config.Port)
+		config.Logger.Debug().
 			Str("port", portString).
 			Msg("Serving Prometheus metrics...")
 
-		http.Handle("/metrics", promhttp.Handler())
+		http.Handle(config.MetricsPath, promhttp.Handler())
+		for path, handler := range config.Handlers {
+			http.Handle(path, handler)
+		}
 
 		if err := http.ListenAndServe(portString, nil); err != nil {
-			log.Fatal().Err(err).Msg("Starting Prometheus listener failed")
+			config.Logger.Fatal().Err(err).Msg("Starting Prometheus listener failed")
 		}
 	}()
 }
+
+// InitMetricsWithPort initializes the prometheus endpoint /metrics on specified port
+func InitMetricsWithPort(port int) {
+	InitMetrics(WithPort(port))
+}
+
+// DefaultDurationBuckets returns histogram buckets in seconds from 1 millisecond to 4 hours, covering both http requests and complete builds, so durations are comparable across services
+func DefaultDurationBuckets() []float64 {
+	return []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400}
+}
+
+// DefaultSizeBuckets returns histogram buckets in bytes from 256 bytes to 4 gibibytes, each 4 times the previous one, covering both payloads and build artifacts or caches
+func DefaultSizeBuckets() []float64 {
+	return prometheus.ExponentialBuckets(256, 4, 13)
+}
@@ -0,0 +1,37 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultDurationBuckets(t *testing.T) {
+
+	t.Run("ReturnsSortedBucketsFromMillisecondsToHours", func(t *testing.T) {
+
+		// act
+		buckets := DefaultDurationBuckets()
+
+		assert.True(t, sort.Float64sAreSorted(buckets))
+		assert.Equal(t, 0.001, buckets[0])
+		assert.Equal(t, 14400.0, buckets[len(buckets)-1])
+	})
+}
+
+func TestDefaultSizeBuckets(t *testing.T) {
+
+	t.Run("ReturnsSortedBucketsFromBytesToGigabytes", func(t *testing.T) {
+
+		// act
+		buckets := DefaultSizeBuckets()
+
+		assert.True(t, sort.Float64sAreSorted(buckets))
+		assert.Equal(t, 256.0, buckets[0])
+		assert.Equal(t, float64(4*1024*1024*1024), buckets[len(buckets)-1])
+	})
+}
@@ -0,0 +1,173 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// migrationsLockID is the key of the postgres advisory lock that makes sure only one replica runs migrations at a time
+const migrationsLockID int64 = 7289624001
+
+type migration struct {
+	version string
+	query   string
+}
+
+// RunMigrations applies the .sql files in the root of fsys in lexical order, each exactly once, recording applied versions in the schema_migrations table; an advisory lock makes other replicas wait until migrations have finished, and readiness is withheld while they run. In check-only mode the pending migrations are only reported
+func RunMigrations(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	// the advisory lock is held by a session, so all statements have to use the same connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if IsCheckOnly(ctx) {
+		return reportPendingMigrations(ctx, conn, migrations)
+	}
+
+	wasReady := IsReady()
+	SetReadiness(false)
+	defer SetReadiness(wasReady)
+
+	log.Info().Msg("Acquiring migrations lock...")
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationsLockID); err != nil {
+		return fmt.Errorf("Acquiring migrations lock failed: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLockID); err != nil {
+			log.Warn().Err(err).Msg("Releasing migrations lock failed")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT now())"); err != nil {
+		return fmt.Errorf("Creating schema_migrations table failed: %w", err)
+	}
+
+	applied, err := readAppliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		log.Info().Msgf("Applying migration %v...", m.version)
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return fmt.Errorf("Applying migration %v failed: %w", m.version, err)
+		}
+		count++
+	}
+
+	log.Info().Msgf("Applied %v migrations, %v were applied before", count, len(migrations)-count)
+
+	return nil
+}
+
+// reportPendingMigrations logs the versions that haven't been applied yet, without creating the schema_migrations table or taking the lock
+func reportPendingMigrations(ctx context.Context, conn *sql.Conn, migrations []migration) error {
+	var tableExists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&tableExists); err != nil {
+		return fmt.Errorf("Checking for schema_migrations table failed: %w", err)
+	}
+
+	applied := map[string]bool{}
+	if tableExists {
+		var err error
+		applied, err = readAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	pending := []string{}
+	for _, m := range migrations {
+		if !applied[m.version] {
+			pending = append(pending, m.version)
+		}
+	}
+
+	log.Info().Msgf("Check-only, not applying %v pending migrations: %v", len(pending), strings.Join(pending, ", "))
+
+	return nil
+}
+
+func readMigrations(fsys fs.FS) ([]migration, error) {
+	fileNames, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(fileNames)
+
+	migrations := make([]migration, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		data, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{
+			version: strings.TrimSuffix(path.Base(fileName), ".sql"),
+			query:   string(data),
+		})
+	}
+
+	return migrations, nil
+}
+
+func readAppliedMigrations(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("Reading applied migrations failed: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[string]bool{}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, m.query); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", m.version); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,166 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMigrationsDriver records executed statements and keeps track of inserted schema_migrations versions
+type fakeMigrationsDriver struct {
+	mutex      sync.Mutex
+	statements []string
+	versions   []string
+}
+
+func (d *fakeMigrationsDriver) Open(name string) (driver.Conn, error) {
+	return &fakeMigrationsConn{driver: d}, nil
+}
+
+func (d *fakeMigrationsDriver) reset(versions ...string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.statements = nil
+	d.versions = versions
+}
+
+func (d *fakeMigrationsDriver) executed() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]string{}, d.statements...)
+}
+
+type fakeMigrationsConn struct {
+	driver *fakeMigrationsDriver
+}
+
+func (c *fakeMigrationsConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c *fakeMigrationsConn) Close() error              { return nil }
+func (c *fakeMigrationsConn) Begin() (driver.Tx, error) { return &fakeDatabaseTx{}, nil }
+
+func (c *fakeMigrationsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.mutex.Lock()
+	defer c.driver.mutex.Unlock()
+
+	if strings.Contains(query, "syntax error") {
+		return nil, errors.New("pq: syntax error")
+	}
+	c.driver.statements = append(c.driver.statements, query)
+	if strings.HasPrefix(query, "INSERT INTO schema_migrations") {
+		c.driver.versions = append(c.driver.versions, args[0].Value.(string))
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeMigrationsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.mutex.Lock()
+	defer c.driver.mutex.Unlock()
+
+	if strings.Contains(query, "to_regclass") {
+		return &fakeMigrationsRows{versions: []string{"true"}}, nil
+	}
+
+	return &fakeMigrationsRows{versions: append([]string{}, c.driver.versions...)}, nil
+}
+
+type fakeMigrationsRows struct {
+	versions []string
+}
+
+func (r *fakeMigrationsRows) Columns() []string { return []string{"version"} }
+func (r *fakeMigrationsRows) Close() error      { return nil }
+func (r *fakeMigrationsRows) Next(dest []driver.Value) error {
+	if len(r.versions) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.versions[0]
+	r.versions = r.versions[1:]
+	return nil
+}
+
+var fakeMigrations = &fakeMigrationsDriver{}
+
+func init() {
+	sql.Register("fakemigrations", fakeMigrations)
+}
+
+func TestRunMigrations(t *testing.T) {
+
+	t.Run("AppliesPendingMigrationsInOrderWhileHoldingLock", func(t *testing.T) {
+
+		fakeMigrations.reset("0001_create_builds")
+		db, _ := sql.Open("fakemigrations", "")
+		defer db.Close()
+		fsys := fstest.MapFS{
+			"0002_add_status.sql":    {Data: []byte("ALTER TABLE builds ADD COLUMN status TEXT")},
+			"0001_create_builds.sql": {Data: []byte("CREATE TABLE builds (id INT)")},
+			"0003_add_index.sql":     {Data: []byte("CREATE INDEX builds_status ON builds (status)")},
+			"README.md":              {Data: []byte("not a migration")},
+		}
+
+		// act
+		err := RunMigrations(context.Background(), db, fsys)
+
+		assert.Nil(t, err)
+		statements := fakeMigrations.executed()
+		assert.Equal(t, "SELECT pg_advisory_lock($1)", statements[0])
+		assert.Equal(t, []string{
+			"ALTER TABLE builds ADD COLUMN status TEXT",
+			"INSERT INTO schema_migrations (version) VALUES ($1)",
+			"CREATE INDEX builds_status ON builds (status)",
+			"INSERT INTO schema_migrations (version) VALUES ($1)",
+		}, statements[2:6])
+		assert.Equal(t, "SELECT pg_advisory_unlock($1)", statements[6])
+		assert.True(t, IsReady())
+	})
+
+	t.Run("ReturnsErrorAndRestoresReadinessIfMigrationFails", func(t *testing.T) {
+
+		fakeMigrations.reset()
+		db, _ := sql.Open("fakemigrations", "")
+		defer db.Close()
+		fsys := fstest.MapFS{
+			"0001_create_builds.sql": {Data: []byte("CREATE TABLE builds syntax error")},
+		}
+
+		// act
+		err := RunMigrations(context.Background(), db, fsys)
+
+		assert.NotNil(t, err)
+		assert.True(t, IsReady())
+		statements := fakeMigrations.executed()
+		assert.Equal(t, "SELECT pg_advisory_unlock($1)", statements[len(statements)-1])
+	})
+
+	t.Run("OnlyReportsPendingMigrationsInCheckOnlyMode", func(t *testing.T) {
+
+		fakeMigrations.reset("0001_create_builds")
+		db, _ := sql.Open("fakemigrations", "")
+		defer db.Close()
+		fsys := fstest.MapFS{
+			"0001_create_builds.sql": {Data: []byte("CREATE TABLE builds (id INT)")},
+			"0002_add_status.sql":    {Data: []byte("ALTER TABLE builds ADD COLUMN status TEXT")},
+		}
+
+		// act
+		err := RunMigrations(context.WithValue(context.Background(), checkOnlyContextKey{}, true), db, fsys)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(fakeMigrations.executed()))
+		assert.True(t, IsReady())
+	})
+}
@@ -0,0 +1,39 @@
+//go:build foundation_minimal
+// +build foundation_minimal
+
+// Building with -tags foundation_minimal leaves out everything depending on prometheus, opentracing and jaeger, fsnotify or database/sql, like metrics, tracing, file watching, feature flags, webhooks and databases, for small binaries that only need the lifecycle, probe, config and string helpers.
+// The types below replace the instrumentation used by the remaining code.
+
+package foundation
+
+import "net/http"
+
+// OTLPOption allows to override the defaults of the OTLP exporter, which is left out of minimal builds
+type OTLPOption func(*OTLPConfig)
+
+// OTLPConfig is used to configure the OTLP exporter, which is left out of minimal builds
+type OTLPConfig struct{}
+
+// instrumentedRoundTripper passes requests through without recording metrics
+type instrumentedRoundTripper struct {
+	target string
+	next   http.RoundTripper
+}
+
+func (rt *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.next.RoundTrip(req)
+}
+
+// tracingRoundTripper passes requests through without starting spans, so WithHTTPTracing has no effect
+type tracingRoundTripper struct {
+	target string
+	next   http.RoundTripper
+}
+
+func (rt *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.next.RoundTrip(req)
+}
+
+func getTraceID(r *http.Request) string {
+	return ""
+}
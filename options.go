@@ -0,0 +1,193 @@
+package foundation
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// InitOption allows to override the defaults of the Init functions, WatchForFileChanges and HandleShutdown
+type InitOption func(*InitConfig)
+
+// InitConfig is used to configure the Init functions, WatchForFileChanges and HandleShutdown; each of them only uses the fields relevant to it
+type InitConfig struct {
+	Port                           int
+	LivenessPath                   string
+	ReadinessPath                  string
+	MetricsPath                    string
+	Logger                         *zerolog.Logger
+	ShutdownTimeout                time.Duration
+	FunctionsOnShutdown            []func()
+	Handlers                       map[string]http.Handler
+	CallbackTimeout                time.Duration
+	SkipCallbacksOnShutdown        bool
+	OTLPEndpoint                   string
+	OTLPOptions                    []OTLPOption
+	ReExecGracePeriod              time.Duration
+	ReExecDebounce                 time.Duration
+	BlockedShutdownWarningInterval time.Duration
+	StartupPath                    string
+	ReadinessDelay                 time.Duration
+	WarmUps                        []namedWarmUp
+	Clock                          Clock
+}
+
+// WithPort sets the port to serve the probe or metrics endpoints on
+// default is 5000 for probes and 9101 for metrics
+func WithPort(port int) InitOption {
+	return func(c *InitConfig) {
+		c.Port = port
+	}
+}
+
+// WithLivenessPath sets the path of the liveness endpoint
+// default is /liveness
+func WithLivenessPath(path string) InitOption {
+	return func(c *InitConfig) {
+		c.LivenessPath = path
+	}
+}
+
+// WithReadinessPath sets the path of the readiness endpoint
+// default is /readiness
+func WithReadinessPath(path string) InitOption {
+	return func(c *InitConfig) {
+		c.ReadinessPath = path
+	}
+}
+
+// WithStartupPath sets the path of the startup endpoint reporting the warm-up progress
+// default is /startup
+func WithStartupPath(path string) InitOption {
+	return func(c *InitConfig) {
+		c.StartupPath = path
+	}
+}
+
+// WithReadinessDelay withholds readiness until the delay has passed since the readiness endpoint was initialized, for example to let a jvm-like runtime or connection pools warm up
+// default is 0
+func WithReadinessDelay(delay time.Duration) InitOption {
+	return func(c *InitConfig) {
+		c.ReadinessDelay = delay
+	}
+}
+
+// WithWarmUp adds a function to run in the background when the readiness endpoint is initialized, like priming caches; readiness is withheld until all warm-up functions succeeded, and their progress is reported on the startup endpoint
+func WithWarmUp(name string, warmUp WarmUpFunc) InitOption {
+	return func(c *InitConfig) {
+		c.WarmUps = append(c.WarmUps, namedWarmUp{name: name, warmUp: warmUp})
+	}
+}
+
+// WithMetricsPath sets the path of the prometheus metrics endpoint
+// default is /metrics
+func WithMetricsPath(path string) InitOption {
+	return func(c *InitConfig) {
+		c.MetricsPath = path
+	}
+}
+
+// WithLogger sets the logger to log to
+// default is the global zerolog logger
+func WithLogger(logger zerolog.Logger) InitOption {
+	return func(c *InitConfig) {
+		c.Logger = &logger
+	}
+}
+
+// WithShutdownTimeout sets how long HandleShutdown waits for pending work before continuing to shut down; 0 waits indefinitely
+// default is 0
+func WithShutdownTimeout(timeout time.Duration) InitOption {
+	return func(c *InitConfig) {
+		c.ShutdownTimeout = timeout
+	}
+}
+
+// WithBlockedShutdownWarningInterval sets how often HandleShutdown logs which work tracked with a TrackedWaitGroup is still blocking the shutdown
+// default is 5 seconds
+func WithBlockedShutdownWarningInterval(interval time.Duration) InitOption {
+	return func(c *InitConfig) {
+		c.BlockedShutdownWarningInterval = interval
+	}
+}
+
+// WithCallbackTimeout sets how long HandleShutdown waits for running WatchForFileChanges callbacks to finish, before waiting for pending work
+// default is 10 seconds
+func WithCallbackTimeout(timeout time.Duration) InitOption {
+	return func(c *InitConfig) {
+		c.CallbackTimeout = timeout
+	}
+}
+
+// WithSkipCallbacksOnShutdown makes WatchForFileChanges skip callbacks for changes detected after shutdown has begun, instead of running them while the application is torn down
+func WithSkipCallbacksOnShutdown() InitOption {
+	return func(c *InitConfig) {
+		c.SkipCallbacksOnShutdown = true
+	}
+}
+
+// WithReExecGracePeriod sets how long ReExecOnChange waits for the new process to keep running before draining the current one
+// default is 10 seconds
+func WithReExecGracePeriod(gracePeriod time.Duration) InitOption {
+	return func(c *InitConfig) {
+		c.ReExecGracePeriod = gracePeriod
+	}
+}
+
+// WithReExecDebounce sets how long ReExecOnChange waits after the last change before re-executing, so a binary that's still being copied isn't started
+// default is 1 second
+func WithReExecDebounce(debounce time.Duration) InitOption {
+	return func(c *InitConfig) {
+		c.ReExecDebounce = debounce
+	}
+}
+
+// WithClock sets the clock HandleShutdown uses for its timeouts, for example a fake clock in tests
+// default is the system clock
+func WithClock(clock Clock) InitOption {
+	return func(c *InitConfig) {
+		c.Clock = clock
+	}
+}
+
+// WithFunctionsOnShutdown sets functions HandleShutdown executes as soon as the shutdown signal is received, before waiting for pending work
+func WithFunctionsOnShutdown(functionsOnShutdown ...func()) InitOption {
+	return func(c *InitConfig) {
+		c.FunctionsOnShutdown = append(c.FunctionsOnShutdown, functionsOnShutdown...)
+	}
+}
+
+// WithHandler registers an additional handler on the probes or metrics server, like the one returned by NewDrainHandler
+func WithHandler(path string, handler http.Handler) InitOption {
+	return func(c *InitConfig) {
+		if c.Handlers == nil {
+			c.Handlers = map[string]http.Handler{}
+		}
+		c.Handlers[path] = handler
+	}
+}
+
+func newInitConfig(defaultPort int, opts []InitOption) *InitConfig {
+	config := &InitConfig{
+		Port:                           defaultPort,
+		LivenessPath:                   "/liveness",
+		ReadinessPath:                  "/readiness",
+		StartupPath:                    "/startup",
+		Clock:                          NewSystemClock(),
+		MetricsPath:                    "/metrics",
+		Logger:                         &log.Logger,
+		CallbackTimeout:                10 * time.Second,
+		ReExecGracePeriod:              10 * time.Second,
+		ReExecDebounce:                 time.Second,
+		BlockedShutdownWarningInterval: 5 * time.Second,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
@@ -0,0 +1,138 @@
+package foundation
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInitConfig(t *testing.T) {
+
+	t.Run("ReturnsDefaultsIfNoOptionsArePassed", func(t *testing.T) {
+
+		// act
+		config := newInitConfig(5000, nil)
+
+		assert.Equal(t, 5000, config.Port)
+		assert.Equal(t, "/liveness", config.LivenessPath)
+		assert.Equal(t, "/readiness", config.ReadinessPath)
+		assert.Equal(t, "/metrics", config.MetricsPath)
+		assert.NotNil(t, config.Logger)
+	})
+
+	t.Run("OverridesDefaultsWithOptions", func(t *testing.T) {
+
+		// act
+		config := newInitConfig(5000, []InitOption{WithPort(8080), WithReadinessPath("/ready"), WithShutdownTimeout(time.Second)})
+
+		assert.Equal(t, 8080, config.Port)
+		assert.Equal(t, "/ready", config.ReadinessPath)
+		assert.Equal(t, time.Second, config.ShutdownTimeout)
+	})
+}
+
+func TestHandleShutdown(t *testing.T) {
+
+	t.Run("StopsWaitingForPendingWorkAfterShutdownTimeout", func(t *testing.T) {
+
+		defer resetShutdown()
+		gracefulShutdown := make(chan os.Signal, 1)
+		waitGroup := &sync.WaitGroup{}
+		waitGroup.Add(1)
+		defer waitGroup.Done()
+		functionCalled := false
+		gracefulShutdown <- syscall.SIGTERM
+
+		// act
+		HandleShutdown(gracefulShutdown, waitGroup, WithShutdownTimeout(10*time.Millisecond), WithFunctionsOnShutdown(func() { functionCalled = true }))
+
+		assert.True(t, functionCalled)
+	})
+
+	t.Run("LogsTrackedWorkBlockingShutdown", func(t *testing.T) {
+
+		defer resetShutdown()
+		defer resetTrackedWaitGroups()
+		gracefulShutdown := make(chan os.Signal, 1)
+		gracefulShutdown <- syscall.SIGTERM
+		waitGroup := &sync.WaitGroup{}
+		trackedWaitGroup := NewTrackedWaitGroup(waitGroup)
+		trackedWaitGroup.Add("build", 1)
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			trackedWaitGroup.Done("build")
+		}()
+		var buffer bytes.Buffer
+
+		// act
+		HandleShutdown(gracefulShutdown, waitGroup, WithBlockedShutdownWarningInterval(5*time.Millisecond), WithLogger(zerolog.New(&buffer)))
+
+		assert.Contains(t, buffer.String(), "Shutdown is blocked by build (1)")
+	})
+
+	t.Run("WaitsForRunningFileWatchCallbacks", func(t *testing.T) {
+
+		defer resetShutdown()
+		gracefulShutdown := make(chan os.Signal, 1)
+		gracefulShutdown <- syscall.SIGTERM
+		startFileWatchCallback(false)
+		callbackFinished := false
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			callbackFinished = true
+			finishFileWatchCallback()
+		}()
+
+		// act
+		HandleShutdown(gracefulShutdown, &sync.WaitGroup{})
+
+		assert.True(t, callbackFinished)
+	})
+
+	t.Run("StopsWaitingForFileWatchCallbacksAfterCallbackTimeout", func(t *testing.T) {
+
+		defer resetShutdown()
+		gracefulShutdown := make(chan os.Signal, 1)
+		gracefulShutdown <- syscall.SIGTERM
+		startFileWatchCallback(false)
+		defer finishFileWatchCallback()
+		start := time.Now()
+
+		// act
+		HandleShutdown(gracefulShutdown, &sync.WaitGroup{}, WithCallbackTimeout(10*time.Millisecond))
+
+		assert.True(t, time.Since(start) < time.Second)
+	})
+}
+
+func TestStartFileWatchCallback(t *testing.T) {
+
+	t.Run("ReturnsFalseDuringShutdownIfSkipped", func(t *testing.T) {
+
+		defer resetShutdown()
+		beginShutdown()
+
+		// act
+		started := startFileWatchCallback(true)
+
+		assert.False(t, started)
+	})
+
+	t.Run("ReturnsTrueDuringShutdownIfNotSkipped", func(t *testing.T) {
+
+		defer resetShutdown()
+		beginShutdown()
+
+		// act
+		started := startFileWatchCallback(false)
+
+		assert.True(t, started)
+		finishFileWatchCallback()
+	})
+}
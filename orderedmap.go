@@ -0,0 +1,294 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap is a map with string keys that keeps the order in which keys were added, and round-trips json and yaml without reordering keys, for re-emitting user-authored manifests; nested objects are unmarshalled as *OrderedMap, arrays as []interface{}. It isn't safe for concurrent use
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap returns an empty OrderedMap
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{
+		values: map[string]interface{}{},
+	}
+}
+
+// Get returns the value for key and whether it was present
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set sets the value for key; a new key is added at the end, an existing key keeps its position
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes key
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in order
+func (m *OrderedMap) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+// Len returns the number of keys
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// MarshalJSON marshals the map as a json object with the keys in order
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		keyData, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueData, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(keyData)
+		buffer.WriteByte(':')
+		buffer.Write(valueData)
+	}
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON replaces the contents of the map with a json object, keeping the order of its keys; numbers are unmarshalled as json.Number to keep their exact representation
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("Expected json object, got %v", token)
+	}
+
+	parsed, err := decodeJSONObject(decoder)
+	if err != nil {
+		return err
+	}
+	*m = *parsed
+
+	return nil
+}
+
+// decodeJSONObject decodes the object after its opening brace has been read
+func decodeJSONObject(decoder *json.Decoder) (*OrderedMap, error) {
+	m := NewOrderedMap()
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("Expected json object key, got %v", token)
+		}
+		value, err := decodeJSONValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		m.Set(key, value)
+	}
+
+	// closing brace
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		return decodeJSONObject(decoder)
+	case json.Delim('['):
+		values := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		// closing bracket
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	return token, nil
+}
+
+// MarshalYAML marshals the map as a yaml mapping with the keys in order
+func (m *OrderedMap) MarshalYAML() (interface{}, error) {
+	return toYAMLNode(m)
+}
+
+func toYAMLNode(value interface{}) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case *OrderedMap:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range v.keys {
+			valueNode, err := toYAMLNode(v.values[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			itemNode, err := toYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, itemNode)
+		}
+		return node, nil
+	case json.Number:
+		return toYAMLNode(yamlNumber(v))
+	}
+
+	// let the yaml package pick the tag and quoting for anything else
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return document.Content[0], nil
+}
+
+// yamlNumber converts a json number to an int or float for yaml
+func yamlNumber(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+
+	return n.String()
+}
+
+// UnmarshalYAML replaces the contents of the map with a yaml mapping, keeping the order of its keys
+func (m *OrderedMap) UnmarshalYAML(node *yaml.Node) error {
+	value, err := (&yamlNodeDecoder{}).decode(node, false)
+	if err != nil {
+		return err
+	}
+	parsed, ok := value.(*OrderedMap)
+	if !ok {
+		return fmt.Errorf("Expected yaml mapping at line %v", node.Line)
+	}
+	*m = *parsed
+
+	return nil
+}
+
+// orderedYAMLMaxAliasedNodes limits the number of nodes expanded through aliases, since expanding nested aliases grows exponentially, like in the billion laughs attack
+const orderedYAMLMaxAliasedNodes = 100000
+
+// yamlNodeDecoder counts the nodes expanded through aliases while decoding a yaml node, to fail on excessive aliasing like the yaml package does
+type yamlNodeDecoder struct {
+	aliasedNodes int
+}
+
+func (d *yamlNodeDecoder) decode(node *yaml.Node, aliased bool) (interface{}, error) {
+	if aliased {
+		d.aliasedNodes++
+		if d.aliasedNodes > orderedYAMLMaxAliasedNodes {
+			return nil, fmt.Errorf("Excessive aliasing in ordered yaml at line %v", node.Line)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return d.decode(node.Alias, true)
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return d.decode(node.Content[0], aliased)
+	case yaml.MappingNode:
+		m := NewOrderedMap()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			if keyNode.Tag == "!!merge" {
+				return nil, fmt.Errorf("Merge keys aren't supported in ordered yaml at line %v", keyNode.Line)
+			}
+			value, err := d.decode(valueNode, aliased)
+			if err != nil {
+				return nil, err
+			}
+			m.Set(keyNode.Value, value)
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		values := []interface{}{}
+		for _, itemNode := range node.Content {
+			value, err := d.decode(itemNode, aliased)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
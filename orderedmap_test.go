@@ -0,0 +1,128 @@
+package foundation
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestOrderedMap(t *testing.T) {
+
+	t.Run("KeepsInsertionOrder", func(t *testing.T) {
+
+		m := NewOrderedMap()
+		m.Set("stages", nil)
+		m.Set("labels", nil)
+		m.Set("builder", nil)
+		m.Set("labels", "updated")
+		m.Delete("stages")
+
+		// act
+		keys := m.Keys()
+
+		assert.Equal(t, []string{"labels", "builder"}, keys)
+	})
+
+	t.Run("RoundTripsJSONWithoutReorderingKeys", func(t *testing.T) {
+
+		input := `{"version":2,"stages":{"build":{"image":"golang:1.18","commands":["go build"]},"test":{"image":"golang:1.18"}},"labels":{"team":"ci"},"ratio":0.5,"enabled":true,"none":null}`
+		m := NewOrderedMap()
+
+		// act
+		err := json.Unmarshal([]byte(input), m)
+
+		assert.Nil(t, err)
+		output, err := json.Marshal(m)
+		assert.Nil(t, err)
+		assert.Equal(t, input, string(output))
+	})
+
+	t.Run("RoundTripsYAMLWithoutReorderingKeys", func(t *testing.T) {
+
+		input := `version: 2
+stages:
+  build:
+    image: golang:1.18
+    commands:
+      - go build
+  test:
+    image: golang:1.18
+labels:
+  team: ci
+enabled: true
+`
+		m := NewOrderedMap()
+
+		// act
+		err := yaml.Unmarshal([]byte(input), m)
+
+		assert.Nil(t, err)
+		output, err := yaml.Marshal(m)
+		assert.Nil(t, err)
+		assert.Equal(t, `version: 2
+stages:
+    build:
+        image: golang:1.18
+        commands:
+          - go build
+    test:
+        image: golang:1.18
+labels:
+    team: ci
+enabled: true
+`, string(output))
+	})
+
+	t.Run("ConvertsJSONToYAMLKeepingOrder", func(t *testing.T) {
+
+		m := NewOrderedMap()
+		json.Unmarshal([]byte(`{"z":1,"a":"1.0","m":[1.5]}`), m)
+
+		// act
+		output, err := yaml.Marshal(m)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "z: 1\na: \"1.0\"\nm:\n  - 1.5\n", string(output))
+	})
+
+	t.Run("ReturnsErrorForYAMLSequence", func(t *testing.T) {
+
+		m := NewOrderedMap()
+
+		// act
+		err := yaml.Unmarshal([]byte("- a\n- b\n"), m)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ExpandsAliases", func(t *testing.T) {
+
+		m := NewOrderedMap()
+
+		// act
+		err := yaml.Unmarshal([]byte("base: &base\n  image: golang\nbuild: *base\n"), m)
+
+		assert.Nil(t, err)
+		build, _ := m.Get("build")
+		image, _ := build.(*OrderedMap).Get("image")
+		assert.Equal(t, "golang", image)
+	})
+
+	t.Run("ReturnsErrorForExcessiveAliasing", func(t *testing.T) {
+
+		document := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+		for i, name := range []string{"b", "c", "d", "e", "f", "g", "h", "i"} {
+			previous := string(rune('a' + i))
+			document += fmt.Sprintf("%v: &%v [*%v, *%v, *%v, *%v, *%v, *%v, *%v, *%v, *%v, *%v]\n", name, name, previous, previous, previous, previous, previous, previous, previous, previous, previous, previous)
+		}
+		m := NewOrderedMap()
+
+		// act
+		err := yaml.Unmarshal([]byte(document), m)
+
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,403 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rs/zerolog/log"
+)
+
+// OTLPExporter pushes the metrics registered with prometheus to an OpenTelemetry collector over OTLP/HTTP, so the same counters and histograms can be scraped and pushed
+type OTLPExporter interface {
+	// Export pushes the current value of all metrics once
+	Export(ctx context.Context) error
+	// Flush pushes the metrics a last time; it's registered with FlushOnShutdown by WithOTLPExport
+	Flush() error
+	// Close stops pushing metrics periodically
+	Close()
+}
+
+// OTLPOption allows to override the defaults of NewOTLPExporter
+type OTLPOption func(*OTLPConfig)
+
+// OTLPConfig is used to configure the exporter returned by NewOTLPExporter
+type OTLPConfig struct {
+	Interval    time.Duration
+	ServiceName string
+	Headers     map[string]string
+	Gatherer    prometheus.Gatherer
+	HTTPClient  *http.Client
+}
+
+// WithOTLPInterval sets how often metrics are pushed; 0 only pushes when calling Export or Flush
+// default is 30 seconds
+func WithOTLPInterval(interval time.Duration) OTLPOption {
+	return func(c *OTLPConfig) {
+		c.Interval = interval
+	}
+}
+
+// WithOTLPServiceName sets the service.name resource attribute
+// default is envvar OTEL_SERVICE_NAME or else the name of the executable
+func WithOTLPServiceName(serviceName string) OTLPOption {
+	return func(c *OTLPConfig) {
+		c.ServiceName = serviceName
+	}
+}
+
+// WithOTLPHeader adds a header to each push, for example for authentication with the collector
+func WithOTLPHeader(key, value string) OTLPOption {
+	return func(c *OTLPConfig) {
+		if c.Headers == nil {
+			c.Headers = map[string]string{}
+		}
+		c.Headers[key] = value
+	}
+}
+
+// WithOTLPGatherer sets the prometheus registry to read the metrics from
+// default is prometheus.DefaultGatherer, which promauto registers with
+func WithOTLPGatherer(gatherer prometheus.Gatherer) OTLPOption {
+	return func(c *OTLPConfig) {
+		c.Gatherer = gatherer
+	}
+}
+
+// WithOTLPHTTPClient sets the http client to push with
+// default is the client returned by NewHTTPClient
+func WithOTLPHTTPClient(client *http.Client) OTLPOption {
+	return func(c *OTLPConfig) {
+		c.HTTPClient = client
+	}
+}
+
+// WithOTLPExport makes InitMetrics push all metrics to the OTLP/HTTP endpoint of an OpenTelemetry collector, like http://otel-collector:4318, in addition to serving them for prometheus; the metrics are pushed a last time on shutdown
+func WithOTLPExport(endpoint string, opts ...OTLPOption) InitOption {
+	return func(c *InitConfig) {
+		c.OTLPEndpoint = endpoint
+		c.OTLPOptions = opts
+	}
+}
+
+// NewOTLPExporter returns an OTLPExporter pushing to the OTLP/HTTP endpoint of an OpenTelemetry collector, like http://otel-collector:4318; /v1/metrics is appended if the endpoint has no path
+func NewOTLPExporter(endpoint string, opts ...OTLPOption) OTLPExporter {
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = filepath.Base(os.Args[0])
+	}
+
+	config := &OTLPConfig{
+		Interval:    30 * time.Second,
+		ServiceName: serviceName,
+		Gatherer:    prometheus.DefaultGatherer,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = NewHTTPClient("otlp")
+	}
+	if u, err := url.Parse(endpoint); err == nil && (u.Path == "" || u.Path == "/") {
+		endpoint = u.Scheme + "://" + u.Host + "/v1/metrics"
+	}
+
+	e := &otlpExporter{
+		endpoint:  endpoint,
+		config:    config,
+		startTime: time.Now(),
+		stop:      make(chan struct{}),
+	}
+
+	if config.Interval > 0 {
+		go e.exportPeriodically()
+	}
+
+	return e
+}
+
+type otlpExporter struct {
+	endpoint  string
+	config    *OTLPConfig
+	startTime time.Time
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+func (e *otlpExporter) Export(ctx context.Context) error {
+
+	families, err := e.config.Gatherer.Gather()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(toOTLPMetricsRequest(families, e.config.ServiceName, e.startTime, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP endpoint %v responded with status %v", e.endpoint, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (e *otlpExporter) Flush() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return e.Export(ctx)
+}
+
+func (e *otlpExporter) Close() {
+	e.closeOnce.Do(func() {
+		close(e.stop)
+	})
+}
+
+func (e *otlpExporter) exportPeriodically() {
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.Flush(); err != nil {
+				log.Warn().Err(err).Msg("Exporting metrics over OTLP failed")
+			}
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// the types below are the json encoding of the OTLP ExportMetricsServiceRequest protobuf message; 64-bit integers are encoded as strings
+
+type otlpMetricsRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpAttribute struct {
+	Key   string             `json:"key"`
+	Value otlpAttributeValue `json:"value"`
+}
+
+type otlpAttributeValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Sum         *otlpSum       `json:"sum,omitempty"`
+	Gauge       *otlpGauge     `json:"gauge,omitempty"`
+	Histogram   *otlpHistogram `json:"histogram,omitempty"`
+	Summary     *otlpSummary   `json:"summary,omitempty"`
+}
+
+// otlpAggregationTemporalityCumulative matches the cumulative values of prometheus counters and histograms
+const otlpAggregationTemporalityCumulative = 2
+
+type otlpSum struct {
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpNumberDataPoint struct {
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64          `json:"startTimeUnixNano,string,omitempty"`
+	TimeUnixNano      uint64          `json:"timeUnixNano,string"`
+	AsDouble          float64         `json:"asDouble"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64          `json:"startTimeUnixNano,string"`
+	TimeUnixNano      uint64          `json:"timeUnixNano,string"`
+	Count             uint64          `json:"count,string"`
+	Sum               float64         `json:"sum"`
+	BucketCounts      []string        `json:"bucketCounts"`
+	ExplicitBounds    []float64       `json:"explicitBounds"`
+}
+
+type otlpSummary struct {
+	DataPoints []otlpSummaryDataPoint `json:"dataPoints"`
+}
+
+type otlpSummaryDataPoint struct {
+	Attributes        []otlpAttribute     `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64              `json:"startTimeUnixNano,string"`
+	TimeUnixNano      uint64              `json:"timeUnixNano,string"`
+	Count             uint64              `json:"count,string"`
+	Sum               float64             `json:"sum"`
+	QuantileValues    []otlpQuantileValue `json:"quantileValues"`
+}
+
+type otlpQuantileValue struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// toOTLPMetricsRequest converts prometheus metric families to an OTLP request; counters become monotonic sums, gauges and untyped metrics gauges, histograms and summaries their OTLP equivalent
+func toOTLPMetricsRequest(families []*dto.MetricFamily, serviceName string, startTime, now time.Time) otlpMetricsRequest {
+
+	start := uint64(startTime.UnixNano())
+	timestamp := uint64(now.UnixNano())
+
+	metrics := []otlpMetric{}
+	for _, family := range families {
+		metric := otlpMetric{
+			Name:        family.GetName(),
+			Description: family.GetHelp(),
+		}
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			metric.Sum = &otlpSum{AggregationTemporality: otlpAggregationTemporalityCumulative, IsMonotonic: true}
+			for _, m := range family.Metric {
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, otlpNumberDataPoint{Attributes: toOTLPAttributes(m.Label), StartTimeUnixNano: start, TimeUnixNano: timestamp, AsDouble: m.GetCounter().GetValue()})
+			}
+
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			metric.Gauge = &otlpGauge{}
+			for _, m := range family.Metric {
+				value := m.GetGauge().GetValue()
+				if m.Untyped != nil {
+					value = m.GetUntyped().GetValue()
+				}
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, otlpNumberDataPoint{Attributes: toOTLPAttributes(m.Label), TimeUnixNano: timestamp, AsDouble: value})
+			}
+
+		case dto.MetricType_HISTOGRAM:
+			metric.Histogram = &otlpHistogram{AggregationTemporality: otlpAggregationTemporalityCumulative}
+			for _, m := range family.Metric {
+				h := m.GetHistogram()
+				dataPoint := otlpHistogramDataPoint{
+					Attributes:        toOTLPAttributes(m.Label),
+					StartTimeUnixNano: start,
+					TimeUnixNano:      timestamp,
+					Count:             h.GetSampleCount(),
+					Sum:               h.GetSampleSum(),
+					BucketCounts:      []string{},
+					ExplicitBounds:    []float64{},
+				}
+				// prometheus buckets are cumulative, otlp buckets aren't and have an extra bucket for values above the last bound
+				var previous uint64
+				for _, bucket := range h.Bucket {
+					dataPoint.ExplicitBounds = append(dataPoint.ExplicitBounds, bucket.GetUpperBound())
+					dataPoint.BucketCounts = append(dataPoint.BucketCounts, fmt.Sprint(bucket.GetCumulativeCount()-previous))
+					previous = bucket.GetCumulativeCount()
+				}
+				dataPoint.BucketCounts = append(dataPoint.BucketCounts, fmt.Sprint(h.GetSampleCount()-previous))
+				metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, dataPoint)
+			}
+
+		case dto.MetricType_SUMMARY:
+			metric.Summary = &otlpSummary{}
+			for _, m := range family.Metric {
+				s := m.GetSummary()
+				dataPoint := otlpSummaryDataPoint{
+					Attributes:        toOTLPAttributes(m.Label),
+					StartTimeUnixNano: start,
+					TimeUnixNano:      timestamp,
+					Count:             s.GetSampleCount(),
+					Sum:               s.GetSampleSum(),
+					QuantileValues:    []otlpQuantileValue{},
+				}
+				for _, q := range s.Quantile {
+					dataPoint.QuantileValues = append(dataPoint.QuantileValues, otlpQuantileValue{Quantile: q.GetQuantile(), Value: q.GetValue()})
+				}
+				metric.Summary.DataPoints = append(metric.Summary.DataPoints, dataPoint)
+			}
+
+		default:
+			continue
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return otlpMetricsRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{{Key: "service.name", Value: otlpAttributeValue{StringValue: serviceName}}},
+			},
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: "github.com/ziplineeci/ziplinee-foundation"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+func toOTLPAttributes(labels []*dto.LabelPair) []otlpAttribute {
+	attributes := make([]otlpAttribute, 0, len(labels))
+	for _, label := range labels {
+		attributes = append(attributes, otlpAttribute{Key: label.GetName(), Value: otlpAttributeValue{StringValue: label.GetValue()}})
+	}
+
+	return attributes
+}
@@ -0,0 +1,95 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTLPExporter(t *testing.T) {
+
+	t.Run("PushesPrometheusMetricsAsOTLPJson", func(t *testing.T) {
+
+		registry := prometheus.NewRegistry()
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "builds_total", Help: "Total builds."}, []string{"status"})
+		histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "build_duration_seconds", Buckets: []float64{1, 10}})
+		registry.MustRegister(counter, histogram)
+		counter.WithLabelValues("succeeded").Add(3)
+		histogram.Observe(0.5)
+		histogram.Observe(5)
+		histogram.Observe(50)
+
+		var request otlpMetricsRequest
+		var path, authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			authorization = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&request)
+		}))
+		defer server.Close()
+		exporter := NewOTLPExporter(server.URL, WithOTLPGatherer(registry), WithOTLPInterval(0), WithOTLPServiceName("ziplinee-api"), WithOTLPHeader("Authorization", "Bearer abc"))
+		defer exporter.Close()
+
+		// act
+		err := exporter.Export(context.Background())
+
+		if assert.Nil(t, err) {
+			assert.Equal(t, "/v1/metrics", path)
+			assert.Equal(t, "Bearer abc", authorization)
+			assert.Equal(t, "ziplinee-api", request.ResourceMetrics[0].Resource.Attributes[0].Value.StringValue)
+			metrics := request.ResourceMetrics[0].ScopeMetrics[0].Metrics
+			if assert.Equal(t, 2, len(metrics)) {
+				assert.Equal(t, "build_duration_seconds", metrics[0].Name)
+				assert.Equal(t, []string{"1", "1", "1"}, metrics[0].Histogram.DataPoints[0].BucketCounts)
+				assert.Equal(t, []float64{1, 10}, metrics[0].Histogram.DataPoints[0].ExplicitBounds)
+				assert.Equal(t, uint64(3), metrics[0].Histogram.DataPoints[0].Count)
+				assert.Equal(t, "builds_total", metrics[1].Name)
+				assert.True(t, metrics[1].Sum.IsMonotonic)
+				assert.Equal(t, 3.0, metrics[1].Sum.DataPoints[0].AsDouble)
+				assert.Equal(t, "status", metrics[1].Sum.DataPoints[0].Attributes[0].Key)
+			}
+		}
+	})
+
+	t.Run("ReturnsErrorIfCollectorRejectsMetrics", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+		exporter := NewOTLPExporter(server.URL+"/otlp/v1/metrics", WithOTLPGatherer(prometheus.NewRegistry()), WithOTLPInterval(0))
+		defer exporter.Close()
+
+		// act
+		err := exporter.Export(context.Background())
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("PushesPeriodically", func(t *testing.T) {
+
+		pushes := make(chan struct{}, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pushes <- struct{}{}
+		}))
+		defer server.Close()
+		exporter := NewOTLPExporter(server.URL, WithOTLPGatherer(prometheus.NewRegistry()), WithOTLPInterval(10*time.Millisecond))
+		defer exporter.Close()
+
+		// act
+		select {
+		case <-pushes:
+		case <-time.After(time.Second):
+			assert.Fail(t, "metrics weren't pushed")
+		}
+	})
+}
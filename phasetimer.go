@@ -0,0 +1,176 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	phaseDurationSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "foundation_phase_duration_seconds",
+			Help:    "Duration of phases recorded by a PhaseTimer by timer and phase.",
+			Buckets: DefaultDurationBuckets(),
+		},
+		[]string{"timer", "phase"},
+	)
+)
+
+// PhaseTimer records the duration of named phases, like clone, restore-cache, build and push, and reports them in a single log event
+type PhaseTimer interface {
+	// Start ends the running phase, if any, and starts timing phase; a phase started more than once adds up
+	Start(phase string)
+	// Stop ends the running phase, if any
+	Stop()
+	// Durations returns the duration per phase in the order the phases were first started, including the running phase up till now
+	Durations() []PhaseDuration
+	// Finish ends the running phase, logs one event with msg, the duration per phase and the total duration, and records the phase durations in a histogram if enabled with WithPhaseMetrics
+	Finish(msg string)
+}
+
+// PhaseDuration is the duration of a single phase recorded by a PhaseTimer
+type PhaseDuration struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// PhaseTimerOption allows to override the defaults of NewPhaseTimer
+type PhaseTimerOption func(*PhaseTimerConfig)
+
+// PhaseTimerConfig is used to configure the timer returned by NewPhaseTimer
+type PhaseTimerConfig struct {
+	Logger  *zerolog.Logger
+	Metrics bool
+}
+
+// WithPhaseTimerLogger sets the logger Finish logs to, for example one with build fields added
+// default is the global logger
+func WithPhaseTimerLogger(logger zerolog.Logger) PhaseTimerOption {
+	return func(c *PhaseTimerConfig) {
+		c.Logger = &logger
+	}
+}
+
+// WithPhaseMetrics enables recording the phase durations in the foundation_phase_duration_seconds histogram on Finish
+// default is disabled
+func WithPhaseMetrics() PhaseTimerOption {
+	return func(c *PhaseTimerConfig) {
+		c.Metrics = true
+	}
+}
+
+// NewPhaseTimer returns a PhaseTimer without running phase; name identifies the timer in the log event and as metrics label, so it should have low cardinality
+func NewPhaseTimer(name string, opts ...PhaseTimerOption) PhaseTimer {
+
+	config := &PhaseTimerConfig{
+		Logger: &log.Logger,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return &phaseTimer{
+		name:      name,
+		config:    config,
+		startedAt: time.Now(),
+		durations: map[string]time.Duration{},
+	}
+}
+
+type phaseTimer struct {
+	name   string
+	config *PhaseTimerConfig
+
+	mutex          sync.Mutex
+	startedAt      time.Time
+	phases         []string
+	durations      map[string]time.Duration
+	running        string
+	runningSince   time.Time
+	runningStarted bool
+}
+
+func (t *phaseTimer) Start(phase string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	t.stopLocked(now)
+
+	if _, ok := t.durations[phase]; !ok {
+		t.phases = append(t.phases, phase)
+		t.durations[phase] = 0
+	}
+	t.running = phase
+	t.runningSince = now
+	t.runningStarted = true
+}
+
+func (t *phaseTimer) Stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stopLocked(time.Now())
+}
+
+// stopLocked adds the duration of the running phase; must be called with the mutex held
+func (t *phaseTimer) stopLocked(now time.Time) {
+	if !t.runningStarted {
+		return
+	}
+	t.durations[t.running] += now.Sub(t.runningSince)
+	t.runningStarted = false
+}
+
+func (t *phaseTimer) Durations() []PhaseDuration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.durationsLocked(time.Now())
+}
+
+// durationsLocked returns the duration per phase including the running phase; must be called with the mutex held
+func (t *phaseTimer) durationsLocked(now time.Time) []PhaseDuration {
+	durations := make([]PhaseDuration, 0, len(t.phases))
+	for _, phase := range t.phases {
+		duration := t.durations[phase]
+		if t.runningStarted && phase == t.running {
+			duration += now.Sub(t.runningSince)
+		}
+		durations = append(durations, PhaseDuration{Phase: phase, Duration: duration})
+	}
+
+	return durations
+}
+
+func (t *phaseTimer) Finish(msg string) {
+	t.mutex.Lock()
+	now := time.Now()
+	t.stopLocked(now)
+	durations := t.durationsLocked(now)
+	t.mutex.Unlock()
+
+	phases := zerolog.Dict()
+	for _, d := range durations {
+		phases = phases.Dur(d.Phase, d.Duration)
+		if t.config.Metrics {
+			phaseDurationSeconds.WithLabelValues(t.name, d.Phase).Observe(d.Duration.Seconds())
+		}
+	}
+
+	t.config.Logger.Info().
+		Str("timer", t.name).
+		Dict("phases", phases).
+		Dur("total", now.Sub(t.startedAt)).
+		Msg(msg)
+}
@@ -0,0 +1,82 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseTimer(t *testing.T) {
+
+	t.Run("ReturnsDurationsInOrderPhasesWereStarted", func(t *testing.T) {
+
+		timer := NewPhaseTimer("build")
+		timer.Start("clone")
+		time.Sleep(2 * time.Millisecond)
+		timer.Start("build")
+		timer.Start("clone")
+		timer.Stop()
+
+		// act
+		durations := timer.Durations()
+
+		if assert.Equal(t, 2, len(durations)) {
+			assert.Equal(t, "clone", durations[0].Phase)
+			assert.True(t, durations[0].Duration >= 2*time.Millisecond)
+			assert.Equal(t, "build", durations[1].Phase)
+		}
+	})
+
+	t.Run("IncludesRunningPhaseInDurations", func(t *testing.T) {
+
+		timer := NewPhaseTimer("build")
+		timer.Start("push")
+		time.Sleep(time.Millisecond)
+
+		// act
+		durations := timer.Durations()
+
+		if assert.Equal(t, 1, len(durations)) {
+			assert.True(t, durations[0].Duration >= time.Millisecond)
+		}
+	})
+
+	t.Run("LogsSingleEventWithAllPhases", func(t *testing.T) {
+
+		var buffer bytes.Buffer
+		timer := NewPhaseTimer("build", WithPhaseTimerLogger(zerolog.New(&buffer)))
+		timer.Start("clone")
+		timer.Start("restore-cache")
+
+		// act
+		timer.Finish("Build finished")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Equal(t, "build", event["timer"])
+			assert.Equal(t, "Build finished", event["message"])
+			assert.Contains(t, event["phases"], "clone")
+			assert.Contains(t, event["phases"], "restore-cache")
+			assert.Contains(t, event, "total")
+		}
+	})
+
+	t.Run("RecordsPhaseDurationsInHistogramIfEnabled", func(t *testing.T) {
+
+		timer := NewPhaseTimer("test-phase-metrics", WithPhaseTimerLogger(zerolog.Nop()), WithPhaseMetrics())
+		timer.Start("clone")
+
+		// act
+		timer.Finish("Build finished")
+
+		assert.Equal(t, 1, testutil.CollectAndCount(phaseDurationSeconds, "foundation_phase_duration_seconds"))
+	})
+}
@@ -0,0 +1,24 @@
+package foundation
+
+import "reflect"
+
+// Ptr returns a pointer to v, to fill optional fields of kubernetes api objects without temporary variables, like Replicas: foundation.Ptr(int32(3))
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Deref returns the value p points to, or def if p is nil
+func Deref[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+
+	return *p
+}
+
+// IsZero returns whether v is the zero value of its type, like 0, "", false, nil or a struct with only zero fields
+func IsZero[T any](v T) bool {
+	value := reflect.ValueOf(&v).Elem()
+
+	return value.IsZero()
+}
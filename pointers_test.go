@@ -0,0 +1,65 @@
+package foundation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPtr(t *testing.T) {
+
+	t.Run("ReturnsPointerToCopyOfValue", func(t *testing.T) {
+
+		replicas := int32(3)
+
+		// act
+		p := Ptr(replicas)
+
+		replicas = 5
+		assert.Equal(t, int32(3), *p)
+	})
+}
+
+func TestDeref(t *testing.T) {
+
+	t.Run("ReturnsValueIfNotNil", func(t *testing.T) {
+
+		// act
+		value := Deref(Ptr(true), false)
+
+		assert.True(t, value)
+	})
+
+	t.Run("ReturnsDefaultIfNil", func(t *testing.T) {
+
+		// act
+		value := Deref(nil, "default")
+
+		assert.Equal(t, "default", value)
+	})
+}
+
+func TestIsZero(t *testing.T) {
+
+	t.Run("ReturnsTrueForZeroValues", func(t *testing.T) {
+
+		// act
+		zero := IsZero(0)
+
+		assert.True(t, zero)
+		assert.True(t, IsZero(""))
+		assert.True(t, IsZero[*int](nil))
+		assert.True(t, IsZero[error](nil))
+		assert.True(t, IsZero(struct{ Name string }{}))
+	})
+
+	t.Run("ReturnsFalseForNonZeroValues", func(t *testing.T) {
+
+		// act
+		zero := IsZero(1)
+
+		assert.False(t, zero)
+		assert.False(t, IsZero(Ptr(0)))
+		assert.False(t, IsZero(struct{ Name string }{Name: "api"}))
+	})
+}
@@ -2,36 +2,47 @@ package foundation
 
 import (
 	"fmt"
-	"io"
 	"net/http"
-
-	"github.com/rs/zerolog/log"
 )
 
-// InitLivenessAndReadiness initializes the /liveness and /readiness endpoint on port 5000
-func InitLivenessAndReadiness() {
-	InitLivenessAndReadinessWithPort(5000)
-}
+// InitLivenessAndReadiness initializes the /liveness, /readiness and /startup endpoint on port 5000; override with InitOption, for example WithWarmUp to withhold readiness until caches are primed
+func InitLivenessAndReadiness(opts ...InitOption) {
+	config := newInitConfig(5000, opts)
 
-// InitLivenessAndReadinessWithPort initializes the /liveness and /readiness endpoint on specified port
-func InitLivenessAndReadinessWithPort(port int) {
 	// start liveness endpoint
 	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
+		portString := fmt.Sprintf(":%v", config.Port)
+		config.Logger.Debug().
 			Str("port", portString).
-			Msg("Serving /liveness and /readiness endpoints...")
-
-		serverMux := http.NewServeMux()
-		serverMux.HandleFunc("/liveness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm alive!\n")
-		})
-		serverMux.HandleFunc("/readiness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm ready!\n")
-		})
-
-		if err := http.ListenAndServe(portString, serverMux); err != nil {
-			log.Fatal().Err(err).Msg("Starting /liveness and /readiness listener failed")
+			Msgf("Serving %v and %v endpoints...", config.LivenessPath, config.ReadinessPath)
+
+		if err := http.ListenAndServe(portString, newProbesHandler(config)); err != nil {
+			config.Logger.Fatal().Err(err).Msgf("Starting %v and %v listener failed", config.LivenessPath, config.ReadinessPath)
 		}
 	}()
 }
+
+// NewProbesHandler returns the handler serving the /liveness, /readiness and /startup endpoint, to serve on a listener of your own, for example with an ephemeral port in tests; the port set with WithPort is ignored
+func NewProbesHandler(opts ...InitOption) http.Handler {
+	return newProbesHandler(newInitConfig(0, opts))
+}
+
+func newProbesHandler(config *InitConfig) http.Handler {
+	startWarmUp(config)
+
+	serverMux := http.NewServeMux()
+	serverMux.HandleFunc(config.LivenessPath, livenessHandler)
+	serverMux.HandleFunc(config.ReadinessPath, readinessHandler)
+	serverMux.HandleFunc(config.StartupPath, startupHandler)
+
+	for path, handler := range config.Handlers {
+		serverMux.Handle(path, handler)
+	}
+
+	return serverMux
+}
+
+// InitLivenessAndReadinessWithPort initializes the /liveness and /readiness endpoint on specified port
+func InitLivenessAndReadinessWithPort(port int) {
+	InitLivenessAndReadiness(WithPort(port))
+}
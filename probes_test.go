@@ -1,8 +1,8 @@
 package foundation
 
 import (
+	"fmt"
 	"io/ioutil"
-	"net/http"
 	"testing"
 
 	"github.com/sethgrid/pester"
@@ -13,10 +13,12 @@ func TestInitLivenessAndReadiness(t *testing.T) {
 
 	t.Run("Returns200OKForLiveness", func(t *testing.T) {
 
+		port, _ := GetFreePort()
+
 		// act
-		InitLivenessAndReadinessWithPort(5003)
+		InitLivenessAndReadinessWithPort(port)
 
-		resp, err := pester.Get("http://localhost:5003/liveness")
+		resp, err := pester.Get(fmt.Sprintf("http://localhost:%v/liveness", port))
 
 		if assert.Nil(t, err) {
 
@@ -33,10 +35,12 @@ func TestInitLivenessAndReadiness(t *testing.T) {
 
 	t.Run("Returns200OKForReadiness", func(t *testing.T) {
 
+		port, _ := GetFreePort()
+
 		// act
-		InitLivenessAndReadinessWithPort(5004)
+		InitLivenessAndReadinessWithPort(port)
 
-		resp, err := http.Get("http://localhost:5004/readiness")
+		resp, err := pester.Get(fmt.Sprintf("http://localhost:%v/readiness", port))
 
 		if assert.Nil(t, err) {
 
@@ -0,0 +1,100 @@
+package foundation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// ProblemContentType is the content type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response, extended with the request and trace id to correlate it with logs and traces
+type Problem struct {
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	TraceID   string `json:"traceId,omitempty"`
+	// InvalidParams lists the request fields that failed validation
+	InvalidParams []InvalidParam `json:"invalidParams,omitempty"`
+}
+
+// InvalidParam is a request field that failed validation, with the reason why
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Error returns the problem as error message, so a parsed problem can be returned as error
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%v %v: %v", p.Status, p.Title, p.Detail)
+	}
+
+	return fmt.Sprintf("%v %v", p.Status, p.Title)
+}
+
+// WriteProblem writes an application/problem+json response for statusCode with detail as human readable explanation
+func WriteProblem(w http.ResponseWriter, r *http.Request, statusCode int, detail string) {
+	WriteProblemDetails(w, r, Problem{
+		Status: statusCode,
+		Detail: detail,
+	})
+}
+
+// WriteProblemDetails writes the problem as application/problem+json response; title defaults to the status text, instance to the request path, and the request id and trace id are taken from the request if not set
+func WriteProblemDetails(w http.ResponseWriter, r *http.Request, problem Problem) {
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if r != nil {
+		if problem.Instance == "" {
+			problem.Instance = r.URL.Path
+		}
+		if problem.RequestID == "" {
+			problem.RequestID = r.Header.Get("X-Request-Id")
+		}
+		if problem.TraceID == "" {
+			problem.TraceID = getTraceID(r)
+		}
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// ParseProblem reads the problem from an application/problem+json error response; for other error responses it returns a problem with the status and body as detail, and for successful responses nil
+func ParseProblem(resp *http.Response) *Problem {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	problem := Problem{}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != ProblemContentType || json.Unmarshal(body, &problem) != nil {
+		problem = Problem{Detail: string(body)}
+	}
+
+	if problem.Status == 0 {
+		problem.Status = resp.StatusCode
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(resp.StatusCode)
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = resp.Header.Get("X-Request-Id")
+	}
+
+	return &problem
+}
@@ -0,0 +1,78 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteProblem(t *testing.T) {
+
+	t.Run("WritesProblemJsonWithRequestID", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodGet, "/api/pipelines/ziplinee-ci", nil)
+		request.Header.Set("X-Request-Id", "abc123")
+		recorder := httptest.NewRecorder()
+
+		// act
+		WriteProblem(recorder, request, http.StatusNotFound, "Pipeline ziplinee-ci does not exist")
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+		problem := Problem{}
+		json.Unmarshal(recorder.Body.Bytes(), &problem)
+		assert.Equal(t, Problem{
+			Title:     "Not Found",
+			Status:    http.StatusNotFound,
+			Detail:    "Pipeline ziplinee-ci does not exist",
+			Instance:  "/api/pipelines/ziplinee-ci",
+			RequestID: "abc123",
+		}, problem)
+	})
+}
+
+func TestParseProblem(t *testing.T) {
+
+	t.Run("ReturnsProblemFromProblemJsonResponse", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+		WriteProblemDetails(recorder, nil, Problem{Type: "https://ziplinee.io/problems/quota", Status: http.StatusTooManyRequests, Detail: "Build quota exceeded"})
+
+		// act
+		problem := ParseProblem(recorder.Result())
+
+		if assert.NotNil(t, problem) {
+			assert.Equal(t, "https://ziplinee.io/problems/quota", problem.Type)
+			assert.Equal(t, "429 Too Many Requests: Build quota exceeded", problem.Error())
+		}
+	})
+
+	t.Run("ReturnsProblemWithBodyAsDetailForOtherErrorResponses", func(t *testing.T) {
+
+		resp := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewReader([]byte("upstream timed out")))}
+
+		// act
+		problem := ParseProblem(resp)
+
+		if assert.NotNil(t, problem) {
+			assert.Equal(t, http.StatusBadGateway, problem.Status)
+			assert.Equal(t, "Bad Gateway", problem.Title)
+			assert.Equal(t, "upstream timed out", problem.Detail)
+		}
+	})
+
+	t.Run("ReturnsNilForSuccessfulResponses", func(t *testing.T) {
+
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}
+
+		// act
+		problem := ParseProblem(resp)
+
+		assert.Nil(t, problem)
+	})
+}
@@ -1,34 +1,131 @@
 package foundation
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
-// InitReadiness initializes the /readiness endpoint on port 5000
-func InitReadiness() {
-	InitReadinessWithPort(5000)
+var (
+	// ready is 1 when the /readiness endpoint should report the application as ready
+	ready int32 = 1
+	// draining is 1 once draining has been requested; unlike ready it can't be undone, so startup checks or migrations finishing don't mark a drained application as ready again
+	draining int32
+
+	readinessChecks      []namedReadinessCheck
+	readinessChecksMutex sync.Mutex
+)
+
+// ReadinessCheck verifies on every /readiness request whether a dependency needed to serve traffic is available
+type ReadinessCheck func(ctx context.Context) error
+
+type namedReadinessCheck struct {
+	name  string
+	check ReadinessCheck
 }
 
-// InitReadinessWithPort initializes the /readiness endpoint on specified port
-func InitReadinessWithPort(port int) {
-	// start liveness endpoint
+// SetReadiness sets whether the /readiness endpoint reports the application as ready
+func SetReadiness(isReady bool) {
+	if isReady {
+		atomic.StoreInt32(&ready, 1)
+	} else {
+		atomic.StoreInt32(&ready, 0)
+	}
+}
+
+// IsReady returns whether the application is marked as ready
+func IsReady() bool {
+	return atomic.LoadInt32(&ready) == 1
+}
+
+// IsDraining returns whether draining has been requested, see NewDrainHandler; a draining application is reported as not ready
+func IsDraining() bool {
+	return atomic.LoadInt32(&draining) == 1
+}
+
+func startDraining() {
+	atomic.StoreInt32(&draining, 1)
+}
+
+// RegisterReadinessCheck registers a named check run on every /readiness request; if it fails the application is reported as not ready
+func RegisterReadinessCheck(name string, check ReadinessCheck) {
+	readinessChecksMutex.Lock()
+	defer readinessChecksMutex.Unlock()
+
+	readinessChecks = append(readinessChecks, namedReadinessCheck{name: name, check: check})
+}
+
+// InitReadiness initializes the /readiness and /startup endpoint on port 5000; override with InitOption
+func InitReadiness(opts ...InitOption) {
+	config := newInitConfig(5000, opts)
+
+	startWarmUp(config)
+
+	// start readiness endpoint
 	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
+		portString := fmt.Sprintf(":%v", config.Port)
+		config.Logger.Debug().
 			Str("port", portString).
-			Msg("Serving /readiness endpoint...")
+			Msgf("Serving %v endpoint...", config.ReadinessPath)
 
 		serverMux := http.NewServeMux()
-		serverMux.HandleFunc("/readiness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm ready!\n")
-		})
+		serverMux.HandleFunc(config.ReadinessPath, readinessHandler)
+		serverMux.HandleFunc(config.StartupPath, startupHandler)
+
+		for path, handler := range config.Handlers {
+			serverMux.Handle(path, handler)
+		}
 
 		if err := http.ListenAndServe(portString, serverMux); err != nil {
-			log.Fatal().Err(err).Msg("Starting /readiness listener failed")
+			config.Logger.Fatal().Err(err).Msgf("Starting %v listener failed", config.ReadinessPath)
 		}
 	}()
 }
+
+// InitReadinessWithPort initializes the /readiness endpoint on specified port
+func InitReadinessWithPort(port int) {
+	InitReadiness(WithPort(port))
+}
+
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if !IsReady() || IsDraining() || !isWarmedUp() || !runReadinessChecks(r.Context()) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "I'm not ready!\n")
+		return
+	}
+
+	io.WriteString(w, "I'm ready!\n")
+}
+
+func runReadinessChecks(ctx context.Context) bool {
+	readinessChecksMutex.Lock()
+	checks := readinessChecks
+	readinessChecksMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	for _, c := range checks {
+		if err := c.check(ctx); err != nil {
+			log.Warn().Err(err).Msgf("Readiness check %v failed", c.name)
+			return false
+		}
+	}
+
+	return true
+}
+
+// resetReadinessChecks clears all registered readiness checks and undoes draining; for usage in tests
+func resetReadinessChecks() {
+	readinessChecksMutex.Lock()
+	defer readinessChecksMutex.Unlock()
+
+	readinessChecks = nil
+	atomic.StoreInt32(&draining, 0)
+}
@@ -1,7 +1,10 @@
 package foundation
 
 import (
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/sethgrid/pester"
@@ -12,10 +15,12 @@ func TestInitReadiness(t *testing.T) {
 
 	t.Run("Returns200OK", func(t *testing.T) {
 
+		port, _ := GetFreePort()
+
 		// act
-		InitReadinessWithPort(5002)
+		InitReadinessWithPort(port)
 
-		resp, err := pester.Get("http://localhost:5002/readiness")
+		resp, err := pester.Get(fmt.Sprintf("http://localhost:%v/readiness", port))
 
 		if assert.Nil(t, err) {
 
@@ -30,3 +35,20 @@ func TestInitReadiness(t *testing.T) {
 		}
 	})
 }
+
+func TestReadinessHandler(t *testing.T) {
+
+	t.Run("Returns503ServiceUnavailableIfNotReady", func(t *testing.T) {
+
+		SetReadiness(false)
+		defer SetReadiness(true)
+
+		recorder := httptest.NewRecorder()
+
+		// act
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "I'm not ready!\n", recorder.Body.String())
+	})
+}
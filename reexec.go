@@ -0,0 +1,212 @@
+package foundation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrNoInheritedListener is returned by InheritedListener if the parent process didn't hand over a listener for the address
+var ErrNoInheritedListener = errors.New("No listener has been inherited for this address")
+
+// inheritedListenersEnvVar holds the comma-separated addresses of the listeners handed over to a re-executed process, in the order of their file descriptors
+const inheritedListenersEnvVar = "ESTAFETTE_INHERITED_LISTENERS"
+
+// inheritedListenersFirstFD is the file descriptor of the first handed over listener; 0, 1 and 2 are stdin, stdout and stderr
+var inheritedListenersFirstFD = 3
+
+var (
+	registeredListeners      []net.Listener
+	registeredListenersMutex sync.Mutex
+
+	inheritedListenersMutex sync.Mutex
+	inheritedListenersTaken = map[string]bool{}
+)
+
+// RegisterListener registers a listener to be handed over to the new process started by ReExecOnChange, so it keeps accepting connections without a gap; the listener needs to be a *net.TCPListener or *net.UnixListener
+func RegisterListener(l net.Listener) {
+	registeredListenersMutex.Lock()
+	defer registeredListenersMutex.Unlock()
+
+	registeredListeners = append(registeredListeners, l)
+}
+
+// InheritedListener returns the listener for addr handed over by the parent process through ReExecOnChange, or ErrNoInheritedListener; each listener can only be taken once
+func InheritedListener(addr string) (net.Listener, error) {
+	inheritedListenersMutex.Lock()
+	defer inheritedListenersMutex.Unlock()
+
+	addresses := os.Getenv(inheritedListenersEnvVar)
+	if addresses == "" || inheritedListenersTaken[addr] {
+		return nil, ErrNoInheritedListener
+	}
+
+	for i, address := range strings.Split(addresses, ",") {
+		if !sameListenAddress(address, addr) {
+			continue
+		}
+
+		file := os.NewFile(uintptr(inheritedListenersFirstFD+i), address)
+		l, err := net.FileListener(file)
+		// FileListener dups the file descriptor, so the original can be closed
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Inheriting listener for %v failed: %w", addr, err)
+		}
+		inheritedListenersTaken[addr] = true
+
+		return l, nil
+	}
+
+	return nil, ErrNoInheritedListener
+}
+
+// sameListenAddress returns true if the addresses have the same port and the same host, treating an empty or unspecified host like :5000 or [::]:5000 as the same
+func sameListenAddress(a, b string) bool {
+	hostA, portA, errA := net.SplitHostPort(a)
+	hostB, portB, errB := net.SplitHostPort(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	isUnspecified := func(host string) bool {
+		ip := net.ParseIP(host)
+		return host == "" || (ip != nil && ip.IsUnspecified())
+	}
+
+	return portA == portB && (hostA == hostB || (isUnspecified(hostA) && isUnspecified(hostB)))
+}
+
+// reExecTrigger re-executes once changes have stopped for the debounce period, and ignores changes once a re-exec has succeeded, so several write events for a single copy don't start several new processes
+type reExecTrigger struct {
+	debounce time.Duration
+	logger   *zerolog.Logger
+	reExec   func() error
+	drain    func()
+
+	mutex       sync.Mutex
+	timer       *time.Timer
+	changedFile string
+	reExecuted  bool
+}
+
+func (t *reExecTrigger) changed(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.reExecuted {
+		t.logger.Debug().Msgf("Ignoring change to %v, process has already been re-executed", name)
+		return
+	}
+
+	t.changedFile = name
+	if t.timer == nil {
+		t.timer = time.AfterFunc(t.debounce, t.fire)
+		return
+	}
+	t.timer.Reset(t.debounce)
+}
+
+func (t *reExecTrigger) fire() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.reExecuted {
+		return
+	}
+
+	t.logger.Info().Msgf("File %v changed, re-executing process...", t.changedFile)
+	if err := t.reExec(); err != nil {
+		t.logger.Error().Err(err).Msg("Re-executing process failed, keeping current process running")
+		return
+	}
+	t.reExecuted = true
+
+	t.logger.Info().Msg("New process has started, draining current process...")
+	t.drain()
+}
+
+// reExec starts the new process and waits for the grace period to make sure it doesn't exit right away
+func reExec(gracePeriod time.Duration) error {
+	cmd, err := newReExecCommand()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, f := range cmd.ExtraFiles {
+			f.Close()
+		}
+	}()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return fmt.Errorf("New process exited during startup: %v", err)
+	case <-time.After(gracePeriod):
+		return nil
+	}
+}
+
+// newReExecCommand returns the command to start a copy of the current process with the registered listeners as extra files
+func newReExecCommand() (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	registeredListenersMutex.Lock()
+	defer registeredListenersMutex.Unlock()
+
+	files := []*os.File{}
+	addresses := []string{}
+	for _, l := range registeredListeners {
+		filer, ok := l.(interface{ File() (*os.File, error) })
+		if !ok {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, fmt.Errorf("Listener for %v of type %T can't be handed over", l.Addr(), l)
+		}
+		file, err := filer.File()
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, err
+		}
+		files = append(files, file)
+		addresses = append(addresses, l.Addr().String())
+	}
+
+	env := []string{}
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, inheritedListenersEnvVar+"=") {
+			env = append(env, e)
+		}
+	}
+	env = append(env, inheritedListenersEnvVar+"="+strings.Join(addresses, ","))
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = env
+	cmd.ExtraFiles = files
+
+	return cmd, nil
+}
@@ -0,0 +1,88 @@
+package foundation
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReExecCommand(t *testing.T) {
+
+	t.Run("HandsOverRegisteredListenersAsExtraFiles", func(t *testing.T) {
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer l.Close()
+		defer func() { registeredListeners = nil }()
+		RegisterListener(l)
+
+		// act
+		cmd, err := newReExecCommand()
+
+		if assert.Nil(t, err) {
+			defer cmd.ExtraFiles[0].Close()
+			assert.Equal(t, 1, len(cmd.ExtraFiles))
+			assert.Equal(t, inheritedListenersEnvVar+"="+l.Addr().String(), cmd.Env[len(cmd.Env)-1])
+			assert.Equal(t, os.Args[1:], cmd.Args[1:])
+		}
+	})
+}
+
+func TestInheritedListener(t *testing.T) {
+
+	t.Run("ReturnsListenerForFileDescriptorOfAddress", func(t *testing.T) {
+
+		l, _ := net.Listen("tcp", "127.0.0.1:0")
+		defer l.Close()
+		defer func(fd int) { inheritedListenersFirstFD = fd }(inheritedListenersFirstFD)
+		inheritedListenersFirstFD = dupListenerFD(l)
+		t.Setenv(inheritedListenersEnvVar, l.Addr().String())
+		defer delete(inheritedListenersTaken, l.Addr().String())
+
+		// act
+		inherited, err := InheritedListener(l.Addr().String())
+
+		if assert.Nil(t, err) {
+			defer inherited.Close()
+			assert.Equal(t, l.Addr().String(), inherited.Addr().String())
+			_, err = InheritedListener(l.Addr().String())
+			assert.Equal(t, ErrNoInheritedListener, err)
+		}
+	})
+
+	t.Run("ReturnsErrNoInheritedListenerIfNoneWasHandedOver", func(t *testing.T) {
+
+		t.Setenv(inheritedListenersEnvVar, "")
+
+		// act
+		_, err := InheritedListener(":5000")
+
+		assert.Equal(t, ErrNoInheritedListener, err)
+	})
+}
+
+// dupListenerFD returns a duplicate of the listener's file descriptor, owned by whoever takes it over, like a descriptor passed to a new process
+func dupListenerFD(l net.Listener) int {
+	file, _ := l.(*net.TCPListener).File()
+	defer file.Close()
+
+	fd, _ := syscall.Dup(int(file.Fd()))
+
+	return fd
+}
+
+func TestSameListenAddress(t *testing.T) {
+
+	t.Run("TreatsUnspecifiedHostsAsSame", func(t *testing.T) {
+
+		assert.True(t, sameListenAddress("[::]:5000", ":5000"))
+		assert.True(t, sameListenAddress("0.0.0.0:5000", ":5000"))
+		assert.False(t, sameListenAddress("127.0.0.1:5000", ":5000"))
+		assert.False(t, sameListenAddress(":5001", ":5000"))
+	})
+}
@@ -0,0 +1,117 @@
+package foundation
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeListener struct {
+	net.Listener
+	file *os.File
+}
+
+func (l *fakeListener) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5000}
+}
+
+func (l *fakeListener) File() (*os.File, error) {
+	return l.file, nil
+}
+
+type unsupportedListener struct {
+	net.Listener
+}
+
+func (l *unsupportedListener) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5001}
+}
+
+func TestNewReExecCommandClosesFiles(t *testing.T) {
+
+	t.Run("ClosesCollectedFilesIfListenerCanNotBeHandedOver", func(t *testing.T) {
+
+		_, writer, err := os.Pipe()
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer func() { registeredListeners = nil }()
+		RegisterListener(&fakeListener{file: writer})
+		RegisterListener(&unsupportedListener{})
+
+		// act
+		_, err = newReExecCommand()
+
+		assert.NotNil(t, err)
+		_, err = writer.Write([]byte("x"))
+		assert.True(t, errors.Is(err, os.ErrClosed))
+	})
+}
+
+func TestReExecTrigger(t *testing.T) {
+
+	newTrigger := func(reExec func() error) (*reExecTrigger, *int32) {
+		var drains int32
+		return &reExecTrigger{
+			debounce: 20 * time.Millisecond,
+			logger:   &log.Logger,
+			reExec:   reExec,
+			drain:    func() { atomic.AddInt32(&drains, 1) },
+		}, &drains
+	}
+
+	t.Run("ReExecutesOnceAfterChangesStop", func(t *testing.T) {
+
+		var reExecs int32
+		trigger, drains := newTrigger(func() error { atomic.AddInt32(&reExecs, 1); return nil })
+
+		// act
+		trigger.changed("/usr/bin/agent")
+		trigger.changed("/usr/bin/agent")
+		trigger.changed("/usr/bin/agent")
+
+		assert.Equal(t, int32(0), atomic.LoadInt32(&reExecs))
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(drains) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&reExecs))
+	})
+
+	t.Run("IgnoresChangesAfterSuccessfulReExec", func(t *testing.T) {
+
+		var reExecs int32
+		trigger, drains := newTrigger(func() error { atomic.AddInt32(&reExecs, 1); return nil })
+		trigger.changed("/usr/bin/agent")
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(drains) == 1 }, time.Second, time.Millisecond)
+
+		// act
+		trigger.changed("/usr/bin/agent")
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&reExecs))
+		assert.Equal(t, int32(1), atomic.LoadInt32(drains))
+	})
+
+	t.Run("RetriesOnNextChangeAfterFailedReExec", func(t *testing.T) {
+
+		var reExecs int32
+		trigger, drains := newTrigger(func() error {
+			if atomic.AddInt32(&reExecs, 1) == 1 {
+				return errors.New("new process exited during startup")
+			}
+			return nil
+		})
+		trigger.changed("/usr/bin/agent")
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&reExecs) == 1 }, time.Second, time.Millisecond)
+
+		// act
+		trigger.changed("/usr/bin/agent")
+
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(drains) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&reExecs))
+	})
+}
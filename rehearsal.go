@@ -0,0 +1,102 @@
+package foundation
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ShutdownHookRehearsal contains the outcome of a single shutdown hook during a shutdown rehearsal
+type ShutdownHookRehearsal struct {
+	Name string `json:"name"`
+	// DryRun is false for hooks registered without a dry run function, which are skipped
+	DryRun   bool          `json:"dryRun"`
+	Passed   bool          `json:"passed"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// LastDuration is how long the hook took the previous time it ran, for real or as a dry run
+	LastDuration time.Duration `json:"lastDuration,omitempty"`
+}
+
+// ShutdownRehearsal contains what a graceful shutdown would do right now: the pending work it would wait for, the shutdown hooks in the order they would run and the number of registered flushers
+type ShutdownRehearsal struct {
+	Passed   bool                    `json:"passed"`
+	Pending  map[string]int          `json:"pending"`
+	Hooks    []ShutdownHookRehearsal `json:"hooks"`
+	Flushers int                     `json:"flushers"`
+}
+
+// RehearseShutdown simulates the graceful shutdown without exiting or changing readiness: it reports the pending work tracked with TrackedWaitGroup that would be waited for and runs the dry run functions of the hooks registered with RegisterShutdownHookWithDryRun in the order the hooks would run
+func RehearseShutdown() ShutdownRehearsal {
+	shutdownHooksMutex.Lock()
+	hooks := make([]namedShutdownHook, len(shutdownHooks))
+	copy(hooks, shutdownHooks)
+	shutdownHooksMutex.Unlock()
+
+	flushersMutex.Lock()
+	flusherCount := len(flushers)
+	flushersMutex.Unlock()
+
+	rehearsal := ShutdownRehearsal{
+		Passed:   true,
+		Pending:  pendingWork(),
+		Hooks:    make([]ShutdownHookRehearsal, 0, len(hooks)),
+		Flushers: flusherCount,
+	}
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		shutdownHooksMutex.Lock()
+		result := ShutdownHookRehearsal{
+			Name:         hooks[i].name,
+			DryRun:       hooks[i].dryRun != nil,
+			Passed:       true,
+			LastDuration: shutdownHookDurations[hooks[i].name],
+		}
+		shutdownHooksMutex.Unlock()
+
+		if result.DryRun {
+			start := time.Now()
+			err := hooks[i].dryRun()
+			result.Duration = time.Since(start)
+			recordShutdownHookDuration(hooks[i].name, result.Duration)
+			if err != nil {
+				result.Passed = false
+				result.Error = err.Error()
+				rehearsal.Passed = false
+			}
+		}
+
+		rehearsal.Hooks = append(rehearsal.Hooks, result)
+	}
+
+	if rehearsal.Passed {
+		log.Info().
+			Interface("rehearsal", rehearsal).
+			Msgf("Shutdown rehearsal passed for %v hooks", len(rehearsal.Hooks))
+	} else {
+		log.Warn().
+			Interface("rehearsal", rehearsal).
+			Msgf("Shutdown rehearsal failed for one or more of %v hooks", len(rehearsal.Hooks))
+	}
+
+	return rehearsal
+}
+
+// NewShutdownRehearsalHandler returns an http.Handler that on POST runs RehearseShutdown and returns the rehearsal as json, so teams can rehearse the shutdown in staging without killing pods. Requests have to be authenticated with the auth options, see NewAuthMiddleware. Register it on the probes port with WithHandler("/debug/shutdown", handler)
+func NewShutdownRehearsalHandler(opts ...AuthOption) http.Handler {
+	return NewAuthMiddleware(opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		identity, _ := GetAuthIdentity(r.Context())
+		log.Info().Msgf("Shutdown rehearsal requested by %v", identity.Name)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(RehearseShutdown())
+	}))
+}
@@ -0,0 +1,129 @@
+package foundation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRehearseShutdown(t *testing.T) {
+
+	t.Run("RunsDryRunsInReverseOrderOfRegistration", func(t *testing.T) {
+
+		defer ResetLifecycle()
+		calls := []string{}
+		RegisterShutdownHookWithDryRun("database", func() error { calls = append(calls, "database"); return nil }, func() error { calls = append(calls, "database-dry-run"); return nil })
+		RegisterShutdownHookWithDryRun("pubsub", func() error { calls = append(calls, "pubsub"); return nil }, func() error { calls = append(calls, "pubsub-dry-run"); return nil })
+
+		// act
+		rehearsal := RehearseShutdown()
+
+		assert.True(t, rehearsal.Passed)
+		assert.Equal(t, []string{"pubsub-dry-run", "database-dry-run"}, calls)
+		if assert.Equal(t, 2, len(rehearsal.Hooks)) {
+			assert.Equal(t, "pubsub", rehearsal.Hooks[0].Name)
+			assert.Equal(t, "database", rehearsal.Hooks[1].Name)
+		}
+	})
+
+	t.Run("SkipsHooksWithoutDryRun", func(t *testing.T) {
+
+		defer ResetLifecycle()
+		called := false
+		RegisterShutdownHook("tracer", func() error { called = true; return nil })
+
+		// act
+		rehearsal := RehearseShutdown()
+
+		assert.False(t, called)
+		assert.True(t, rehearsal.Passed)
+		if assert.Equal(t, 1, len(rehearsal.Hooks)) {
+			assert.False(t, rehearsal.Hooks[0].DryRun)
+		}
+	})
+
+	t.Run("ReturnsFailedDryRuns", func(t *testing.T) {
+
+		defer ResetLifecycle()
+		RegisterShutdownHookWithDryRun("database", func() error { return nil }, func() error { return errors.New("Connection refused") })
+
+		// act
+		rehearsal := RehearseShutdown()
+
+		assert.False(t, rehearsal.Passed)
+		if assert.Equal(t, 1, len(rehearsal.Hooks)) {
+			assert.False(t, rehearsal.Hooks[0].Passed)
+			assert.Equal(t, "Connection refused", rehearsal.Hooks[0].Error)
+		}
+	})
+
+	t.Run("ReturnsDurationOfPreviousRun", func(t *testing.T) {
+
+		defer ResetLifecycle()
+		RegisterShutdownHookWithDryRun("database", func() error { return nil }, func() error { time.Sleep(10 * time.Millisecond); return nil })
+		first := RehearseShutdown()
+
+		// act
+		second := RehearseShutdown()
+
+		assert.Equal(t, time.Duration(0), first.Hooks[0].LastDuration)
+		assert.Equal(t, first.Hooks[0].Duration, second.Hooks[0].LastDuration)
+	})
+
+	t.Run("ReturnsPendingWorkAndFlushers", func(t *testing.T) {
+
+		defer ResetLifecycle()
+		trackedWaitGroup := NewTrackedWaitGroup(&sync.WaitGroup{})
+		trackedWaitGroup.Add("build", 1)
+		FlushOnShutdown(&fakeSyncer{})
+
+		// act
+		rehearsal := RehearseShutdown()
+
+		assert.Equal(t, map[string]int{"build": 1}, rehearsal.Pending)
+		assert.Equal(t, 1, rehearsal.Flushers)
+		assert.True(t, IsReady())
+	})
+}
+
+func TestNewShutdownRehearsalHandler(t *testing.T) {
+
+	t.Run("ReturnsRehearsalOnPost", func(t *testing.T) {
+
+		defer ResetLifecycle()
+		RegisterShutdownHook("tracer", func() error { return nil })
+		handler := NewShutdownRehearsalHandler(WithAPIKey("operator", "secret"))
+		request := httptest.NewRequest(http.MethodPost, "/debug/shutdown", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var rehearsal ShutdownRehearsal
+		if assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &rehearsal)) {
+			assert.True(t, rehearsal.Passed)
+			assert.Equal(t, 1, len(rehearsal.Hooks))
+		}
+	})
+
+	t.Run("RejectsGetRequests", func(t *testing.T) {
+
+		handler := NewShutdownRehearsalHandler(WithAPIKey("operator", "secret"))
+		request := httptest.NewRequest(http.MethodGet, "/debug/shutdown", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
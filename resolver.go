@@ -0,0 +1,205 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoAddresses is returned when a service name doesn't resolve to any address
+var ErrNoAddresses = errors.New("Service name did not resolve to any address")
+
+const (
+	// resolverFailureBackoff is how long to wait before looking up again after a failed lookup, so a dns outage doesn't cause a lookup per call
+	resolverFailureBackoff = 5 * time.Second
+	// resolverRefreshTimeout limits background refreshes, which aren't bound to the context of a call
+	resolverRefreshTimeout = 10 * time.Second
+)
+
+// Resolver discovers the addresses of a service through dns
+type Resolver interface {
+	// Pick returns the next healthy address in round-robin order, falling back to unhealthy addresses if none are healthy
+	Pick(ctx context.Context) (string, error)
+	// Addresses returns all resolved addresses as host:port
+	Addresses(ctx context.Context) ([]string, error)
+	// MarkUnhealthy skips the address in Pick for the unhealthy cooldown
+	MarkUnhealthy(address string)
+}
+
+type resolver struct {
+	mutex             sync.Mutex
+	service           string
+	ttl               time.Duration
+	unhealthyCooldown time.Duration
+
+	addresses      []string
+	expiresAt      time.Time
+	refreshing     bool
+	lastErr        error
+	next           int
+	unhealthyUntil map[string]time.Time
+
+	lookupSRV  func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewResolver returns a Resolver for service; a name like _grpc._tcp.api.ziplinee.internal is looked up as SRV record, a name like api.ziplinee.internal:5000 as A/AAAA record. Results are cached for ttl with +-25% jitter so instances don't refresh in lockstep; expired results are refreshed in the background while the previous addresses keep being used, and kept if the refresh fails
+func NewResolver(service string, ttl, unhealthyCooldown time.Duration) Resolver {
+	return &resolver{
+		service:           service,
+		ttl:               ttl,
+		unhealthyCooldown: unhealthyCooldown,
+		unhealthyUntil:    map[string]time.Time{},
+		lookupSRV:         net.DefaultResolver.LookupSRV,
+		lookupHost:        net.DefaultResolver.LookupHost,
+	}
+}
+
+func (r *resolver) Pick(ctx context.Context) (string, error) {
+	if err := r.refresh(ctx); err != nil {
+		return "", err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	for i := 0; i < len(r.addresses); i++ {
+		address := r.addresses[(r.next+i)%len(r.addresses)]
+		if now.Before(r.unhealthyUntil[address]) {
+			continue
+		}
+		r.next = (r.next + i + 1) % len(r.addresses)
+		return address, nil
+	}
+
+	// all addresses are unhealthy, any of them is better than none
+	address := r.addresses[r.next%len(r.addresses)]
+	r.next = (r.next + 1) % len(r.addresses)
+
+	return address, nil
+}
+
+func (r *resolver) Addresses(ctx context.Context) ([]string, error) {
+	if err := r.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]string{}, r.addresses...), nil
+}
+
+func (r *resolver) MarkUnhealthy(address string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.unhealthyUntil[address] = time.Now().Add(r.unhealthyCooldown)
+}
+
+// refresh makes sure there are addresses; the first lookup happens in the foreground, once there are addresses expired ones are refreshed in the background. Lookups are done without holding the mutex
+func (r *resolver) refresh(ctx context.Context) error {
+	r.mutex.Lock()
+	expired := !time.Now().Before(r.expiresAt)
+	if len(r.addresses) > 0 {
+		if expired && !r.refreshing {
+			r.refreshing = true
+			go r.refreshInBackground()
+		}
+		r.mutex.Unlock()
+		return nil
+	}
+	if !expired {
+		// the previous lookup failed recently
+		err := r.lastErr
+		r.mutex.Unlock()
+		return err
+	}
+	r.mutex.Unlock()
+
+	addresses, err := r.lookup(ctx)
+	if err != nil && ctx.Err() != nil {
+		// the caller gave up, which says nothing about dns
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.update(addresses, err)
+}
+
+func (r *resolver) refreshInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), resolverRefreshTimeout)
+	defer cancel()
+
+	addresses, err := r.lookup(ctx)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.refreshing = false
+	r.update(addresses, err)
+}
+
+// update stores the result of a lookup; after a failure the previous addresses are kept and the next lookup is delayed by the failure backoff; must be called with the mutex held
+func (r *resolver) update(addresses []string, err error) error {
+	if err == nil && len(addresses) == 0 {
+		err = ErrNoAddresses
+	}
+	if err != nil {
+		r.lastErr = err
+		r.expiresAt = time.Now().Add(time.Duration(ApplyJitter(int(resolverFailureBackoff/time.Millisecond))) * time.Millisecond)
+		if len(r.addresses) > 0 {
+			log.Warn().Err(err).Msgf("Resolving %v failed, using previously resolved addresses", r.service)
+			return nil
+		}
+		return err
+	}
+
+	r.addresses = addresses
+	r.lastErr = nil
+	r.expiresAt = time.Now().Add(time.Duration(ApplyJitter(int(r.ttl/time.Millisecond))) * time.Millisecond)
+
+	return nil
+}
+
+func (r *resolver) lookup(ctx context.Context) ([]string, error) {
+	if strings.HasPrefix(r.service, "_") {
+		_, records, err := r.lookupSRV(ctx, "", "", r.service)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses := make([]string, 0, len(records))
+		for _, record := range records {
+			addresses = append(addresses, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+		}
+		return addresses, nil
+	}
+
+	host, port, err := net.SplitHostPort(r.service)
+	if err != nil {
+		return nil, fmt.Errorf("Service %v is neither an SRV name nor host:port: %w", r.service, err)
+	}
+
+	ips, err := r.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, net.JoinHostPort(ip, port))
+	}
+
+	return addresses, nil
+}
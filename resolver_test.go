@@ -0,0 +1,162 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestResolver(service string, ttl time.Duration, lookupHost func(ctx context.Context, host string) ([]string, error)) *resolver {
+	r := NewResolver(service, ttl, time.Minute).(*resolver)
+	r.lookupHost = lookupHost
+	r.lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{{Target: "api-0.ziplinee.internal.", Port: 5000}, {Target: "api-1.ziplinee.internal.", Port: 5001}}, nil
+	}
+	return r
+}
+
+func TestResolver(t *testing.T) {
+
+	t.Run("PicksAddressesRoundRobin", func(t *testing.T) {
+
+		r := newTestResolver("api.ziplinee.internal:5000", time.Minute, func(ctx context.Context, host string) ([]string, error) {
+			return []string{"10.0.0.1", "10.0.0.2"}, nil
+		})
+
+		// act
+		first, _ := r.Pick(context.Background())
+		second, _ := r.Pick(context.Background())
+		third, _ := r.Pick(context.Background())
+
+		assert.Equal(t, "10.0.0.1:5000", first)
+		assert.Equal(t, "10.0.0.2:5000", second)
+		assert.Equal(t, "10.0.0.1:5000", third)
+	})
+
+	t.Run("ResolvesSRVRecords", func(t *testing.T) {
+
+		r := newTestResolver("_http._tcp.api.ziplinee.internal", time.Minute, nil)
+
+		// act
+		addresses, err := r.Addresses(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"api-0.ziplinee.internal:5000", "api-1.ziplinee.internal:5001"}, addresses)
+	})
+
+	t.Run("SkipsUnhealthyAddresses", func(t *testing.T) {
+
+		r := newTestResolver("api.ziplinee.internal:5000", time.Minute, func(ctx context.Context, host string) ([]string, error) {
+			return []string{"10.0.0.1", "10.0.0.2"}, nil
+		})
+		r.MarkUnhealthy("10.0.0.1:5000")
+
+		// act
+		first, _ := r.Pick(context.Background())
+		second, _ := r.Pick(context.Background())
+
+		assert.Equal(t, "10.0.0.2:5000", first)
+		assert.Equal(t, "10.0.0.2:5000", second)
+	})
+
+	t.Run("CachesAddressesUntilTTLExpires", func(t *testing.T) {
+
+		lookups := 0
+		r := newTestResolver("api.ziplinee.internal:5000", time.Minute, func(ctx context.Context, host string) ([]string, error) {
+			lookups++
+			return []string{"10.0.0.1"}, nil
+		})
+
+		// act
+		r.Pick(context.Background())
+		r.Pick(context.Background())
+
+		assert.Equal(t, 1, lookups)
+	})
+
+	t.Run("KeepsPreviousAddressesIfRefreshFails", func(t *testing.T) {
+
+		var lookups int32
+		r := newTestResolver("api.ziplinee.internal:5000", 0, func(ctx context.Context, host string) ([]string, error) {
+			if atomic.AddInt32(&lookups, 1) > 1 {
+				return nil, errors.New("no such host")
+			}
+			return []string{"10.0.0.1"}, nil
+		})
+		r.Pick(context.Background())
+		r.Pick(context.Background())
+		waitForResolverRefresh(t, r)
+
+		// act
+		address, err := r.Pick(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0.0.1:5000", address)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+	})
+
+	t.Run("RefreshesExpiredAddressesInTheBackground", func(t *testing.T) {
+
+		var lookups int32
+		unblock := make(chan struct{})
+		r := newTestResolver("api.ziplinee.internal:5000", 0, func(ctx context.Context, host string) ([]string, error) {
+			if atomic.AddInt32(&lookups, 1) > 1 {
+				<-unblock
+				return []string{"10.0.0.2"}, nil
+			}
+			return []string{"10.0.0.1"}, nil
+		})
+		r.Pick(context.Background())
+
+		// act
+		address, err := r.Pick(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0.0.1:5000", address)
+		close(unblock)
+		waitForResolverRefresh(t, r)
+		addresses, _ := r.Addresses(context.Background())
+		assert.Equal(t, []string{"10.0.0.2:5000"}, addresses)
+	})
+
+	t.Run("BacksOffAfterFailedLookup", func(t *testing.T) {
+
+		var lookups int32
+		r := newTestResolver("api.ziplinee.internal:5000", time.Minute, func(ctx context.Context, host string) ([]string, error) {
+			atomic.AddInt32(&lookups, 1)
+			return nil, errors.New("no such host")
+		})
+		r.Pick(context.Background())
+
+		// act
+		_, err := r.Pick(context.Background())
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+	})
+
+	t.Run("ReturnsErrorIfNothingResolves", func(t *testing.T) {
+
+		r := newTestResolver("api.ziplinee.internal:5000", time.Minute, func(ctx context.Context, host string) ([]string, error) {
+			return []string{}, nil
+		})
+
+		// act
+		_, err := r.Pick(context.Background())
+
+		assert.Equal(t, ErrNoAddresses, err)
+	})
+}
+
+func waitForResolverRefresh(t *testing.T, r *resolver) {
+	assert.Eventually(t, func() bool {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		return !r.refreshing
+	}, time.Second, time.Millisecond)
+}
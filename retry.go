@@ -111,6 +111,7 @@ type RetryConfig struct {
 	DelayType        DelayTypeFunc
 	LastErrorOnly    bool
 	IsRetryableError IsRetryableErrorFunc
+	BackoffStatePath string
 }
 
 // Retry retries a function
@@ -138,10 +139,33 @@ func Retry(retryableFunc func() error, opts ...RetryOption) error {
 		errorLog = make(RetryError, 1)
 	}
 
+	// resume the backoff of a previous process, only waiting for what's left of its delay
+	var state backoffState
+	if config.BackoffStatePath != "" {
+		state = loadBackoffState(config.BackoffStatePath)
+		if state.Failures > 0 {
+			if remaining := config.DelayType(state.Failures-1, config) - time.Since(state.UpdatedAt); remaining > 0 {
+				time.Sleep(remaining)
+			}
+		}
+	}
+
 	lastErrIndex := n
 	for n < config.Attempts {
 		err := retryableFunc()
 
+		if config.BackoffStatePath != "" {
+			if err != nil {
+				if state.Failures < maxPersistedBackoffFailures {
+					state.Failures++
+				}
+				state.UpdatedAt = time.Now()
+				saveBackoffState(config.BackoffStatePath, state)
+			} else if state.Failures > 0 {
+				removeBackoffState(config.BackoffStatePath)
+			}
+		}
+
 		if err != nil {
 			errorLog[lastErrIndex] = unpackUnrecoverable(err)
 
@@ -156,6 +180,9 @@ func Retry(retryableFunc func() error, opts ...RetryOption) error {
 			}
 
 			delayTime := config.DelayType(n, config)
+			if state.Failures > 0 {
+				delayTime = config.DelayType(state.Failures-1, config)
+			}
 			time.Sleep(delayTime)
 		} else {
 			return nil
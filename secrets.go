@@ -0,0 +1,387 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrSecretNotFound is returned by a SecretProvider for a secret that doesn't exist
+var ErrSecretNotFound = errors.New("Secret does not exist")
+
+// SecretProvider returns secrets by name from a secret source, so services can switch between mounted files, envvars and vault via config
+type SecretProvider interface {
+	// GetSecret returns the value of the named secret
+	GetSecret(ctx context.Context, name string) ([]byte, error)
+}
+
+// SecretRotationFunc is called by a caching secret provider when the value of a secret changes
+type SecretRotationFunc func(name string, value []byte)
+
+// CachingSecretProvider caches secrets of another SecretProvider and refreshes them periodically
+type CachingSecretProvider interface {
+	SecretProvider
+	// OnRotation registers a function called when a refresh returns a new value for the named secret
+	OnRotation(name string, fn SecretRotationFunc)
+	// Close stops refreshing secrets
+	Close()
+}
+
+// NewFileSecretProvider returns a SecretProvider reading each secret from the file with its name in dir, like a mounted kubernetes secret; trailing newlines are trimmed
+func NewFileSecretProvider(dir string) SecretProvider {
+	return &fileSecretProvider{dir: dir}
+}
+
+type fileSecretProvider struct {
+	dir string
+}
+
+func (p *fileSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	// don't allow names to escape the directory
+	if name == "" || strings.Contains(name, "..") {
+		return nil, fmt.Errorf("%w: %v", ErrSecretNotFound, name)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(p.dir, filepath.Clean(name)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %v", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(data, "\r\n"), nil
+}
+
+// NewEnvSecretProvider returns a SecretProvider reading each secret from an envvar with the prefix and its name upper-cased with dashes and dots replaced by underscores, so secret db-password with prefix ESTAFETTE_ is read from ESTAFETTE_DB_PASSWORD
+func NewEnvSecretProvider(prefix string) SecretProvider {
+	return &envSecretProvider{prefix: prefix}
+}
+
+type envSecretProvider struct {
+	prefix string
+}
+
+func (p *envSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	envVar := p.prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+
+	value, ok := os.LookupEnv(envVar)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrSecretNotFound, name)
+	}
+
+	return []byte(value), nil
+}
+
+// VaultOption allows to override the defaults of NewVaultSecretProvider
+type VaultOption func(*VaultConfig)
+
+// VaultConfig is used to configure the provider returned by NewVaultSecretProvider
+type VaultConfig struct {
+	Token               string
+	KubernetesRole      string
+	KubernetesJWTPath   string
+	KubernetesAuthMount string
+	HTTPClient          *http.Client
+}
+
+// WithVaultToken sets the token to authenticate with
+// default is envvar VAULT_TOKEN
+func WithVaultToken(token string) VaultOption {
+	return func(c *VaultConfig) {
+		c.Token = token
+	}
+}
+
+// WithVaultKubernetesAuth logs in with the kubernetes service account token of the pod for the vault role, instead of using a static token
+func WithVaultKubernetesAuth(role string) VaultOption {
+	return func(c *VaultConfig) {
+		c.KubernetesRole = role
+	}
+}
+
+// WithVaultKubernetesJWTPath sets the path of the service account token used for kubernetes auth
+// default is /var/run/secrets/kubernetes.io/serviceaccount/token
+func WithVaultKubernetesJWTPath(path string) VaultOption {
+	return func(c *VaultConfig) {
+		c.KubernetesJWTPath = path
+	}
+}
+
+// WithVaultKubernetesAuthMount sets the path the kubernetes auth method is mounted at
+// default is kubernetes
+func WithVaultKubernetesAuthMount(mount string) VaultOption {
+	return func(c *VaultConfig) {
+		c.KubernetesAuthMount = mount
+	}
+}
+
+// WithVaultHTTPClient sets the http client to call vault with
+// default is the client returned by NewHTTPClient
+func WithVaultHTTPClient(client *http.Client) VaultOption {
+	return func(c *VaultConfig) {
+		c.HTTPClient = client
+	}
+}
+
+// NewVaultSecretProvider returns a SecretProvider reading secrets from the kv version 2 secrets engine mounted at mount of the vault server at address; a secret name has the form path#key, like ziplinee/database#password, with key defaulting to value
+func NewVaultSecretProvider(address, mount string, opts ...VaultOption) SecretProvider {
+
+	config := &VaultConfig{
+		Token:               os.Getenv("VAULT_TOKEN"),
+		KubernetesJWTPath:   "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		KubernetesAuthMount: "kubernetes",
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = NewHTTPClient("vault")
+	}
+
+	return &vaultSecretProvider{
+		address: strings.TrimRight(address, "/"),
+		mount:   strings.Trim(mount, "/"),
+		config:  config,
+		token:   config.Token,
+	}
+}
+
+type vaultSecretProvider struct {
+	address string
+	mount   string
+	config  *VaultConfig
+
+	mutex          sync.Mutex
+	token          string
+	tokenExpiresAt time.Time
+}
+
+func (p *vaultSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	path, key := name, "value"
+	if i := strings.LastIndex(name, "#"); i >= 0 {
+		path, key = name[:i], name[i+1:]
+	}
+
+	token, err := p.getToken(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	status, err := p.do(ctx, http.MethodGet, fmt.Sprintf("/v1/%v/data/%v", p.mount, strings.Trim(path, "/")), token, nil, &response)
+	if status == http.StatusForbidden && p.config.KubernetesRole != "" {
+		// the token might have been revoked, log in again once
+		if token, err = p.getToken(ctx, true); err != nil {
+			return nil, err
+		}
+		status, err = p.do(ctx, http.MethodGet, fmt.Sprintf("/v1/%v/data/%v", p.mount, strings.Trim(path, "/")), token, nil, &response)
+	}
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %v", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := response.Data.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrSecretNotFound, name)
+	}
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+
+	return json.Marshal(value)
+}
+
+// getToken returns the static token or logs in with kubernetes auth if the previous token expired or force is set
+func (p *vaultSecretProvider) getToken(ctx context.Context, force bool) (string, error) {
+	if p.config.KubernetesRole == "" {
+		return p.token, nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !force && p.token != "" && time.Now().Before(p.tokenExpiresAt) {
+		return p.token, nil
+	}
+
+	jwt, err := ioutil.ReadFile(p.config.KubernetesJWTPath)
+	if err != nil {
+		return "", fmt.Errorf("Reading service account token for vault login failed: %w", err)
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	request := map[string]string{
+		"role": p.config.KubernetesRole,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	}
+	if _, err := p.do(ctx, http.MethodPost, fmt.Sprintf("/v1/auth/%v/login", p.config.KubernetesAuthMount), "", request, &response); err != nil {
+		return "", fmt.Errorf("Logging in to vault with kubernetes auth failed: %w", err)
+	}
+
+	p.token = response.Auth.ClientToken
+	// renew well before the lease expires
+	p.tokenExpiresAt = time.Now().Add(time.Duration(response.Auth.LeaseDuration) * time.Second * 3 / 4)
+
+	return p.token, nil
+}
+
+func (p *vaultSecretProvider) do(ctx context.Context, method, path, token string, request, response interface{}) (int, error) {
+	var body []byte
+	if request != nil {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.address+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("Vault responded to %v %v with status %v", method, path, resp.StatusCode)
+	}
+
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(response)
+}
+
+// NewCachingSecretProvider returns a CachingSecretProvider that caches the secrets of provider and refreshes the cached secrets every refreshInterval, calling the rotation functions for secrets whose value changed; with a refreshInterval of 0 secrets are cached indefinitely
+func NewCachingSecretProvider(provider SecretProvider, refreshInterval time.Duration) CachingSecretProvider {
+
+	p := &cachingSecretProvider{
+		provider:  provider,
+		cache:     map[string][]byte{},
+		callbacks: map[string][]SecretRotationFunc{},
+		stop:      make(chan struct{}),
+	}
+
+	if refreshInterval > 0 {
+		go p.refreshPeriodically(refreshInterval)
+	}
+
+	return p
+}
+
+type cachingSecretProvider struct {
+	provider  SecretProvider
+	mutex     sync.RWMutex
+	cache     map[string][]byte
+	callbacks map[string][]SecretRotationFunc
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+func (p *cachingSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	p.mutex.RLock()
+	value, ok := p.cache[name]
+	p.mutex.RUnlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := p.provider.GetSecret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	p.cache[name] = value
+	p.mutex.Unlock()
+
+	return value, nil
+}
+
+func (p *cachingSecretProvider) OnRotation(name string, fn SecretRotationFunc) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.callbacks[name] = append(p.callbacks[name], fn)
+}
+
+func (p *cachingSecretProvider) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+func (p *cachingSecretProvider) refreshPeriodically(refreshInterval time.Duration) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.refresh(context.Background())
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// refresh fetches all cached secrets again and calls the rotation functions for the ones that changed; on failure the cached value is kept
+func (p *cachingSecretProvider) refresh(ctx context.Context) {
+	p.mutex.RLock()
+	names := make([]string, 0, len(p.cache))
+	for name := range p.cache {
+		names = append(names, name)
+	}
+	p.mutex.RUnlock()
+
+	for _, name := range names {
+		value, err := p.provider.GetSecret(ctx, name)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Refreshing secret %v failed, keeping cached value", name)
+			continue
+		}
+
+		p.mutex.Lock()
+		changed := !bytes.Equal(p.cache[name], value)
+		p.cache[name] = value
+		callbacks := p.callbacks[name]
+		p.mutex.Unlock()
+
+		if changed {
+			log.Info().Msgf("Secret %v has been rotated", name)
+			for _, fn := range callbacks {
+				fn(name, value)
+			}
+		}
+	}
+}
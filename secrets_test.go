@@ -0,0 +1,166 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSecretProvider(t *testing.T) {
+
+	t.Run("ReturnsFileContentWithoutTrailingNewline", func(t *testing.T) {
+
+		dir := t.TempDir()
+		ioutil.WriteFile(filepath.Join(dir, "db-password"), []byte("s3cr3t\n"), 0600)
+		provider := NewFileSecretProvider(dir)
+
+		// act
+		value, err := provider.GetSecret(context.Background(), "db-password")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cr3t", string(value))
+	})
+
+	t.Run("ReturnsErrSecretNotFoundForMissingOrEscapingNames", func(t *testing.T) {
+
+		provider := NewFileSecretProvider(t.TempDir())
+
+		// act
+		_, err := provider.GetSecret(context.Background(), "db-password")
+		_, errEscaping := provider.GetSecret(context.Background(), "../etc/passwd")
+
+		assert.True(t, errors.Is(err, ErrSecretNotFound))
+		assert.True(t, errors.Is(errEscaping, ErrSecretNotFound))
+	})
+}
+
+func TestEnvSecretProvider(t *testing.T) {
+
+	t.Run("ReturnsValueOfPrefixedUpperCasedEnvvar", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_DB_PASSWORD", "s3cr3t")
+		provider := NewEnvSecretProvider("ESTAFETTE_")
+
+		// act
+		value, err := provider.GetSecret(context.Background(), "db-password")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cr3t", string(value))
+	})
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+
+	t.Run("ReturnsKeyOfKvSecretAfterKubernetesLogin", func(t *testing.T) {
+
+		jwtPath := filepath.Join(t.TempDir(), "token")
+		ioutil.WriteFile(jwtPath, []byte("service-account-jwt"), 0600)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v1/auth/kubernetes/login":
+				var login map[string]string
+				json.NewDecoder(r.Body).Decode(&login)
+				if login["role"] != "ziplinee-api" || login["jwt"] != "service-account-jwt" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.Write([]byte(`{"auth":{"client_token":"vault-token","lease_duration":3600}}`))
+			case "/v1/secret/data/ziplinee/database":
+				if r.Header.Get("X-Vault-Token") != "vault-token" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.Write([]byte(`{"data":{"data":{"password":"s3cr3t"}}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		provider := NewVaultSecretProvider(server.URL, "secret", WithVaultKubernetesAuth("ziplinee-api"), WithVaultKubernetesJWTPath(jwtPath))
+
+		// act
+		value, err := provider.GetSecret(context.Background(), "ziplinee/database#password")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cr3t", string(value))
+	})
+
+	t.Run("ReturnsErrSecretNotFoundForMissingSecret", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		provider := NewVaultSecretProvider(server.URL, "secret", WithVaultToken("vault-token"))
+
+		// act
+		_, err := provider.GetSecret(context.Background(), "ziplinee/database#password")
+
+		assert.True(t, errors.Is(err, ErrSecretNotFound))
+	})
+}
+
+type testSecretProvider struct {
+	mutex sync.Mutex
+	value string
+	calls int
+}
+
+func (p *testSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.calls++
+	return []byte(p.value), nil
+}
+
+func TestCachingSecretProvider(t *testing.T) {
+
+	t.Run("ReturnsCachedValue", func(t *testing.T) {
+
+		source := &testSecretProvider{value: "s3cr3t"}
+		provider := NewCachingSecretProvider(source, 0)
+		defer provider.Close()
+
+		// act
+		provider.GetSecret(context.Background(), "db-password")
+		value, err := provider.GetSecret(context.Background(), "db-password")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cr3t", string(value))
+		assert.Equal(t, 1, source.calls)
+	})
+
+	t.Run("CallsRotationFunctionWhenValueChanges", func(t *testing.T) {
+
+		source := &testSecretProvider{value: "s3cr3t"}
+		provider := NewCachingSecretProvider(source, 10*time.Millisecond)
+		defer provider.Close()
+		rotated := make(chan string, 1)
+		provider.OnRotation("db-password", func(name string, value []byte) { rotated <- string(value) })
+		provider.GetSecret(context.Background(), "db-password")
+
+		// act
+		source.mutex.Lock()
+		source.value = "n3w-s3cr3t"
+		source.mutex.Unlock()
+
+		select {
+		case value := <-rotated:
+			assert.Equal(t, "n3w-s3cr3t", value)
+		case <-time.After(time.Second):
+			assert.Fail(t, "rotation function wasn't called")
+		}
+		value, _ := provider.GetSecret(context.Background(), "db-password")
+		assert.Equal(t, "n3w-s3cr3t", string(value))
+	})
+}
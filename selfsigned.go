@@ -0,0 +1,93 @@
+package foundation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"time"
+)
+
+// GenerateSelfSignedCert generates a PEM encoded self-signed certificate and key for the hosts (dns names or ip addresses), valid from now for the duration of validity; the certificate can be used as its own CA bundle
+func GenerateSelfSignedCert(hosts []string, validity time.Duration) (certPEM, keyPEM []byte, err error) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+
+	notBefore := time.Now().Add(-1 * time.Minute)
+	template := x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"Ziplinee CI"}},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	if len(hosts) > 0 {
+		template.Subject.CommonName = hosts[0]
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return
+	}
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+
+	return
+}
+
+// GenerateSelfSignedCertFiles generates a self-signed certificate and key for the hosts and writes them as PEM to certFile and keyFile
+func GenerateSelfSignedCertFiles(hosts []string, validity time.Duration, certFile, keyFile string) error {
+
+	certPEM, keyPEM, err := GenerateSelfSignedCert(hosts, validity)
+	if err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(certFile, certPEM, 0644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(keyFile, keyPEM, 0600)
+}
+
+// GenerateSelfSignedTLSCertificate generates a self-signed certificate for the hosts ready to be used in a tls.Config
+func GenerateSelfSignedTLSCertificate(hosts []string, validity time.Duration) (tls.Certificate, error) {
+
+	certPEM, keyPEM, err := GenerateSelfSignedCert(hosts, validity)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
@@ -0,0 +1,45 @@
+package foundation
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+
+	t.Run("ReturnsCertificateValidForDNSNamesAndIPAddresses", func(t *testing.T) {
+
+		// act
+		certPEM, keyPEM, err := GenerateSelfSignedCert([]string{"localhost", "127.0.0.1"}, time.Hour)
+
+		if assert.Nil(t, err) {
+			assert.NotEmpty(t, keyPEM)
+
+			block, _ := pem.Decode(certPEM)
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if assert.Nil(t, err) {
+				assert.Nil(t, certificate.VerifyHostname("localhost"))
+				assert.Nil(t, certificate.VerifyHostname("127.0.0.1"))
+				assert.NotNil(t, certificate.VerifyHostname("example.com"))
+				assert.True(t, certificate.NotAfter.Before(time.Now().Add(time.Hour)))
+			}
+		}
+	})
+}
+
+func TestGenerateSelfSignedTLSCertificate(t *testing.T) {
+
+	t.Run("ReturnsUsableTLSCertificate", func(t *testing.T) {
+
+		// act
+		certificate, err := GenerateSelfSignedTLSCertificate([]string{"localhost"}, time.Hour)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(certificate.Certificate))
+		assert.NotNil(t, certificate.PrivateKey)
+	})
+}
@@ -0,0 +1,135 @@
+package foundation
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// EWMA tracks an exponentially weighted moving average, for example of build durations
+type EWMA interface {
+	// Add adds a new observation
+	Add(value float64)
+	// Value returns the current moving average; 0 if nothing has been observed yet
+	Value() float64
+}
+
+type ewma struct {
+	mutex       sync.Mutex
+	alpha       float64
+	value       float64
+	initialized bool
+}
+
+// NewEWMA returns an EWMA where each new observation gets weight alpha (between 0 and 1); a higher alpha makes the average follow recent observations more closely
+func NewEWMA(alpha float64) EWMA {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.1
+	}
+
+	return &ewma{
+		alpha: alpha,
+	}
+}
+
+func (e *ewma) Add(value float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// seed with the first observation to avoid the average slowly creeping up from 0
+	if !e.initialized {
+		e.value = value
+		e.initialized = true
+		return
+	}
+
+	e.value = e.alpha*value + (1-e.alpha)*e.value
+}
+
+func (e *ewma) Value() float64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.value
+}
+
+// QuantileTracker tracks quantiles over a rolling window of the most recent observations, for example of queue wait times
+type QuantileTracker interface {
+	// Add adds a new observation, pushing out the oldest one if the window is full
+	Add(value float64)
+	// Quantile returns the q-quantile (between 0 and 1) of the observations in the window; NaN if nothing has been observed yet
+	Quantile(q float64) float64
+	// Count returns the number of observations in the window
+	Count() int
+}
+
+type quantileTracker struct {
+	mutex   sync.Mutex
+	samples []float64
+	next    int
+	full    bool
+}
+
+// NewQuantileTracker returns a QuantileTracker keeping the last windowSize observations
+func NewQuantileTracker(windowSize int) QuantileTracker {
+	if windowSize < 1 {
+		windowSize = 1
+	}
+
+	return &quantileTracker{
+		samples: make([]float64, windowSize),
+	}
+}
+
+func (t *quantileTracker) Add(value float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.samples[t.next] = value
+	t.next = (t.next + 1) % len(t.samples)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+func (t *quantileTracker) Quantile(q float64) float64 {
+	t.mutex.Lock()
+	count := t.count()
+	sorted := make([]float64, count)
+	copy(sorted, t.samples[:count])
+	t.mutex.Unlock()
+
+	if count == 0 {
+		return math.NaN()
+	}
+
+	sort.Float64s(sorted)
+
+	if q <= 0 {
+		return sorted[0]
+	}
+	if q >= 1 {
+		return sorted[count-1]
+	}
+
+	// interpolate linearly between the closest ranks
+	rank := q * float64(count-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (rank-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+func (t *quantileTracker) Count() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.count()
+}
+
+func (t *quantileTracker) count() int {
+	if t.full {
+		return len(t.samples)
+	}
+	return t.next
+}
@@ -0,0 +1,74 @@
+package foundation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEWMA(t *testing.T) {
+
+	t.Run("ReturnsFirstObservationAsValue", func(t *testing.T) {
+
+		ewma := NewEWMA(0.5)
+
+		// act
+		ewma.Add(10)
+
+		assert.Equal(t, 10.0, ewma.Value())
+	})
+
+	t.Run("WeighsNewObservationsByAlpha", func(t *testing.T) {
+
+		ewma := NewEWMA(0.5)
+		ewma.Add(10)
+
+		// act
+		ewma.Add(20)
+
+		assert.Equal(t, 15.0, ewma.Value())
+	})
+}
+
+func TestQuantileTracker(t *testing.T) {
+
+	t.Run("ReturnsNaNWithoutObservations", func(t *testing.T) {
+
+		tracker := NewQuantileTracker(10)
+
+		// act
+		median := tracker.Quantile(0.5)
+
+		assert.True(t, math.IsNaN(median))
+	})
+
+	t.Run("ReturnsInterpolatedQuantile", func(t *testing.T) {
+
+		tracker := NewQuantileTracker(10)
+		for _, v := range []float64{4, 1, 3, 2} {
+			tracker.Add(v)
+		}
+
+		// act
+		median := tracker.Quantile(0.5)
+
+		assert.Equal(t, 2.5, median)
+		assert.Equal(t, 1.0, tracker.Quantile(0))
+		assert.Equal(t, 4.0, tracker.Quantile(1))
+	})
+
+	t.Run("OnlyKeepsMostRecentObservationsInWindow", func(t *testing.T) {
+
+		tracker := NewQuantileTracker(3)
+		for _, v := range []float64{100, 1, 2, 3} {
+			tracker.Add(v)
+		}
+
+		// act
+		max := tracker.Quantile(1)
+
+		assert.Equal(t, 3.0, max)
+		assert.Equal(t, 3, tracker.Count())
+	})
+}
@@ -0,0 +1,177 @@
+package foundation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamingUnsupported is returned when the http.ResponseWriter can't flush partial responses
+var ErrStreamingUnsupported = errors.New("Response writer does not support streaming")
+
+// SSEWriter writes server-sent events to a client
+type SSEWriter interface {
+	// Send writes an event and flushes it to the client; event and id are optional
+	Send(event, id string, data []byte) error
+	// SendJSON writes an event with the json representation of v as data
+	SendJSON(event, id string, v interface{}) error
+	// Done is closed when the client disconnects
+	Done() <-chan struct{}
+	// Close stops sending heartbeats; call it before the handler returns
+	Close()
+}
+
+type sseWriter struct {
+	mutex   sync.Mutex
+	writer  http.ResponseWriter
+	flusher http.Flusher
+	done    <-chan struct{}
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// NewSSEWriter sets the headers for a server-sent events response and sends a comment line every heartbeatInterval to keep proxies from closing the idle connection; a heartbeatInterval of 0 disables heartbeats
+func NewSSEWriter(w http.ResponseWriter, r *http.Request, heartbeatInterval time.Duration) (SSEWriter, error) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disable response buffering in nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sw := &sseWriter{
+		writer:  w,
+		flusher: flusher,
+		done:    r.Context().Done(),
+		stop:    make(chan struct{}),
+	}
+
+	if heartbeatInterval > 0 {
+		sw.stopped.Add(1)
+		go sw.sendHeartbeats(heartbeatInterval)
+	}
+
+	return sw, nil
+}
+
+func (sw *sseWriter) Send(event, id string, data []byte) error {
+	var message strings.Builder
+	if id != "" {
+		fmt.Fprintf(&message, "id: %v\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(&message, "event: %v\n", event)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(&message, "data: %v\n", line)
+	}
+	message.WriteString("\n")
+
+	return sw.write(message.String())
+}
+
+func (sw *sseWriter) SendJSON(event, id string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return sw.Send(event, id, data)
+}
+
+func (sw *sseWriter) Done() <-chan struct{} {
+	return sw.done
+}
+
+func (sw *sseWriter) Close() {
+	sw.mutex.Lock()
+	select {
+	case <-sw.stop:
+	default:
+		close(sw.stop)
+	}
+	sw.mutex.Unlock()
+
+	sw.stopped.Wait()
+}
+
+func (sw *sseWriter) write(message string) error {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	select {
+	case <-sw.done:
+		return io.ErrClosedPipe
+	default:
+	}
+
+	if _, err := io.WriteString(sw.writer, message); err != nil {
+		return err
+	}
+	sw.flusher.Flush()
+
+	return nil
+}
+
+func (sw *sseWriter) sendHeartbeats(interval time.Duration) {
+	defer sw.stopped.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := sw.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-sw.done:
+			return
+		case <-sw.stop:
+			return
+		}
+	}
+}
+
+// StreamLines writes each line received on the channel to the client as soon as it arrives, for example to stream live build logs; it returns when the channel is closed or the client disconnects
+func StreamLines(w http.ResponseWriter, r *http.Request, lines <-chan string) error {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return ErrStreamingUnsupported
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return nil
+			}
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return err
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+	}
+}
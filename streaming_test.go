@@ -0,0 +1,104 @@
+package foundation
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSSEWriter(t *testing.T) {
+
+	t.Run("WritesEventsInServerSentEventsFormat", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/api/builds/1/logs", nil)
+		writer, err := NewSSEWriter(recorder, request, 0)
+		assert.Nil(t, err)
+
+		// act
+		writer.Send("log", "1", []byte("line 1\nline 2"))
+		writer.SendJSON("status", "", map[string]string{"status": "running"})
+		writer.Close()
+
+		assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "id: 1\nevent: log\ndata: line 1\ndata: line 2\n\nevent: status\ndata: {\"status\":\"running\"}\n\n", recorder.Body.String())
+		assert.True(t, recorder.Flushed)
+	})
+
+	t.Run("SendsHeartbeatsUntilClosed", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writer, _ := NewSSEWriter(w, r, 10*time.Millisecond)
+			defer writer.Close()
+			time.Sleep(35 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		// act
+		resp, err := http.Get(server.URL)
+
+		if assert.Nil(t, err) {
+			defer resp.Body.Close()
+			body := bufio.NewScanner(resp.Body)
+			heartbeats := 0
+			for body.Scan() {
+				if body.Text() == ": heartbeat" {
+					heartbeats++
+				}
+			}
+			assert.True(t, heartbeats >= 2)
+		}
+	})
+
+	t.Run("ReturnsErrorOnSendAfterClientDisconnected", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/api/builds/1/logs", nil).WithContext(ctx)
+		writer, _ := NewSSEWriter(recorder, request, 0)
+		defer writer.Close()
+		cancel()
+
+		// act
+		err := writer.Send("log", "", []byte("line"))
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestStreamLines(t *testing.T) {
+
+	t.Run("WritesLinesUntilChannelIsClosed", func(t *testing.T) {
+
+		lines := make(chan string, 2)
+		lines <- "step 1"
+		lines <- "step 2"
+		close(lines)
+		recorder := httptest.NewRecorder()
+
+		// act
+		err := StreamLines(recorder, httptest.NewRequest(http.MethodGet, "/api/builds/1/logs", nil), lines)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "step 1\nstep 2\n", recorder.Body.String())
+	})
+
+	t.Run("ReturnsWhenClientDisconnects", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		recorder := httptest.NewRecorder()
+
+		// act
+		err := StreamLines(recorder, httptest.NewRequest(http.MethodGet, "/api/builds/1/logs", nil).WithContext(ctx), make(chan string))
+
+		assert.NotNil(t, err)
+		assert.True(t, strings.Contains(err.Error(), "canceled"))
+	})
+}
@@ -0,0 +1,53 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrSystemStatsUnsupported is returned by GetSystemStats on platforms other than linux and darwin, along with only the cpu count
+var ErrSystemStatsUnsupported = errors.New("System stats are not supported on this platform")
+
+// SystemStats is a snapshot of the resource usage of the host
+type SystemStats struct {
+	Timestamp               time.Time `json:"timestamp"`
+	CPUCount                int       `json:"cpuCount"`
+	LoadAverage1            float64   `json:"loadAverage1"`
+	LoadAverage5            float64   `json:"loadAverage5"`
+	LoadAverage15           float64   `json:"loadAverage15"`
+	MemoryTotalBytes        uint64    `json:"memoryTotalBytes"`
+	MemoryAvailableBytes    uint64    `json:"memoryAvailableBytes"`
+	DiskTotalBytes          uint64    `json:"diskTotalBytes"`
+	DiskFreeBytes           uint64    `json:"diskFreeBytes"`
+	NetworkReceivedBytes    uint64    `json:"networkReceivedBytes"`
+	NetworkTransmittedBytes uint64    `json:"networkTransmittedBytes"`
+}
+
+// GetSystemStats returns the cpu load, memory, disk usage of the root filesystem and network counters summed over all interfaces except loopback
+func GetSystemStats() (SystemStats, error) {
+	return getSystemStats("/")
+}
+
+// SampleSystemStats calls onSample with the current system stats every interval until ctx is cancelled
+func SampleSystemStats(ctx context.Context, interval time.Duration, onSample func(SystemStats)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := GetSystemStats()
+		if err != nil {
+			log.Warn().Err(err).Msg("Getting system stats failed")
+		} else {
+			onSample(stats)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
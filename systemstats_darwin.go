@@ -0,0 +1,82 @@
+package foundation
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// getSystemStats reads the stats with sysctl; available memory only counts free pages, and network counters are 32 bit and wrap around at 4GiB
+func getSystemStats(diskPath string) (stats SystemStats, err error) {
+	stats.Timestamp = time.Now().UTC()
+	stats.CPUCount = runtime.NumCPU()
+
+	// struct loadavg holds 3 fixed point uint32 load averages followed by the long fscale to divide them by
+	loadAverage, err := sysctlBytes("vm.loadavg", 24)
+	if err != nil {
+		return stats, err
+	}
+	fscale := float64(binary.LittleEndian.Uint64(loadAverage[16:24]))
+	if fscale == 0 {
+		return stats, errors.New("Unexpected vm.loadavg scale 0")
+	}
+	stats.LoadAverage1 = float64(binary.LittleEndian.Uint32(loadAverage[0:4])) / fscale
+	stats.LoadAverage5 = float64(binary.LittleEndian.Uint32(loadAverage[4:8])) / fscale
+	stats.LoadAverage15 = float64(binary.LittleEndian.Uint32(loadAverage[8:12])) / fscale
+
+	memorySize, err := sysctlBytes("hw.memsize", 8)
+	if err != nil {
+		return stats, err
+	}
+	stats.MemoryTotalBytes = binary.LittleEndian.Uint64(memorySize)
+
+	pageSize, err := syscall.SysctlUint32("hw.pagesize")
+	if err != nil {
+		return stats, err
+	}
+	freePages, err := syscall.SysctlUint32("vm.page_free_count")
+	if err != nil {
+		return stats, err
+	}
+	stats.MemoryAvailableBytes = uint64(freePages) * uint64(pageSize)
+
+	rib, err := syscall.RouteRIB(syscall.NET_RT_IFLIST, 0)
+	if err != nil {
+		return stats, err
+	}
+	messages, err := syscall.ParseRoutingMessage(rib)
+	if err != nil {
+		return stats, err
+	}
+	for _, m := range messages {
+		if im, ok := m.(*syscall.InterfaceMessage); ok && im.Header.Flags&syscall.IFF_LOOPBACK == 0 {
+			stats.NetworkReceivedBytes += uint64(im.Header.Data.Ibytes)
+			stats.NetworkTransmittedBytes += uint64(im.Header.Data.Obytes)
+		}
+	}
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(diskPath, &fs); err != nil {
+		return stats, err
+	}
+	stats.DiskTotalBytes = fs.Blocks * uint64(fs.Bsize)
+	stats.DiskFreeBytes = fs.Bavail * uint64(fs.Bsize)
+
+	return stats, nil
+}
+
+// sysctlBytes returns the raw value of a sysctl as size bytes; syscall.Sysctl strips a trailing zero byte, which is added back
+func sysctlBytes(name string, size int) ([]byte, error) {
+	value, err := syscall.Sysctl(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) > size {
+		return nil, fmt.Errorf("Unexpected size %v of sysctl %v", len(value), name)
+	}
+
+	return append([]byte(value), make([]byte, size-len(value))...), nil
+}
@@ -0,0 +1,128 @@
+package foundation
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func getSystemStats(diskPath string) (stats SystemStats, err error) {
+	stats.Timestamp = time.Now().UTC()
+	stats.CPUCount = runtime.NumCPU()
+
+	data, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return stats, err
+	}
+	if stats.LoadAverage1, stats.LoadAverage5, stats.LoadAverage15, err = parseLoadAverage(data); err != nil {
+		return stats, err
+	}
+
+	data, err = ioutil.ReadFile("/proc/meminfo")
+	if err != nil {
+		return stats, err
+	}
+	if stats.MemoryTotalBytes, stats.MemoryAvailableBytes, err = parseMemoryInfo(data); err != nil {
+		return stats, err
+	}
+
+	data, err = ioutil.ReadFile("/proc/net/dev")
+	if err != nil {
+		return stats, err
+	}
+	if stats.NetworkReceivedBytes, stats.NetworkTransmittedBytes, err = parseNetworkDevices(data); err != nil {
+		return stats, err
+	}
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(diskPath, &fs); err != nil {
+		return stats, err
+	}
+	stats.DiskTotalBytes = fs.Blocks * uint64(fs.Bsize)
+	stats.DiskFreeBytes = fs.Bavail * uint64(fs.Bsize)
+
+	return stats, nil
+}
+
+// parseLoadAverage parses the contents of /proc/loadavg
+func parseLoadAverage(data []byte) (load1, load5, load15 float64, err error) {
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return 0, 0, 0, fmt.Errorf("Unexpected /proc/loadavg format %q", string(data))
+	}
+
+	if load1, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return
+	}
+	if load5, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return
+	}
+	load15, err = strconv.ParseFloat(fields[2], 64)
+
+	return
+}
+
+// parseMemoryInfo parses the total and available memory from the contents of /proc/meminfo
+func parseMemoryInfo(data []byte) (totalBytes, availableBytes uint64, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		var target *uint64
+		switch fields[0] {
+		case "MemTotal:":
+			target = &totalBytes
+		case "MemAvailable:":
+			target = &availableBytes
+		default:
+			continue
+		}
+
+		kiloBytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		*target = kiloBytes * 1024
+	}
+
+	return totalBytes, availableBytes, scanner.Err()
+}
+
+// parseNetworkDevices sums the received and transmitted bytes of all interfaces except loopback from the contents of /proc/net/dev
+func parseNetworkDevices(data []byte) (receivedBytes, transmittedBytes uint64, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "lo" {
+			continue
+		}
+
+		fields := strings.Fields(parts[1])
+		if len(fields) < 9 {
+			continue
+		}
+
+		received, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		transmitted, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		receivedBytes += received
+		transmittedBytes += transmitted
+	}
+
+	return receivedBytes, transmittedBytes, scanner.Err()
+}
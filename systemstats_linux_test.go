@@ -0,0 +1,69 @@
+package foundation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSystemStats(t *testing.T) {
+
+	t.Run("ReturnsStatsOfHost", func(t *testing.T) {
+
+		// act
+		stats, err := GetSystemStats()
+
+		if assert.Nil(t, err) {
+			assert.True(t, stats.CPUCount > 0)
+			assert.True(t, stats.MemoryTotalBytes >= stats.MemoryAvailableBytes)
+			assert.True(t, stats.DiskTotalBytes >= stats.DiskFreeBytes)
+		}
+	})
+}
+
+func TestParseLoadAverage(t *testing.T) {
+
+	t.Run("ReturnsLoadAverages", func(t *testing.T) {
+
+		// act
+		load1, load5, load15, err := parseLoadAverage([]byte("0.29 0.28 0.19 2/72 13919\n"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0.29, load1)
+		assert.Equal(t, 0.28, load5)
+		assert.Equal(t, 0.19, load15)
+	})
+}
+
+func TestParseMemoryInfo(t *testing.T) {
+
+	t.Run("ReturnsTotalAndAvailableMemoryInBytes", func(t *testing.T) {
+
+		// act
+		total, available, err := parseMemoryInfo([]byte("MemTotal:        6147400 kB\nMemFree:         4472636 kB\nMemAvailable:    5630888 kB\n"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(6147400*1024), total)
+		assert.Equal(t, uint64(5630888*1024), available)
+	})
+}
+
+func TestParseNetworkDevices(t *testing.T) {
+
+	t.Run("SumsBytesOfAllInterfacesExceptLoopback", func(t *testing.T) {
+
+		data := []byte(`Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 44010971    6892    0    0    0     0          0         0 44010971    6892    0    0    0     0       0          0
+  eth0:     1000      10    0    0    0     0          0         0      200       2    0    0    0     0       0          0
+  eth1:       24       1    0    0    0     0          0         0       36       1    0    0    0     0       0          0
+`)
+
+		// act
+		received, transmitted, err := parseNetworkDevices(data)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1024), received)
+		assert.Equal(t, uint64(236), transmitted)
+	})
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package foundation
+
+import (
+	"runtime"
+	"time"
+)
+
+func getSystemStats(diskPath string) (SystemStats, error) {
+	return SystemStats{
+		Timestamp: time.Now().UTC(),
+		CPUCount:  runtime.NumCPU(),
+	}, ErrSystemStatsUnsupported
+}
@@ -0,0 +1,109 @@
+package testkit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a foundation.Clock that only moves forward when Advance is called, so timeouts can be tested without waiting for them
+type FakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+type fakeTimer struct {
+	firesAt time.Time
+	c       chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// After returns a channel that receives the fake current time once the clock has been advanced by d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	timer := &fakeTimer{firesAt: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- c.now
+		return timer.c
+	}
+
+	c.timers = append(c.timers, timer)
+	c.notify()
+
+	return timer.c
+}
+
+// Advance moves the clock forward by d and fires all timers that are due, in the order they're due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].firesAt.Before(c.timers[j].firesAt) })
+
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.firesAt.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+	c.notify()
+}
+
+// Timers returns the number of timers waiting to fire
+func (c *FakeClock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.timers)
+}
+
+// WaitForTimers blocks until at least n timers are waiting to fire or timeout has passed in real time, and returns whether there are; use it to make sure the code under test waits for a timeout before calling Advance
+func (c *FakeClock) WaitForTimers(n int, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		c.mutex.Lock()
+		count := len(c.timers)
+		changed := c.changed
+		c.mutex.Unlock()
+
+		if count >= n {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return false
+		}
+	}
+}
+
+// notify wakes up WaitForTimers; must be called with the mutex held
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
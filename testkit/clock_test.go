@@ -0,0 +1,47 @@
+package testkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+
+	t.Run("FiresTimersOnlyWhenAdvancedPastTheirDuration", func(t *testing.T) {
+
+		clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		timer := clock.After(10 * time.Second)
+
+		clock.Advance(5 * time.Second)
+		assert.Equal(t, 0, len(timer))
+
+		// act
+		clock.Advance(5 * time.Second)
+
+		assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 10, 0, time.UTC), <-timer)
+		assert.Equal(t, 0, clock.Timers())
+	})
+
+	t.Run("WaitsForTimersCreatedInOtherGoroutines", func(t *testing.T) {
+
+		clock := NewFakeClock(time.Now())
+		go clock.After(time.Second)
+
+		// act
+		ok := clock.WaitForTimers(1, time.Second)
+
+		assert.True(t, ok)
+	})
+
+	t.Run("ReturnsFalseIfTimersArentCreatedWithinTimeout", func(t *testing.T) {
+
+		clock := NewFakeClock(time.Now())
+
+		// act
+		ok := clock.WaitForTimers(1, 10*time.Millisecond)
+
+		assert.False(t, ok)
+	})
+}
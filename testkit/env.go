@@ -0,0 +1,18 @@
+package testkit
+
+import (
+	"testing"
+
+	foundation "github.com/ziplineeci/ziplinee-foundation"
+)
+
+// WithEnv sets the envvars for the duration of the test and restores them when the test and its subtests have finished; like t.Setenv it can't be used in parallel tests, since the environment is shared by the whole process
+func WithEnv(t testing.TB, env map[string]string) {
+	t.Helper()
+
+	restore, err := foundation.SetEnv(env)
+	if err != nil {
+		t.Fatalf("Setting envvars failed: %v", err)
+	}
+	t.Cleanup(restore)
+}
@@ -0,0 +1,25 @@
+package testkit
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithEnv(t *testing.T) {
+
+	t.Run("RestoresEnvvarsAfterTest", func(t *testing.T) {
+
+		t.Run("SetsEnvvars", func(t *testing.T) {
+
+			// act
+			WithEnv(t, map[string]string{"ESTAFETTE_TEST_WITH_ENV": "during"})
+
+			assert.Equal(t, "during", os.Getenv("ESTAFETTE_TEST_WITH_ENV"))
+		})
+
+		_, isSet := os.LookupEnv("ESTAFETTE_TEST_WITH_ENV")
+		assert.False(t, isSet)
+	})
+}
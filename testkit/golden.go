@@ -0,0 +1,172 @@
+package testkit
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var updateGoldenFiles = flag.Bool("update-golden", false, "update golden files instead of comparing against them")
+
+// Normalizer replaces parts of an output that differ between runs, like timestamps or generated ids, before comparing it to a golden file
+type Normalizer func(output []byte) []byte
+
+// GoldenOption allows to override the defaults of AssertGolden
+type GoldenOption func(*GoldenConfig)
+
+// GoldenConfig is used to configure AssertGolden
+type GoldenConfig struct {
+	Dir         string
+	Normalizers []Normalizer
+}
+
+// WithGoldenDir sets the directory holding the golden files
+// default is testdata
+func WithGoldenDir(dir string) GoldenOption {
+	return func(c *GoldenConfig) {
+		c.Dir = dir
+	}
+}
+
+// WithNormalizers adds normalizers applied to the output, in order, before comparing or updating the golden file
+func WithNormalizers(normalizers ...Normalizer) GoldenOption {
+	return func(c *GoldenConfig) {
+		c.Normalizers = append(c.Normalizers, normalizers...)
+	}
+}
+
+// NormalizeRegexp returns a Normalizer replacing all matches of pattern with replacement
+func NormalizeRegexp(pattern, replacement string) Normalizer {
+	re := regexp.MustCompile(pattern)
+	return func(output []byte) []byte {
+		return re.ReplaceAll(output, []byte(replacement))
+	}
+}
+
+// NormalizeTimestamps replaces RFC 3339 timestamps, like 2026-01-02T15:04:05.999Z, with <timestamp>
+var NormalizeTimestamps = NormalizeRegexp(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`, "<timestamp>")
+
+// NormalizeUUIDs replaces uuids with <uuid>
+var NormalizeUUIDs = NormalizeRegexp(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, "<uuid>")
+
+// AssertGolden compares output with the golden file <name>.golden and fails the test with a diff if they differ; run the tests with -update-golden to write the output to the golden file instead
+func AssertGolden(t testing.TB, name string, output []byte, opts ...GoldenOption) bool {
+	t.Helper()
+
+	config := &GoldenConfig{
+		Dir: "testdata",
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	for _, normalize := range config.Normalizers {
+		output = normalize(output)
+	}
+
+	path := filepath.Join(config.Dir, name+".golden")
+
+	if *updateGoldenFiles {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Creating golden file directory failed: %v", err)
+		}
+		if err := ioutil.WriteFile(path, output, 0644); err != nil {
+			t.Fatalf("Updating golden file %v failed: %v", path, err)
+		}
+		return true
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("Reading golden file %v failed, run with -update-golden to create it: %v", path, err)
+		return false
+	}
+
+	if !bytes.Equal(expected, output) {
+		t.Errorf("Output doesn't match golden file %v, run with -update-golden to update it:\n%v", path, Diff(string(expected), string(output)))
+		return false
+	}
+
+	return true
+}
+
+// Diff returns a line-based diff of expected and actual, with removed lines prefixed by '-', added lines by '+' and up to 3 unchanged lines of context around each change
+func Diff(expected, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type diffLine struct {
+		op   byte
+		text string
+	}
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+
+	const context = 3
+	show := make([]bool, len(lines))
+	for k, line := range lines {
+		if line.op == ' ' {
+			continue
+		}
+		for c := k - context; c <= k+context; c++ {
+			if c >= 0 && c < len(lines) {
+				show[c] = true
+			}
+		}
+	}
+
+	var diff strings.Builder
+	skipped := false
+	for k, line := range lines {
+		if !show[k] {
+			skipped = true
+			continue
+		}
+		if skipped && diff.Len() > 0 {
+			diff.WriteString("...\n")
+		}
+		skipped = false
+		fmt.Fprintf(&diff, "%c %v\n", line.op, line.text)
+	}
+
+	return diff.String()
+}
@@ -0,0 +1,80 @@
+package testkit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingT records errors instead of failing the test
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGolden(t *testing.T) {
+
+	t.Run("ReturnsTrueIfNormalizedOutputMatchesGoldenFile", func(t *testing.T) {
+
+		output := []byte("Build 0b6f7e0c-3c5e-4d3b-9a4e-9f1f2b2c8d11 succeeded at 2026-10-16T12:34:56.789Z\n")
+
+		// act
+		ok := AssertGolden(t, "notification", output, WithNormalizers(NormalizeUUIDs, NormalizeTimestamps))
+
+		assert.True(t, ok)
+	})
+
+	t.Run("FailsWithDiffIfOutputDiffers", func(t *testing.T) {
+
+		recorder := &recordingT{TB: t}
+
+		// act
+		ok := AssertGolden(recorder, "notification", []byte("Build <uuid> failed at <timestamp>\n"))
+
+		assert.False(t, ok)
+		if assert.Equal(t, 1, len(recorder.errors)) {
+			assert.Contains(t, recorder.errors[0], "- Build <uuid> succeeded at <timestamp>\n+ Build <uuid> failed at <timestamp>\n")
+		}
+	})
+
+	t.Run("FailsIfGoldenFileDoesNotExist", func(t *testing.T) {
+
+		recorder := &recordingT{TB: t}
+
+		// act
+		ok := AssertGolden(recorder, "missing", []byte("output"))
+
+		assert.False(t, ok)
+		assert.Equal(t, 1, len(recorder.errors))
+	})
+}
+
+func TestDiff(t *testing.T) {
+
+	t.Run("ShowsChangedLinesWithContext", func(t *testing.T) {
+
+		expected := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10"
+		actual := "1\n2\n3\n4\n5\nsix\n7\n8\n9\n10"
+
+		// act
+		diff := Diff(expected, actual)
+
+		assert.Equal(t, "  3\n  4\n  5\n- 6\n+ six\n  7\n  8\n  9\n", diff)
+	})
+
+	t.Run("SeparatesDistantChanges", func(t *testing.T) {
+
+		expected := "a\n1\n2\n3\n4\n5\n6\n7\nb"
+		actual := "A\n1\n2\n3\n4\n5\n6\n7\nB"
+
+		// act
+		diff := Diff(expected, actual)
+
+		assert.Equal(t, "- a\n+ A\n  1\n  2\n  3\n...\n  5\n  6\n  7\n- b\n+ B\n", diff)
+	})
+}
@@ -0,0 +1,156 @@
+package testkit
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	foundation "github.com/ziplineeci/ziplinee-foundation"
+)
+
+// HTTPServer is a scripted http server to test retry, backoff and webhook logic against; it records every request it receives
+type HTTPServer struct {
+	*httptest.Server
+
+	config   *HTTPServerConfig
+	mutex    sync.Mutex
+	requests []CapturedRequest
+}
+
+// CapturedRequest is a request received by an HTTPServer
+type CapturedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+	// SignatureValid is whether the body is signed with the secret set with WithWebhookSecret
+	SignatureValid bool
+	// StatusCode is the status code the server responded with
+	StatusCode int
+}
+
+// HTTPServerOption allows to override the defaults of NewHTTPServer
+type HTTPServerOption func(*HTTPServerConfig)
+
+// HTTPServerConfig is used to configure the server returned by NewHTTPServer
+type HTTPServerConfig struct {
+	Failures          int
+	FailureStatusCode int
+	Latency           time.Duration
+	StatusCode        int
+	Body              []byte
+	WebhookSecret     []byte
+	SignatureHeader   string
+}
+
+// WithFailures makes the server respond to the first n requests with statusCode before succeeding
+// default is 0
+func WithFailures(n int, statusCode int) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.Failures = n
+		c.FailureStatusCode = statusCode
+	}
+}
+
+// WithLatency makes the server wait before responding to each request, unless the request is canceled first
+// default is 0
+func WithLatency(latency time.Duration) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.Latency = latency
+	}
+}
+
+// WithResponse sets the status code and body of successful responses
+// default is 200 with an empty body
+func WithResponse(statusCode int, body string) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.StatusCode = statusCode
+		c.Body = []byte(body)
+	}
+}
+
+// WithWebhookSecret makes the server verify the HMAC SHA-256 signature in header of every request body, recorded as CapturedRequest.SignatureValid
+// default is not verifying signatures
+func WithWebhookSecret(secret []byte, header string) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.WebhookSecret = secret
+		c.SignatureHeader = header
+	}
+}
+
+// NewHTTPServer starts an HTTPServer on an ephemeral port and closes it when the test finishes
+func NewHTTPServer(t testing.TB, opts ...HTTPServerOption) *HTTPServer {
+	t.Helper()
+
+	config := &HTTPServerConfig{
+		FailureStatusCode: http.StatusServiceUnavailable,
+		StatusCode:        http.StatusOK,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	s := &HTTPServer{
+		config: config,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *HTTPServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	request := CapturedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+	if len(s.config.WebhookSecret) > 0 {
+		request.SignatureValid = foundation.VerifyHMACSHA256(s.config.WebhookSecret, body, r.Header.Get(s.config.SignatureHeader))
+	}
+
+	if s.config.Latency > 0 {
+		select {
+		case <-time.After(s.config.Latency):
+		case <-r.Context().Done():
+		}
+	}
+
+	s.mutex.Lock()
+	failing := len(s.requests) < s.config.Failures
+	request.StatusCode = s.config.StatusCode
+	if failing {
+		request.StatusCode = s.config.FailureStatusCode
+	}
+	s.requests = append(s.requests, request)
+	s.mutex.Unlock()
+
+	w.WriteHeader(request.StatusCode)
+	if !failing {
+		w.Write(s.config.Body)
+	}
+}
+
+// Requests returns the requests received so far in the order they were received
+func (s *HTTPServer) Requests() []CapturedRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]CapturedRequest{}, s.requests...)
+}
+
+// RequestCount returns the number of requests received so far
+func (s *HTTPServer) RequestCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.requests)
+}
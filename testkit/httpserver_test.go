@@ -0,0 +1,74 @@
+package testkit
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	foundation "github.com/ziplineeci/ziplinee-foundation"
+)
+
+func TestHTTPServer(t *testing.T) {
+
+	t.Run("FailsFirstRequestsThenSucceeds", func(t *testing.T) {
+
+		server := NewHTTPServer(t, WithFailures(2, http.StatusBadGateway), WithResponse(http.StatusOK, "ok"))
+
+		// act
+		err := foundation.Retry(func() error {
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return assert.AnError
+			}
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, "ok", string(body))
+			return nil
+		}, foundation.Attempts(3), foundation.Fixed(), foundation.DelayMillisecond(1))
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 3, server.RequestCount()) {
+			requests := server.Requests()
+			assert.Equal(t, http.StatusBadGateway, requests[0].StatusCode)
+			assert.Equal(t, http.StatusBadGateway, requests[1].StatusCode)
+			assert.Equal(t, http.StatusOK, requests[2].StatusCode)
+		}
+	})
+
+	t.Run("DelaysResponsesWithLatency", func(t *testing.T) {
+
+		server := NewHTTPServer(t, WithLatency(200*time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+		// act
+		_, err := http.DefaultClient.Do(request)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("CapturesSignedWebhookPayloads", func(t *testing.T) {
+
+		server := NewHTTPServer(t, WithWebhookSecret([]byte("secret"), "X-Signature-256"))
+		dispatcher := foundation.NewDispatcher([]foundation.DispatcherDestination{{Name: "test", URL: server.URL + "/events", Secret: []byte("secret")}}, nil)
+
+		// act
+		err := dispatcher.Dispatch(context.Background(), "build.finished", map[string]string{"status": "succeeded"})
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, server.RequestCount()) {
+			request := server.Requests()[0]
+			assert.Equal(t, "/events", request.Path)
+			assert.Equal(t, "build.finished", request.Header.Get("X-Event-Type"))
+			assert.JSONEq(t, `{"status":"succeeded"}`, string(request.Body))
+			assert.True(t, request.SignatureValid)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+// Package testkit helps testing the startup and graceful shutdown of applications built with the foundation package, by injecting a synthetic SIGTERM, recording the order in which hooks run and controlling timeouts with a fake clock
+package testkit
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	foundation "github.com/ziplineeci/ziplinee-foundation"
+)
+
+// Lifecycle runs the graceful shutdown of an application in a test; the lifecycle state registered with the foundation package is reset when the test finishes
+type Lifecycle struct {
+	// GracefulShutdown and WaitGroup are returned by foundation.InitGracefulShutdownHandling, pass them to the code under test
+	GracefulShutdown chan os.Signal
+	WaitGroup        *sync.WaitGroup
+	// Clock is used for the shutdown timeouts by HandleShutdown and Shutdown
+	Clock *FakeClock
+
+	t     testing.TB
+	mutex sync.Mutex
+	calls []string
+}
+
+// NewLifecycle returns a Lifecycle with a fake clock set to the current time
+func NewLifecycle(t testing.TB) *Lifecycle {
+	t.Helper()
+
+	foundation.ResetLifecycle()
+	gracefulShutdown, waitGroup := foundation.InitGracefulShutdownHandling()
+
+	t.Cleanup(func() {
+		signal.Stop(gracefulShutdown)
+		foundation.ResetLifecycle()
+	})
+
+	return &Lifecycle{
+		GracefulShutdown: gracefulShutdown,
+		WaitGroup:        waitGroup,
+		Clock:            NewFakeClock(time.Now()),
+		t:                t,
+	}
+}
+
+// SendSIGTERM injects a SIGTERM as if it was sent to the process by the orchestrator
+func (l *Lifecycle) SendSIGTERM() {
+	l.GracefulShutdown <- syscall.SIGTERM
+}
+
+// HandleShutdown runs foundation.HandleShutdown with the fake clock in the background and returns a channel that is closed once it returns
+func (l *Lifecycle) HandleShutdown(opts ...foundation.InitOption) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		foundation.HandleShutdown(l.GracefulShutdown, l.WaitGroup, append([]foundation.InitOption{foundation.WithClock(l.Clock)}, opts...)...)
+	}()
+
+	return done
+}
+
+// Shutdown sends SIGTERM and runs the shutdown, failing the test if it doesn't complete within 10 seconds in real time
+func (l *Lifecycle) Shutdown(opts ...foundation.InitOption) {
+	l.t.Helper()
+
+	l.SendSIGTERM()
+	select {
+	case <-l.HandleShutdown(opts...):
+	case <-time.After(10 * time.Second):
+		l.t.Fatalf("Shutdown didn't complete within 10 seconds")
+	}
+}
+
+// Record wraps a shutdown hook, recording its name when it runs
+func (l *Lifecycle) Record(name string, hook func() error) func() error {
+	return func() error {
+		l.record(name)
+		return hook()
+	}
+}
+
+// RecordFunc wraps a function, like one passed to foundation.WithFunctionsOnShutdown, recording its name when it runs
+func (l *Lifecycle) RecordFunc(name string, f func()) func() {
+	return func() {
+		l.record(name)
+		f()
+	}
+}
+
+func (l *Lifecycle) record(name string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.calls = append(l.calls, name)
+}
+
+// Calls returns the names of the recorded hooks and functions in the order they ran
+func (l *Lifecycle) Calls() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return append([]string{}, l.calls...)
+}
+
+// AssertOrder fails the test if the recorded hooks and functions didn't run exactly in the order of names
+func (l *Lifecycle) AssertOrder(names ...string) bool {
+	l.t.Helper()
+
+	calls := l.Calls()
+	if len(calls) != len(names) || (len(names) > 0 && !reflect.DeepEqual(calls, names)) {
+		l.t.Errorf("Expected calls in order %v, got %v", names, calls)
+		return false
+	}
+
+	return true
+}
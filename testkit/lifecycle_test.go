@@ -0,0 +1,43 @@
+package testkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	foundation "github.com/ziplineeci/ziplinee-foundation"
+)
+
+func TestLifecycle(t *testing.T) {
+
+	t.Run("RunsFunctionsOnShutdownBeforeShutdownHooksInReverseOrder", func(t *testing.T) {
+
+		lifecycle := NewLifecycle(t)
+		foundation.RegisterShutdownHook("database", lifecycle.Record("database", func() error { return nil }))
+		foundation.RegisterShutdownHook("queue", lifecycle.Record("queue", func() error { return nil }))
+
+		// act
+		lifecycle.Shutdown(foundation.WithFunctionsOnShutdown(lifecycle.RecordFunc("stop-server", func() {})))
+
+		lifecycle.AssertOrder("stop-server", "queue", "database")
+	})
+
+	t.Run("StopsWaitingForPendingWorkWhenFakeClockPassesShutdownTimeout", func(t *testing.T) {
+
+		lifecycle := NewLifecycle(t)
+		lifecycle.WaitGroup.Add(1)
+		defer lifecycle.WaitGroup.Done()
+		foundation.RegisterShutdownHook("database", lifecycle.Record("database", func() error { return nil }))
+		lifecycle.SendSIGTERM()
+		done := lifecycle.HandleShutdown(foundation.WithShutdownTimeout(30*time.Second), foundation.WithBlockedShutdownWarningInterval(0))
+		// the callback timeout and the shutdown timeout
+		assert.True(t, lifecycle.Clock.WaitForTimers(2, time.Second))
+		assert.Equal(t, 0, len(lifecycle.Calls()))
+
+		// act
+		lifecycle.Clock.Advance(30 * time.Second)
+
+		<-done
+		lifecycle.AssertOrder("database")
+	})
+}
@@ -0,0 +1,139 @@
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// LogEntry is a single parsed log line captured by a LogCapture
+type LogEntry struct {
+	Level   zerolog.Level
+	Message string
+	// Fields holds all fields of the log line, including level and message, as decoded from json
+	Fields map[string]interface{}
+}
+
+// LogCapture captures zerolog output into parsed entries to assert on
+type LogCapture struct {
+	t      testing.TB
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+// NewLogCapture returns a LogCapture; pass its Logger to the code under test, for example with foundation.WithLogger
+func NewLogCapture(t testing.TB) *LogCapture {
+	return &LogCapture{t: t}
+}
+
+// CaptureGlobalLogger replaces the global zerolog logger, used by most foundation functions, with one writing to a LogCapture and restores it when the test finishes; tests using it can't run in parallel
+func CaptureGlobalLogger(t testing.TB) *LogCapture {
+	c := NewLogCapture(t)
+
+	previous := log.Logger
+	log.Logger = c.Logger()
+	t.Cleanup(func() {
+		log.Logger = previous
+	})
+
+	return c
+}
+
+// Logger returns a logger writing to the capture
+func (c *LogCapture) Logger() zerolog.Logger {
+	return zerolog.New(c).With().Timestamp().Logger()
+}
+
+// Write implements io.Writer, so the capture can be used as output of any zerolog logger
+func (c *LogCapture) Write(p []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.buffer.Write(p)
+}
+
+// Entries returns the log lines captured so far; lines that aren't valid json fail the test
+func (c *LogCapture) Entries() []LogEntry {
+	c.t.Helper()
+
+	c.mutex.Lock()
+	output := c.buffer.String()
+	c.mutex.Unlock()
+
+	entries := []LogEntry{}
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			c.t.Errorf("Parsing log line %q failed: %v", line, err)
+			continue
+		}
+
+		entry := LogEntry{Level: zerolog.NoLevel, Fields: fields}
+		if level, ok := fields[zerolog.LevelFieldName].(string); ok {
+			if parsed, err := zerolog.ParseLevel(level); err == nil {
+				entry.Level = parsed
+			}
+		}
+		entry.Message, _ = fields[zerolog.MessageFieldName].(string)
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// AssertLogged fails the test unless an entry with level has a message containing msgContains and all fields with equal values; numbers are compared as decoded from json, so pass them as float64
+func (c *LogCapture) AssertLogged(level zerolog.Level, msgContains string, fields map[string]interface{}) bool {
+	c.t.Helper()
+
+	entries := c.Entries()
+	for _, entry := range entries {
+		if entry.Level == level && strings.Contains(entry.Message, msgContains) && hasFields(entry, fields) {
+			return true
+		}
+	}
+
+	messages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, fmt.Sprintf("%v: %v", entry.Level, entry.Message))
+	}
+	c.t.Errorf("Expected %v log entry containing %q with fields %v, got:\n%v", level, msgContains, fields, strings.Join(messages, "\n"))
+
+	return false
+}
+
+// AssertNotLogged fails the test if an entry with level has a message containing msgContains
+func (c *LogCapture) AssertNotLogged(level zerolog.Level, msgContains string) bool {
+	c.t.Helper()
+
+	for _, entry := range c.Entries() {
+		if entry.Level == level && strings.Contains(entry.Message, msgContains) {
+			c.t.Errorf("Expected no %v log entry containing %q, got %q", level, msgContains, entry.Message)
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasFields(entry LogEntry, fields map[string]interface{}) bool {
+	for name, value := range fields {
+		actual, ok := entry.Fields[name]
+		if !ok || !reflect.DeepEqual(actual, value) {
+			return false
+		}
+	}
+
+	return true
+}
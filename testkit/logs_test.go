@@ -0,0 +1,71 @@
+package testkit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogCapture(t *testing.T) {
+
+	t.Run("ParsesCapturedEntries", func(t *testing.T) {
+
+		capture := NewLogCapture(t)
+		logger := capture.Logger()
+		logger.Warn().Int("attempt", 2).Msg("Retrying request")
+
+		// act
+		entries := capture.Entries()
+
+		if assert.Equal(t, 1, len(entries)) {
+			assert.Equal(t, zerolog.WarnLevel, entries[0].Level)
+			assert.Equal(t, "Retrying request", entries[0].Message)
+			assert.Equal(t, 2.0, entries[0].Fields["attempt"])
+		}
+	})
+
+	t.Run("AssertsEntryWithLevelMessageAndFields", func(t *testing.T) {
+
+		capture := NewLogCapture(t)
+		logger := capture.Logger()
+		logger.Error().Err(errors.New("connection refused")).Str("target", "api").Msg("Calling api failed")
+
+		// act
+		ok := capture.AssertLogged(zerolog.ErrorLevel, "api failed", map[string]interface{}{"error": "connection refused", "target": "api"})
+
+		assert.True(t, ok)
+	})
+
+	t.Run("FailsIfNoEntryMatches", func(t *testing.T) {
+
+		recorder := &recordingT{TB: t}
+		capture := NewLogCapture(recorder)
+		logger := capture.Logger()
+		logger.Info().Msg("Calling api failed")
+
+		// act
+		ok := capture.AssertLogged(zerolog.ErrorLevel, "api failed", nil)
+
+		assert.False(t, ok)
+		assert.Equal(t, 1, len(recorder.errors))
+		assert.True(t, capture.AssertNotLogged(zerolog.ErrorLevel, "api failed"))
+	})
+
+	t.Run("CapturesGlobalLoggerUntilTestFinishes", func(t *testing.T) {
+
+		var capture *LogCapture
+		t.Run("Captures", func(t *testing.T) {
+
+			// act
+			capture = CaptureGlobalLogger(t)
+
+			log.Info().Msg("Captured")
+		})
+		log.Info().Msg("Not captured")
+
+		assert.Equal(t, 1, len(capture.Entries()))
+	})
+}
@@ -0,0 +1,18 @@
+package testkit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	foundation "github.com/ziplineeci/ziplinee-foundation"
+)
+
+// NewTestProbeServer serves the /liveness, /readiness and /startup endpoint on an ephemeral port, so tests can run in parallel, and closes it when the test finishes; request the endpoints at server.URL
+func NewTestProbeServer(t testing.TB, opts ...foundation.InitOption) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(foundation.NewProbesHandler(opts...))
+	t.Cleanup(server.Close)
+
+	return server
+}
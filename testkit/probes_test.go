@@ -0,0 +1,41 @@
+package testkit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	foundation "github.com/ziplineeci/ziplinee-foundation"
+)
+
+func TestNewTestProbeServer(t *testing.T) {
+
+	t.Run("ServesProbesOnEphemeralPort", func(t *testing.T) {
+
+		// act
+		server := NewTestProbeServer(t)
+
+		for _, path := range []string{"/liveness", "/readiness", "/startup"} {
+			resp, err := http.Get(server.URL + path)
+			if assert.Nil(t, err) {
+				resp.Body.Close()
+				assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+			}
+		}
+	})
+
+	t.Run("ReturnsNotReadyIfSetNotReady", func(t *testing.T) {
+
+		NewLifecycle(t)
+		server := NewTestProbeServer(t)
+		foundation.SetReadiness(false)
+
+		// act
+		resp, err := http.Get(server.URL + "/readiness")
+
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// NewTLSConfigFromFiles returns a tls.Config using the client certificate and key from certFile and keyFile and trusting the CA bundle in caFile; with watch set to true the files are reloaded whenever they change on disk. With a caFile the server certificate is verified against the host name sent with SNI; for ip address targets, which aren't sent with SNI, set ServerName on the returned config or connections fail
+func NewTLSConfigFromFiles(certFile, keyFile, caFile string, watch bool) (*tls.Config, error) {
+
+	store := &tlsFilesStore{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return store.getCertificate()
+		},
+	}
+
+	if caFile != "" {
+		// the root CAs of a tls.Config can't be swapped once in use, so verify against the latest CA bundle ourselves
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return store.verifyConnection(state, config.ServerName)
+		}
+	}
+
+	if watch {
+		for _, f := range []string{certFile, keyFile, caFile} {
+			if f == "" {
+				continue
+			}
+			WatchForFileChanges(f, func(event fsnotify.Event) {
+				log.Info().Msgf("File %v changed, reloading tls config...", event.Name)
+				if err := store.load(); err != nil {
+					log.Error().Err(err).Msg("Reloading tls config failed, keeping previous config")
+				}
+			})
+		}
+	}
+
+	return config, nil
+}
+
+type tlsFilesStore struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	caPool      *x509.CertPool
+}
+
+func (s *tlsFilesStore) load() error {
+	var certificate *tls.Certificate
+	if s.certFile != "" || s.keyFile != "" {
+		c, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return fmt.Errorf("Loading certificate %v and key %v failed: %w", s.certFile, s.keyFile, err)
+		}
+		certificate = &c
+	}
+
+	var caPool *x509.CertPool
+	if s.caFile != "" {
+		caBundle, err := ioutil.ReadFile(s.caFile)
+		if err != nil {
+			return fmt.Errorf("Reading ca bundle %v failed: %w", s.caFile, err)
+		}
+		caPool = x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caBundle) {
+			return fmt.Errorf("Ca bundle %v contains no valid certificates", s.caFile)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.certificate = certificate
+	s.caPool = caPool
+
+	return nil
+}
+
+func (s *tlsFilesStore) getCertificate() (*tls.Certificate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.certificate == nil {
+		// an empty certificate makes the client continue the handshake without one
+		return &tls.Certificate{}, nil
+	}
+
+	return s.certificate, nil
+}
+
+// verifyConnection verifies the peer certificate against the latest CA bundle and the server name sent with SNI, or the configured server name if none was sent, like for ip address targets
+func (s *tlsFilesStore) verifyConnection(state tls.ConnectionState, configuredServerName string) error {
+	s.mutex.RLock()
+	caPool := s.caPool
+	s.mutex.RUnlock()
+
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("Peer presented no certificates")
+	}
+
+	serverName := state.ServerName
+	if serverName == "" {
+		serverName = configuredServerName
+	}
+	if serverName == "" {
+		return errors.New("No server name to verify the peer certificate against, set ServerName on the tls config for ip address targets")
+	}
+
+	options := x509.VerifyOptions{
+		Roots:         caPool,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, c := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(c)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(options)
+
+	return err
+}
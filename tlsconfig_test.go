@@ -0,0 +1,161 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTLSConfigFromFiles(t *testing.T) {
+
+	t.Run("ReturnsErrorIfCertificateFilesDoNotExist", func(t *testing.T) {
+
+		dir := t.TempDir()
+
+		// act
+		_, err := NewTLSConfigFromFiles(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), "", false)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TrustsServerSignedByCABundle", func(t *testing.T) {
+
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificateFiles(t, dir, "server")
+		server := startTestTLSServer(t, certFile, keyFile)
+		defer server.Close()
+
+		config, err := NewTLSConfigFromFiles("", "", certFile, false)
+		assert.Nil(t, err)
+		config.ServerName = "127.0.0.1"
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+		// act
+		resp, err := client.Get(server.URL)
+
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("RejectsServerWithCertificateForOtherServerName", func(t *testing.T) {
+
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificateFiles(t, dir, "server")
+		server := startTestTLSServer(t, certFile, keyFile)
+		defer server.Close()
+
+		config, err := NewTLSConfigFromFiles("", "", certFile, false)
+		assert.Nil(t, err)
+		config.ServerName = "127.0.0.2"
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+		// act
+		_, err = client.Get(server.URL)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("RejectsIPAddressTargetWithoutServerName", func(t *testing.T) {
+
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificateFiles(t, dir, "server")
+		server := startTestTLSServer(t, certFile, keyFile)
+		defer server.Close()
+
+		config, err := NewTLSConfigFromFiles("", "", certFile, false)
+		assert.Nil(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+		// act
+		_, err = client.Get(server.URL)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("RejectsServerNotSignedByCABundle", func(t *testing.T) {
+
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificateFiles(t, dir, "server")
+		otherCertFile, _ := writeTestCertificateFiles(t, dir, "other")
+		server := startTestTLSServer(t, certFile, keyFile)
+		defer server.Close()
+
+		config, err := NewTLSConfigFromFiles("", "", otherCertFile, false)
+		assert.Nil(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+		// act
+		_, err = client.Get(server.URL)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestNewTLSConfigFromFilesWithWatch(t *testing.T) {
+
+	t.Run("ReloadsCABundleWhenFileChanges", func(t *testing.T) {
+
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificateFiles(t, dir, "server")
+		otherCertFile, _ := writeTestCertificateFiles(t, dir, "other")
+		server := startTestTLSServer(t, certFile, keyFile)
+		defer server.Close()
+
+		caFile := filepath.Join(dir, "ca.crt")
+		otherCert, _ := ioutil.ReadFile(otherCertFile)
+		ioutil.WriteFile(caFile, otherCert, 0644)
+
+		config, err := NewTLSConfigFromFiles("", "", caFile, true)
+		assert.Nil(t, err)
+		config.ServerName = "127.0.0.1"
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+		_, err = client.Get(server.URL)
+		assert.NotNil(t, err)
+
+		// act
+		serverCert, _ := ioutil.ReadFile(certFile)
+		ioutil.WriteFile(caFile, serverCert, 0644)
+		time.Sleep(100 * time.Millisecond)
+
+		resp, err := client.Get(server.URL)
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+	})
+}
+
+func startTestTLSServer(t *testing.T, certFile, keyFile string) *httptest.Server {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	server.StartTLS()
+
+	return server
+}
+
+func writeTestCertificateFiles(t *testing.T, dir, name string) (certFile, keyFile string) {
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+
+	if err := GenerateSelfSignedCertFiles([]string{"127.0.0.1"}, time.Hour, certFile, keyFile); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
@@ -1,8 +1,15 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
 package foundation
 
 import (
+	"fmt"
 	"io"
+	"net/http"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rs/zerolog/log"
 	"github.com/uber/jaeger-client-go"
 	jaegercfg "github.com/uber/jaeger-client-go/config"
@@ -24,3 +31,54 @@ func InitTracingFromEnv(app string) io.Closer {
 
 	return closer
 }
+
+type tracingRoundTripper struct {
+	target string
+	next   http.RoundTripper
+}
+
+func (rt *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	tracer := opentracing.GlobalTracer()
+
+	spanOpts := []opentracing.StartSpanOption{ext.SpanKindRPCClient}
+	if parent := opentracing.SpanFromContext(req.Context()); parent != nil {
+		spanOpts = append(spanOpts, opentracing.ChildOf(parent.Context()))
+	}
+	span := tracer.StartSpan(fmt.Sprintf("%v %v", req.Method, rt.target), spanOpts...)
+	defer span.Finish()
+
+	ext.HTTPMethod.Set(span, req.Method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+	ext.PeerService.Set(span, rt.target)
+
+	// don't modify the original request, as required for round trippers
+	req = req.Clone(req.Context())
+	tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("error.message", err.Error())
+		return nil, err
+	}
+
+	ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		ext.Error.Set(span, true)
+	}
+
+	return resp, nil
+}
+
+func getTraceID(r *http.Request) string {
+	span := opentracing.SpanFromContext(r.Context())
+	if span == nil {
+		return ""
+	}
+
+	if spanContext, ok := span.Context().(jaeger.SpanContext); ok {
+		return spanContext.TraceID().String()
+	}
+
+	return ""
+}
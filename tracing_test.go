@@ -0,0 +1,40 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHTTPTracing(t *testing.T) {
+
+	t.Run("PropagatesTracingHeaders", func(t *testing.T) {
+
+		tracer := mocktracer.New()
+		opentracing.SetGlobalTracer(tracer)
+		defer opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+		var traceID string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceID = r.Header.Get("Mockpfx-Ids-Traceid")
+		}))
+		defer server.Close()
+		client := NewHTTPClient("test", WithHTTPTracing())
+
+		// act
+		resp, err := client.Get(server.URL)
+
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+			assert.NotEqual(t, "", traceID)
+			assert.Equal(t, 1, len(tracer.FinishedSpans()))
+			assert.Equal(t, "GET test", tracer.FinishedSpans()[0].OperationName)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package foundation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TrackedWaitGroup wraps the waitGroup returned by InitGracefulShutdownHandling and counts pending work by name, so the drain endpoint can report it and HandleShutdown can log which tasks block the shutdown
+type TrackedWaitGroup interface {
+	// Add adds delta, which may be negative, to the counter of name and to the wrapped wait group
+	Add(name string, delta int)
+	// Done decrements the counter of name and the wrapped wait group by one
+	Done(name string)
+	// Counts returns the pending count by name, leaving out names without pending work
+	Counts() map[string]int
+	// Wait blocks until the wrapped wait group counter is zero
+	Wait()
+}
+
+type trackedWaitGroup struct {
+	waitGroup *sync.WaitGroup
+	mutex     sync.Mutex
+	counts    map[string]int
+}
+
+var (
+	trackedWaitGroups      []*trackedWaitGroup
+	trackedWaitGroupsMutex sync.Mutex
+)
+
+// NewTrackedWaitGroup returns a TrackedWaitGroup adding to and waiting on waitGroup; a count for a name dropping below zero panics, like it does for a sync.WaitGroup, so a Done for the wrong name shows up in tests
+func NewTrackedWaitGroup(waitGroup *sync.WaitGroup) TrackedWaitGroup {
+	wg := &trackedWaitGroup{
+		waitGroup: waitGroup,
+		counts:    map[string]int{},
+	}
+
+	trackedWaitGroupsMutex.Lock()
+	defer trackedWaitGroupsMutex.Unlock()
+
+	trackedWaitGroups = append(trackedWaitGroups, wg)
+
+	return wg
+}
+
+func (wg *trackedWaitGroup) Add(name string, delta int) {
+	wg.mutex.Lock()
+	count := wg.counts[name] + delta
+	if count < 0 {
+		wg.mutex.Unlock()
+		panic(fmt.Sprintf("TrackedWaitGroup: negative count for %v", name))
+	}
+	if count == 0 {
+		delete(wg.counts, name)
+	} else {
+		wg.counts[name] = count
+	}
+	wg.mutex.Unlock()
+
+	wg.waitGroup.Add(delta)
+}
+
+func (wg *trackedWaitGroup) Done(name string) {
+	wg.Add(name, -1)
+}
+
+func (wg *trackedWaitGroup) Counts() map[string]int {
+	wg.mutex.Lock()
+	defer wg.mutex.Unlock()
+
+	counts := make(map[string]int, len(wg.counts))
+	for name, count := range wg.counts {
+		counts[name] = count
+	}
+
+	return counts
+}
+
+func (wg *trackedWaitGroup) Wait() {
+	wg.waitGroup.Wait()
+}
+
+// pendingWork returns the pending count by name summed over all tracked wait groups
+func pendingWork() map[string]int {
+	trackedWaitGroupsMutex.Lock()
+	defer trackedWaitGroupsMutex.Unlock()
+
+	pending := map[string]int{}
+	for _, wg := range trackedWaitGroups {
+		for name, count := range wg.Counts() {
+			pending[name] += count
+		}
+	}
+
+	return pending
+}
+
+// formatPendingWork formats pending work as a sorted list like "build (2), webhook (1)"
+func formatPendingWork(pending map[string]int) string {
+	names := make([]string, 0, len(pending))
+	for name := range pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%v (%v)", name, pending[name]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// resetTrackedWaitGroups forgets all tracked wait groups; only for use in tests
+func resetTrackedWaitGroups() {
+	trackedWaitGroupsMutex.Lock()
+	defer trackedWaitGroupsMutex.Unlock()
+
+	trackedWaitGroups = nil
+}
@@ -0,0 +1,67 @@
+package foundation
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackedWaitGroup(t *testing.T) {
+
+	t.Run("ReturnsPendingCountsByName", func(t *testing.T) {
+
+		defer resetTrackedWaitGroups()
+		wg := NewTrackedWaitGroup(&sync.WaitGroup{})
+		wg.Add("build", 2)
+		wg.Add("webhook", 1)
+		wg.Done("webhook")
+
+		// act
+		counts := wg.Counts()
+
+		assert.Equal(t, map[string]int{"build": 2}, counts)
+	})
+
+	t.Run("AddsToWrappedWaitGroup", func(t *testing.T) {
+
+		defer resetTrackedWaitGroups()
+		waitGroup := &sync.WaitGroup{}
+		wg := NewTrackedWaitGroup(waitGroup)
+		wg.Add("build", 1)
+		done := make(chan struct{})
+		go func() {
+			waitGroup.Wait()
+			close(done)
+		}()
+
+		// act
+		wg.Done("build")
+
+		<-done
+	})
+
+	t.Run("PanicsIfCountForNameBecomesNegative", func(t *testing.T) {
+
+		defer resetTrackedWaitGroups()
+		wg := NewTrackedWaitGroup(&sync.WaitGroup{})
+		wg.Add("build", 1)
+
+		// act
+		assert.PanicsWithValue(t, "TrackedWaitGroup: negative count for webhook", func() { wg.Done("webhook") })
+
+		assert.Equal(t, map[string]int{"build": 1}, wg.Counts())
+	})
+
+	t.Run("SumsPendingWorkOverAllTrackedWaitGroups", func(t *testing.T) {
+
+		defer resetTrackedWaitGroups()
+		NewTrackedWaitGroup(&sync.WaitGroup{}).Add("build", 1)
+		NewTrackedWaitGroup(&sync.WaitGroup{}).Add("build", 2)
+
+		// act
+		pending := pendingWork()
+
+		assert.Equal(t, map[string]int{"build": 3}, pending)
+	})
+}
@@ -0,0 +1,85 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ExecuteInTransactionWithRetry runs fn in a transaction and commits it; if fn or the commit fails with a serialization failure or deadlock the transaction is rolled back and retried
+// default is 5 attempts with exponential backoff with jitter starting at 50ms, returning the last error only; override with RetryOption
+func ExecuteInTransactionWithRetry(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error, opts ...RetryOption) error {
+
+	retryOpts := []RetryOption{
+		Attempts(5),
+		DelayMillisecond(50),
+		ExponentialJitterBackoff(),
+		LastErrorOnly(true),
+		func(c *RetryConfig) {
+			c.IsRetryableError = func(err error) bool {
+				return ctx.Err() == nil && IsRetryableTransactionError(err)
+			}
+		},
+	}
+	retryOpts = append(retryOpts, opts...)
+
+	return Retry(func() error {
+		err := executeInTransaction(ctx, db, fn)
+		if err != nil && IsRetryableTransactionError(err) {
+			log.Debug().Err(err).Msg("Transaction failed with retryable error")
+		}
+		return err
+	}, retryOpts...)
+}
+
+func executeInTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			tx.Rollback()
+			panic(rec)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// sqlStateError is implemented by errors of postgres drivers like github.com/jackc/pgx and github.com/lib/pq
+type sqlStateError interface {
+	SQLState() string
+}
+
+// IsRetryableTransactionError returns whether the error is a serialization failure (SQLSTATE 40001) or deadlock (SQLSTATE 40P01) after which the transaction can be retried
+func IsRetryableTransactionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+		return false
+	}
+
+	// fall back to the error message for drivers that don't expose the sqlstate
+	message := err.Error()
+	return strings.Contains(message, "40001") || strings.Contains(message, "40P01") || strings.Contains(message, "restart transaction")
+}
@@ -0,0 +1,102 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSQLStateError struct {
+	code string
+}
+
+func (e fakeSQLStateError) Error() string    { return "pq: could not serialize access" }
+func (e fakeSQLStateError) SQLState() string { return e.code }
+
+func TestExecuteInTransactionWithRetry(t *testing.T) {
+
+	t.Run("RetriesOnSerializationFailure", func(t *testing.T) {
+
+		db, _ := sql.Open("fake", "fake://")
+		defer db.Close()
+		attempts := 0
+
+		// act
+		err := ExecuteInTransactionWithRetry(context.Background(), db, func(tx *sql.Tx) error {
+			attempts++
+			if attempts < 3 {
+				return fakeSQLStateError{code: "40001"}
+			}
+			return nil
+		}, DelayMillisecond(1))
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("DoesNotRetryOtherErrors", func(t *testing.T) {
+
+		db, _ := sql.Open("fake", "fake://")
+		defer db.Close()
+		attempts := 0
+
+		// act
+		err := ExecuteInTransactionWithRetry(context.Background(), db, func(tx *sql.Tx) error {
+			attempts++
+			return errors.New("duplicate key")
+		}, DelayMillisecond(1))
+
+		assert.Equal(t, "duplicate key", err.Error())
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("ReturnsLastErrorWhenAttemptsAreExhausted", func(t *testing.T) {
+
+		db, _ := sql.Open("fake", "fake://")
+		defer db.Close()
+		attempts := 0
+
+		// act
+		err := ExecuteInTransactionWithRetry(context.Background(), db, func(tx *sql.Tx) error {
+			attempts++
+			return fakeSQLStateError{code: "40P01"}
+		}, Attempts(2), DelayMillisecond(1))
+
+		assert.True(t, IsRetryableTransactionError(err))
+		assert.Equal(t, 2, attempts)
+	})
+}
+
+func TestIsRetryableTransactionError(t *testing.T) {
+
+	t.Run("ReturnsTrueForWrappedSerializationFailure", func(t *testing.T) {
+
+		// act
+		retryable := IsRetryableTransactionError(fmt.Errorf("Updating build failed: %w", fakeSQLStateError{code: "40001"}))
+
+		assert.True(t, retryable)
+	})
+
+	t.Run("ReturnsFalseForOtherSQLState", func(t *testing.T) {
+
+		// act
+		retryable := IsRetryableTransactionError(fakeSQLStateError{code: "23505"})
+
+		assert.False(t, retryable)
+	})
+
+	t.Run("ReturnsTrueForCockroachRestartTransactionMessage", func(t *testing.T) {
+
+		// act
+		retryable := IsRetryableTransactionError(errors.New("restart transaction: TransactionRetryWithProtoRefreshError"))
+
+		assert.True(t, retryable)
+	})
+}
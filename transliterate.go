@@ -0,0 +1,85 @@
+package foundation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transliterations maps groups of characters to the ascii approximation they share
+var transliterations = []struct {
+	from string
+	to   string
+}{
+	// latin
+	{"ÀÁÂÃÄÅĀĂĄǍǺȀȂȦ", "A"}, {"àáâãäåāăąǎǻȁȃȧª", "a"},
+	{"ÇĆĈĊČ", "C"}, {"çćĉċč", "c"},
+	{"ÐĎĐ", "D"}, {"ðďđ", "d"},
+	{"ÈÉÊËĒĔĖĘĚȄȆȨ", "E"}, {"èéêëēĕėęěȅȇȩ", "e"},
+	{"ĜĞĠĢǦ", "G"}, {"ĝğġģǧ", "g"},
+	{"ĤĦȞ", "H"}, {"ĥħȟ", "h"},
+	{"ÌÍÎÏĨĪĬĮİǏȈȊ", "I"}, {"ìíîïĩīĭįıǐȉȋ", "i"},
+	{"Ĵ", "J"}, {"ĵǰ", "j"},
+	{"ĶǨ", "K"}, {"ķĸǩ", "k"},
+	{"ĹĻĽĿŁ", "L"}, {"ĺļľŀł", "l"},
+	{"ÑŃŅŇŊ", "N"}, {"ñńņňŉŋ", "n"},
+	{"ÒÓÔÕÖØŌŎŐǑǾȌȎȮ", "O"}, {"òóôõöøōŏőǒǿȍȏȯº", "o"},
+	{"ŔŖŘȐȒ", "R"}, {"ŕŗřȑȓ", "r"},
+	{"ŚŜŞŠȘ", "S"}, {"śŝşšșſ", "s"},
+	{"ŢŤŦȚ", "T"}, {"ţťŧț", "t"},
+	{"ÙÚÛÜŨŪŬŮŰŲǓǕǗǙǛȔȖ", "U"}, {"ùúûüũūŭůűųǔǖǘǚǜȕȗ", "u"},
+	{"Ŵ", "W"}, {"ŵ", "w"},
+	{"ÝŶŸȲ", "Y"}, {"ýÿŷȳ", "y"},
+	{"ŹŻŽ", "Z"}, {"źżž", "z"},
+	{"ÆǼ", "AE"}, {"æǽ", "ae"},
+	{"Œ", "OE"}, {"œ", "oe"},
+	{"Þ", "TH"}, {"þ", "th"},
+	{"ß", "ss"},
+	{"Ĳ", "IJ"}, {"ĳ", "ij"},
+	// greek
+	{"ΑΆ", "A"}, {"αά", "a"}, {"Β", "V"}, {"β", "v"}, {"Γ", "G"}, {"γ", "g"}, {"Δ", "D"}, {"δ", "d"},
+	{"ΕΈ", "E"}, {"εέ", "e"}, {"Ζ", "Z"}, {"ζ", "z"}, {"ΗΉ", "I"}, {"ηή", "i"}, {"Θ", "TH"}, {"θ", "th"},
+	{"ΙΊΪ", "I"}, {"ιίϊΐ", "i"}, {"Κ", "K"}, {"κ", "k"}, {"Λ", "L"}, {"λ", "l"}, {"Μ", "M"}, {"μ", "m"},
+	{"Ν", "N"}, {"ν", "n"}, {"Ξ", "X"}, {"ξ", "x"}, {"ΟΌ", "O"}, {"οό", "o"}, {"Π", "P"}, {"π", "p"},
+	{"Ρ", "R"}, {"ρ", "r"}, {"Σ", "S"}, {"σς", "s"}, {"Τ", "T"}, {"τ", "t"}, {"ΥΎΫ", "Y"}, {"υύϋΰ", "y"},
+	{"Φ", "F"}, {"φ", "f"}, {"Χ", "CH"}, {"χ", "ch"}, {"Ψ", "PS"}, {"ψ", "ps"}, {"ΩΏ", "O"}, {"ωώ", "o"},
+	// cyrillic
+	{"А", "A"}, {"а", "a"}, {"Б", "B"}, {"б", "b"}, {"В", "V"}, {"в", "v"}, {"ГҐ", "G"}, {"гґ", "g"},
+	{"Д", "D"}, {"д", "d"}, {"ЕЁЄ", "E"}, {"еёє", "e"}, {"Ж", "ZH"}, {"ж", "zh"}, {"З", "Z"}, {"з", "z"},
+	{"ИІЇЙ", "I"}, {"иіїй", "i"}, {"К", "K"}, {"к", "k"}, {"Л", "L"}, {"л", "l"}, {"М", "M"}, {"м", "m"},
+	{"Н", "N"}, {"н", "n"}, {"О", "O"}, {"о", "o"}, {"П", "P"}, {"п", "p"}, {"Р", "R"}, {"р", "r"},
+	{"С", "S"}, {"с", "s"}, {"Т", "T"}, {"т", "t"}, {"УЎ", "U"}, {"уў", "u"}, {"Ф", "F"}, {"ф", "f"},
+	{"Х", "KH"}, {"х", "kh"}, {"Ц", "TS"}, {"ц", "ts"}, {"Ч", "CH"}, {"ч", "ch"}, {"Ш", "SH"}, {"ш", "sh"},
+	{"Щ", "SHCH"}, {"щ", "shch"}, {"Ы", "Y"}, {"ы", "y"}, {"Э", "E"}, {"э", "e"}, {"Ю", "YU"}, {"ю", "yu"},
+	{"Я", "YA"}, {"я", "ya"}, {"ЪЬъь", ""},
+	// punctuation
+	{"‐‑‒–—―", "-"}, {"‘’‚‛′", "'"}, {"“”„‟″", "\""}, {"…", "..."}, {"«", "<<"}, {"»", ">>"},
+}
+
+var transliterationMap = func() map[rune]string {
+	m := map[rune]string{}
+	for _, t := range transliterations {
+		for _, r := range t.from {
+			m[r] = t.to
+		}
+	}
+	return m
+}()
+
+// Transliterate converts accented latin, greek and cyrillic characters to their ascii approximation, like Ærøskøbing to AEroskobing, so names with diacritics produce valid kubernetes resource names after slugifying; other non-ascii characters are removed, except for whitespace which becomes a space
+func Transliterate(in string) string {
+	var out strings.Builder
+	out.Grow(len(in))
+
+	for _, r := range in {
+		switch {
+		case r < unicode.MaxASCII:
+			out.WriteRune(r)
+		case unicode.IsSpace(r):
+			out.WriteRune(' ')
+		default:
+			out.WriteString(transliterationMap[r])
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,50 @@
+package foundation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransliterate(t *testing.T) {
+
+	t.Run("ReturnsAsciiUnchanged", func(t *testing.T) {
+
+		// act
+		out := Transliterate("ziplinee-ci/api_v2")
+
+		assert.Equal(t, "ziplinee-ci/api_v2", out)
+	})
+
+	t.Run("RemovesDiacritics", func(t *testing.T) {
+
+		// act
+		out := Transliterate("Crème Brûlée à São Paulo, Łódź, İstanbul")
+
+		assert.Equal(t, "Creme Brulee a Sao Paulo, Lodz, Istanbul", out)
+	})
+
+	t.Run("ExpandsLigatures", func(t *testing.T) {
+
+		// act
+		out := Transliterate("Ærøskøbing Straße œuvre")
+
+		assert.Equal(t, "AEroskobing Strasse oeuvre", out)
+	})
+
+	t.Run("ConvertsGreekAndCyrillic", func(t *testing.T) {
+
+		// act
+		out := Transliterate("Αθήνα Москва")
+
+		assert.Equal(t, "Athina Moskva", out)
+	})
+
+	t.Run("RemovesUnknownCharacters", func(t *testing.T) {
+
+		// act
+		out := Transliterate("build 🚀 東京")
+
+		assert.Equal(t, "build  ", out)
+	})
+}
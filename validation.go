@@ -0,0 +1,205 @@
+package foundation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeOption allows to override the defaults of DecodeAndValidateJSON
+type DecodeOption func(*json.Decoder)
+
+// DisallowUnknownFields rejects payloads with fields that don't exist in the target struct
+func DisallowUnknownFields() DecodeOption {
+	return func(d *json.Decoder) {
+		d.DisallowUnknownFields()
+	}
+}
+
+// DecodeAndValidateJSON decodes the json request body of at most maxBytes into target and validates it against the validate struct tags; failures are returned as *Problem, ready to be written with WriteProblemError. Supported rules, separated by commas, are:
+//
+//	required    the field can't have its zero value
+//	min=n       minimum value for numbers, minimum length for strings, slices and maps
+//	max=n       maximum value for numbers, maximum length for strings, slices and maps
+//	oneof=a b   the string has to be one of the space-separated values
+func DecodeAndValidateJSON(r *http.Request, target interface{}, maxBytes int64, opts ...DecodeOption) error {
+
+	// read one byte more than allowed to detect too large bodies
+	counter := &countingReader{reader: io.LimitReader(r.Body, maxBytes+1)}
+	decoder := json.NewDecoder(counter)
+	for _, opt := range opts {
+		opt(decoder)
+	}
+
+	if err := decoder.Decode(target); err != nil {
+		if counter.count > maxBytes {
+			return &Problem{Status: http.StatusRequestEntityTooLarge, Detail: fmt.Sprintf("Request body exceeds %v bytes", maxBytes)}
+		}
+		return &Problem{Status: http.StatusBadRequest, Detail: fmt.Sprintf("Request body is not valid json: %v", err)}
+	}
+	if decoder.More() {
+		return &Problem{Status: http.StatusBadRequest, Detail: "Request body contains more than a single json value"}
+	}
+
+	if invalidParams := ValidateStruct(target); len(invalidParams) > 0 {
+		return &Problem{Status: http.StatusUnprocessableEntity, Detail: "Request body failed validation", InvalidParams: invalidParams}
+	}
+
+	return nil
+}
+
+// WriteProblemError writes err as application/problem+json response; a *Problem is written as is, an *AppError with its http status and user-safe message, any other error as 500 Internal Server Error without exposing its message
+func WriteProblemError(w http.ResponseWriter, r *http.Request, err error) {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		WriteProblemDetails(w, r, *problem)
+		return
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		WriteProblemDetails(w, r, Problem{
+			Type:   "urn:ziplinee:error:" + string(appErr.Code),
+			Status: appErr.HTTPStatus(),
+			Detail: appErr.Message,
+		})
+		return
+	}
+
+	WriteProblem(w, r, http.StatusInternalServerError, "")
+}
+
+// ValidateStruct validates the struct v points to against its validate struct tags, see DecodeAndValidateJSON; nested structs are validated as well
+func ValidateStruct(v interface{}) []InvalidParam {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	invalidParams := []InvalidParam{}
+	validateStructValue(value, "", &invalidParams)
+
+	return invalidParams
+}
+
+func validateStructValue(value reflect.Value, prefix string, invalidParams *[]InvalidParam) {
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		name = prefix + name
+		field := value.Field(i)
+
+		if rules, ok := structField.Tag.Lookup("validate"); ok {
+			for _, rule := range strings.Split(rules, ",") {
+				if reason := validateRule(field, rule); reason != "" {
+					*invalidParams = append(*invalidParams, InvalidParam{Name: name, Reason: reason})
+					break
+				}
+			}
+		}
+
+		nested := field
+		if nested.Kind() == reflect.Ptr && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct {
+			validateStructValue(nested, name+".", invalidParams)
+		}
+	}
+}
+
+// validateRule returns the reason the field fails the rule, or an empty string if it passes
+func validateRule(field reflect.Value, rule string) string {
+	kv := strings.SplitN(strings.TrimSpace(rule), "=", 2)
+	name := kv[0]
+	arg := ""
+	if len(kv) == 2 {
+		arg = kv[1]
+	}
+
+	switch name {
+	case "required":
+		if field.IsZero() {
+			return "is required"
+		}
+
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("has invalid rule %v", rule)
+		}
+		size, isLength, ok := validationSize(field)
+		if !ok {
+			return ""
+		}
+		if name == "min" && size < limit {
+			if isLength {
+				return fmt.Sprintf("must have a length of at least %v", arg)
+			}
+			return fmt.Sprintf("must be at least %v", arg)
+		}
+		if name == "max" && size > limit {
+			if isLength {
+				return fmt.Sprintf("must have a length of at most %v", arg)
+			}
+			return fmt.Sprintf("must be at most %v", arg)
+		}
+
+	case "oneof":
+		if field.Kind() != reflect.String {
+			return ""
+		}
+		if !StringArrayContains(strings.Fields(arg), field.String()) {
+			return fmt.Sprintf("must be one of %v", strings.Join(strings.Fields(arg), ", "))
+		}
+	}
+
+	return ""
+}
+
+func validationSize(field reflect.Value) (size float64, isLength bool, ok bool) {
+	switch field.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(field.Len()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), false, true
+	}
+
+	return 0, false, false
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
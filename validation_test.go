@@ -0,0 +1,114 @@
+package foundation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPipelineTrigger struct {
+	Event string `json:"event" validate:"required,oneof=push cron manual"`
+}
+
+type testCreatePipelineRequest struct {
+	Name     string              `json:"name" validate:"required,max=20"`
+	Workers  int                 `json:"workers" validate:"min=1,max=10"`
+	Labels   []string            `json:"labels" validate:"max=2"`
+	Trigger  testPipelineTrigger `json:"trigger"`
+	Internal string              `json:"-"`
+}
+
+func TestDecodeAndValidateJSON(t *testing.T) {
+
+	t.Run("DecodesValidPayload", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodPost, "/api/pipelines", strings.NewReader(`{"name":"ziplinee-ci","workers":2,"trigger":{"event":"push"}}`))
+		body := testCreatePipelineRequest{}
+
+		// act
+		err := DecodeAndValidateJSON(request, &body, 1024)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "ziplinee-ci", body.Name)
+		assert.Equal(t, "push", body.Trigger.Event)
+	})
+
+	t.Run("ReturnsRequestEntityTooLargeProblemIfBodyExceedsMaxBytes", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodPost, "/api/pipelines", strings.NewReader(`{"name":"ziplinee-ci","workers":2,"trigger":{"event":"push"}}`))
+
+		// act
+		err := DecodeAndValidateJSON(request, &testCreatePipelineRequest{}, 16)
+
+		var problem *Problem
+		if assert.True(t, errors.As(err, &problem)) {
+			assert.Equal(t, http.StatusRequestEntityTooLarge, problem.Status)
+		}
+	})
+
+	t.Run("ReturnsBadRequestProblemForUnknownFieldsIfDisallowed", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodPost, "/api/pipelines", strings.NewReader(`{"name":"ziplinee-ci","workers":2,"trigger":{"event":"push"},"color":"blue"}`))
+
+		// act
+		err := DecodeAndValidateJSON(request, &testCreatePipelineRequest{}, 1024, DisallowUnknownFields())
+
+		var problem *Problem
+		if assert.True(t, errors.As(err, &problem)) {
+			assert.Equal(t, http.StatusBadRequest, problem.Status)
+		}
+	})
+
+	t.Run("ReturnsUnprocessableEntityProblemWithAllInvalidParams", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodPost, "/api/pipelines", strings.NewReader(`{"workers":12,"labels":["a","b","c"],"trigger":{"event":"tag"}}`))
+
+		// act
+		err := DecodeAndValidateJSON(request, &testCreatePipelineRequest{}, 1024)
+
+		var problem *Problem
+		if assert.True(t, errors.As(err, &problem)) {
+			assert.Equal(t, http.StatusUnprocessableEntity, problem.Status)
+			assert.Equal(t, []InvalidParam{
+				{Name: "name", Reason: "is required"},
+				{Name: "workers", Reason: "must be at most 10"},
+				{Name: "labels", Reason: "must have a length of at most 2"},
+				{Name: "trigger.event", Reason: "must be one of push, cron, manual"},
+			}, problem.InvalidParams)
+		}
+	})
+}
+
+func TestWriteProblemError(t *testing.T) {
+
+	t.Run("WritesProblemWithInvalidParams", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodPost, "/api/pipelines", nil)
+		recorder := httptest.NewRecorder()
+
+		// act
+		WriteProblemError(recorder, request, &Problem{Status: http.StatusUnprocessableEntity, InvalidParams: []InvalidParam{{Name: "name", Reason: "is required"}}})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		problem := Problem{}
+		json.Unmarshal(recorder.Body.Bytes(), &problem)
+		assert.Equal(t, []InvalidParam{{Name: "name", Reason: "is required"}}, problem.InvalidParams)
+	})
+
+	t.Run("WritesInternalServerErrorForOtherErrors", func(t *testing.T) {
+
+		request := httptest.NewRequest(http.MethodPost, "/api/pipelines", nil)
+		recorder := httptest.NewRecorder()
+
+		// act
+		WriteProblemError(recorder, request, errors.New("connection refused"))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.False(t, strings.Contains(recorder.Body.String(), "connection refused"))
+	})
+}
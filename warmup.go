@@ -0,0 +1,148 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WarmUpFunc prepares the application for serving traffic, like priming caches or opening connections; readiness is withheld until it returns
+type WarmUpFunc func(ctx context.Context) error
+
+type namedWarmUp struct {
+	name   string
+	warmUp WarmUpFunc
+}
+
+// WarmUpProgress is the state of a single warm-up function, as returned by the startup endpoint
+type WarmUpProgress struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// startupStatus is returned by the startup endpoint
+type startupStatus struct {
+	Completed      bool             `json:"completed"`
+	DelayRemaining time.Duration    `json:"delayRemaining"`
+	WarmUps        []WarmUpProgress `json:"warmUps"`
+}
+
+var (
+	// warmUpCompleted is nil as long as no warm-up has been started, otherwise it's closed once the readiness delay has passed and all warm-up functions succeeded
+	warmUpCompleted chan struct{}
+	warmUpReadyAt   time.Time
+	warmUpProgress  []WarmUpProgress
+	warmUpMutex     sync.Mutex
+)
+
+// startWarmUp withholds readiness until the readiness delay has passed and the warm-up functions have run one after the other; a failing warm-up function keeps withholding readiness, so the startup probe eventually has the application restarted
+func startWarmUp(config *InitConfig) {
+	if config.ReadinessDelay <= 0 && len(config.WarmUps) == 0 {
+		return
+	}
+
+	warmUpMutex.Lock()
+	if warmUpCompleted != nil {
+		// already started by another Init function
+		warmUpMutex.Unlock()
+		return
+	}
+	completed := make(chan struct{})
+	warmUpCompleted = completed
+	readyAt := time.Now().Add(config.ReadinessDelay)
+	warmUpReadyAt = readyAt
+	progress := make([]WarmUpProgress, len(config.WarmUps))
+	for i, w := range config.WarmUps {
+		progress[i] = WarmUpProgress{Name: w.name, Status: "pending"}
+	}
+	warmUpProgress = progress
+	warmUpMutex.Unlock()
+
+	go func() {
+		for i, w := range config.WarmUps {
+			setWarmUpProgress(progress, i, "running", nil, 0)
+
+			start := time.Now()
+			err := w.warmUp(context.Background())
+			if err != nil {
+				setWarmUpProgress(progress, i, "failed", err, time.Since(start))
+				config.Logger.Error().Err(err).Msgf("Warm-up %v failed, withholding readiness", w.name)
+				return
+			}
+
+			setWarmUpProgress(progress, i, "completed", nil, time.Since(start))
+			config.Logger.Info().Msgf("Warm-up %v completed in %v", w.name, time.Since(start))
+		}
+
+		if delay := time.Until(readyAt); delay > 0 {
+			time.Sleep(delay)
+		}
+
+		config.Logger.Info().Msg("Warm-up completed, marking application as ready")
+		close(completed)
+	}()
+}
+
+func setWarmUpProgress(progress []WarmUpProgress, i int, status string, err error, duration time.Duration) {
+	warmUpMutex.Lock()
+	defer warmUpMutex.Unlock()
+
+	progress[i].Status = status
+	progress[i].Duration = duration
+	if err != nil {
+		progress[i].Error = err.Error()
+	}
+}
+
+// isWarmedUp returns whether the warm-up has completed or no warm-up has been started
+func isWarmedUp() bool {
+	warmUpMutex.Lock()
+	completed := warmUpCompleted
+	warmUpMutex.Unlock()
+
+	if completed == nil {
+		return true
+	}
+
+	select {
+	case <-completed:
+		return true
+	default:
+		return false
+	}
+}
+
+// startupHandler responds with 200 once the warm-up has completed and 503 before, with the warm-up progress as json
+func startupHandler(w http.ResponseWriter, _ *http.Request) {
+	status := startupStatus{
+		Completed: isWarmedUp(),
+		WarmUps:   []WarmUpProgress{},
+	}
+
+	warmUpMutex.Lock()
+	if remaining := time.Until(warmUpReadyAt); remaining > 0 {
+		status.DelayRemaining = remaining
+	}
+	status.WarmUps = append(status.WarmUps, warmUpProgress...)
+	warmUpMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Completed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// resetWarmUp forgets the started warm-up; only for use in tests
+func resetWarmUp() {
+	warmUpMutex.Lock()
+	defer warmUpMutex.Unlock()
+
+	warmUpCompleted = nil
+	warmUpReadyAt = time.Time{}
+	warmUpProgress = nil
+}
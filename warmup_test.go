@@ -0,0 +1,108 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartWarmUp(t *testing.T) {
+
+	t.Run("WithholdsReadinessUntilWarmUpCompleted", func(t *testing.T) {
+
+		defer resetWarmUp()
+		release := make(chan struct{})
+		config := newInitConfig(5000, []InitOption{WithWarmUp("cache", func(ctx context.Context) error {
+			<-release
+			return nil
+		})})
+
+		// act
+		startWarmUp(config)
+
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+		close(release)
+		assert.Eventually(t, isWarmedUp, time.Second, time.Millisecond)
+	})
+
+	t.Run("WithholdsReadinessUntilDelayHasPassed", func(t *testing.T) {
+
+		defer resetWarmUp()
+		config := newInitConfig(5000, []InitOption{WithReadinessDelay(20 * time.Millisecond)})
+
+		// act
+		startWarmUp(config)
+
+		assert.False(t, isWarmedUp())
+		assert.Eventually(t, isWarmedUp, time.Second, time.Millisecond)
+	})
+
+	t.Run("KeepsWithholdingReadinessIfWarmUpFails", func(t *testing.T) {
+
+		defer resetWarmUp()
+		config := newInitConfig(5000, []InitOption{WithWarmUp("cache", func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})})
+
+		// act
+		startWarmUp(config)
+
+		time.Sleep(10 * time.Millisecond)
+		assert.False(t, isWarmedUp())
+	})
+}
+
+func TestStartupHandler(t *testing.T) {
+
+	t.Run("Returns200OKWithoutWarmUp", func(t *testing.T) {
+
+		defer resetWarmUp()
+		recorder := httptest.NewRecorder()
+
+		// act
+		startupHandler(recorder, httptest.NewRequest(http.MethodGet, "/startup", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"completed":true,"delayRemaining":0,"warmUps":[]}`, recorder.Body.String())
+	})
+
+	t.Run("Returns503WithProgressDuringWarmUp", func(t *testing.T) {
+
+		defer resetWarmUp()
+		release := make(chan struct{})
+		defer close(release)
+		startWarmUp(newInitConfig(5000, []InitOption{
+			WithWarmUp("connections", func(ctx context.Context) error { return nil }),
+			WithWarmUp("cache", func(ctx context.Context) error {
+				<-release
+				return nil
+			}),
+		}))
+		assert.Eventually(t, func() bool {
+			warmUpMutex.Lock()
+			defer warmUpMutex.Unlock()
+			return warmUpProgress[1].Status == "running"
+		}, time.Second, time.Millisecond)
+		recorder := httptest.NewRecorder()
+
+		// act
+		startupHandler(recorder, httptest.NewRequest(http.MethodGet, "/startup", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		var status startupStatus
+		if assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status)) {
+			assert.False(t, status.Completed)
+			assert.Equal(t, "completed", status.WarmUps[0].Status)
+			assert.Equal(t, "running", status.WarmUps[1].Status)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	webhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "foundation_webhook_deliveries_total",
+			Help: "Total number of received webhook deliveries by source and result.",
+		},
+		[]string{"source", "result"},
+	)
+	webhookHandlingDurationSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "foundation_webhook_handling_duration_seconds",
+			Help:    "Duration of asynchronously handling webhook deliveries by source.",
+			Buckets: DefaultDurationBuckets(),
+		},
+		[]string{"source"},
+	)
+)
+
+// Webhook is a single verified webhook delivery
+type Webhook struct {
+	Source     string
+	DeliveryID string
+	Headers    http.Header
+	Payload    []byte
+}
+
+// HandleWebhookFunc handles a webhook asynchronously after the sender already received a response
+type HandleWebhookFunc func(ctx context.Context, webhook Webhook) error
+
+// WebhookReceiverConfig configures a WebhookReceiver for a single source
+type WebhookReceiverConfig struct {
+	// Source names the sender, like github or bitbucket, and is used as metrics label
+	Source string
+	// Secret to verify the HMAC SHA-256 signature with; without a secret all deliveries are rejected, unless AllowUnsigned is set
+	Secret []byte
+	// AllowUnsigned accepts deliveries without verifying their signature if no Secret is set, for senders that can't sign their webhooks
+	AllowUnsigned bool
+	// SignatureHeader holds the signature, like X-Hub-Signature-256
+	SignatureHeader string
+	// DeliveryIDHeader holds a unique id per delivery, like X-GitHub-Delivery, used to ignore replayed deliveries
+	DeliveryIDHeader string
+	// MaxPayloadBytes limits the request body size; defaults to 5MB
+	MaxPayloadBytes int64
+	// ReplayWindow is how long delivery ids are remembered; defaults to 1 hour
+	ReplayWindow time.Duration
+	// MaxConcurrency is the number of webhooks handled at the same time; defaults to 5
+	MaxConcurrency int
+	// Deduplicator remembers delivery ids; defaults to an in-memory deduplicator remembering 100000 ids, pass one created with a snapshot path to keep ignoring replays after a restart
+	Deduplicator Deduplicator
+}
+
+type webhookReceiver struct {
+	config    WebhookReceiverConfig
+	handle    HandleWebhookFunc
+	waitGroup *sync.WaitGroup
+	semaphore Semaphore
+}
+
+// NewWebhookReceiver returns an http.Handler that verifies webhook deliveries, responds immediately and hands them off to handle on a pool of workers tracked in the waitGroup returned by InitGracefulShutdownHandling
+func NewWebhookReceiver(config WebhookReceiverConfig, handle HandleWebhookFunc, waitGroup *sync.WaitGroup) http.Handler {
+	if config.MaxPayloadBytes <= 0 {
+		config.MaxPayloadBytes = 5 * 1024 * 1024
+	}
+	if config.ReplayWindow <= 0 {
+		config.ReplayWindow = time.Hour
+	}
+	if config.MaxConcurrency < 1 {
+		config.MaxConcurrency = 5
+	}
+	if config.Deduplicator == nil {
+		// without snapshot path this never returns an error
+		config.Deduplicator, _ = NewDeduplicator(100000, "", 0)
+	}
+	if len(config.Secret) == 0 && !config.AllowUnsigned {
+		log.Error().Msgf("Webhook receiver for %v has no secret, rejecting all deliveries; set AllowUnsigned to accept unsigned webhooks", config.Source)
+	}
+
+	return &webhookReceiver{
+		config:    config,
+		handle:    handle,
+		waitGroup: waitGroup,
+		semaphore: NewSemaphore(config.MaxConcurrency),
+	}
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		wr.respond(w, http.StatusMethodNotAllowed, "rejected")
+		return
+	}
+
+	// read one byte more than allowed to tell a too large payload apart from other read errors
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, wr.config.MaxPayloadBytes+1))
+	if err != nil {
+		log.Warn().Err(err).Msgf("Reading %v webhook payload failed", wr.config.Source)
+		wr.respond(w, http.StatusBadRequest, "unreadable")
+		return
+	}
+	if int64(len(payload)) > wr.config.MaxPayloadBytes {
+		log.Warn().Msgf("Payload of %v webhook is larger than %v bytes", wr.config.Source, wr.config.MaxPayloadBytes)
+		wr.respond(w, http.StatusRequestEntityTooLarge, "too_large")
+		return
+	}
+
+	if len(wr.config.Secret) == 0 && !wr.config.AllowUnsigned {
+		wr.respond(w, http.StatusUnauthorized, "invalid_signature")
+		return
+	}
+	if len(wr.config.Secret) > 0 && !VerifyHMACSHA256(wr.config.Secret, payload, r.Header.Get(wr.config.SignatureHeader)) {
+		log.Warn().Msgf("Signature of %v webhook is invalid", wr.config.Source)
+		wr.respond(w, http.StatusUnauthorized, "invalid_signature")
+		return
+	}
+
+	// don't block the sender when all workers are busy, let it retry later instead
+	select {
+	case wr.semaphore.GetAcquireChannel() <- struct{}{}:
+	default:
+		wr.respond(w, http.StatusServiceUnavailable, "busy")
+		return
+	}
+
+	deliveryID := ""
+	if wr.config.DeliveryIDHeader != "" {
+		deliveryID = r.Header.Get(wr.config.DeliveryIDHeader)
+	}
+	if deliveryID != "" && wr.isReplay(deliveryID) {
+		wr.semaphore.Release()
+		log.Debug().Msgf("Ignoring replayed %v webhook delivery %v", wr.config.Source, deliveryID)
+		wr.respond(w, http.StatusOK, "duplicate")
+		return
+	}
+
+	webhook := Webhook{
+		Source:     wr.config.Source,
+		DeliveryID: deliveryID,
+		Headers:    r.Header.Clone(),
+		Payload:    payload,
+	}
+
+	if wr.waitGroup != nil {
+		wr.waitGroup.Add(1)
+	}
+	go func() {
+		defer func() {
+			wr.semaphore.Release()
+			if wr.waitGroup != nil {
+				wr.waitGroup.Done()
+			}
+		}()
+
+		wr.handleWebhook(webhook)
+	}()
+
+	wr.respond(w, http.StatusOK, "accepted")
+}
+
+func (wr *webhookReceiver) handleWebhook(webhook Webhook) {
+	start := time.Now()
+	defer func() {
+		webhookHandlingDurationSeconds.WithLabelValues(webhook.Source).Observe(time.Since(start).Seconds())
+	}()
+
+	var err error
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("Handling webhook panicked: %v", rec)
+			}
+		}()
+		err = wr.handle(context.Background(), webhook)
+	}()
+
+	if err != nil {
+		webhookDeliveriesTotal.WithLabelValues(webhook.Source, "failed").Inc()
+		log.Error().Err(err).Msgf("Handling %v webhook delivery %v failed", webhook.Source, webhook.DeliveryID)
+		return
+	}
+
+	webhookDeliveriesTotal.WithLabelValues(webhook.Source, "handled").Inc()
+}
+
+func (wr *webhookReceiver) respond(w http.ResponseWriter, statusCode int, result string) {
+	webhookDeliveriesTotal.WithLabelValues(wr.config.Source, result).Inc()
+
+	w.WriteHeader(statusCode)
+}
+
+// isReplay returns whether the delivery id has been seen within the replay window and otherwise remembers it
+func (wr *webhookReceiver) isReplay(deliveryID string) bool {
+	return wr.config.Deduplicator.Seen(wr.config.Source+"/"+deliveryID, wr.config.ReplayWindow)
+}
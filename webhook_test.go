@@ -0,0 +1,168 @@
+//go:build !foundation_minimal
+// +build !foundation_minimal
+
+package foundation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhookReceiver(t *testing.T) {
+
+	config := WebhookReceiverConfig{
+		Source:           "github",
+		Secret:           []byte("secret"),
+		SignatureHeader:  "X-Hub-Signature-256",
+		DeliveryIDHeader: "X-GitHub-Delivery",
+		MaxPayloadBytes:  64,
+	}
+
+	post := func(receiver http.Handler, payload, signature, deliveryID string) int {
+		request := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(payload))
+		request.Header.Set("X-Hub-Signature-256", signature)
+		request.Header.Set("X-GitHub-Delivery", deliveryID)
+		recorder := httptest.NewRecorder()
+		receiver.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	t.Run("Returns200AndHandsOffWebhookWithValidSignature", func(t *testing.T) {
+
+		waitGroup := &sync.WaitGroup{}
+		var handled []Webhook
+		var mutex sync.Mutex
+		receiver := NewWebhookReceiver(config, func(ctx context.Context, webhook Webhook) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			handled = append(handled, webhook)
+			return nil
+		}, waitGroup)
+
+		// act
+		statusCode := post(receiver, `{"action":"push"}`, SignHMACSHA256([]byte("secret"), []byte(`{"action":"push"}`)), "delivery-1")
+
+		assert.Equal(t, http.StatusOK, statusCode)
+		waitGroup.Wait()
+		if assert.Equal(t, 1, len(handled)) {
+			assert.Equal(t, "github", handled[0].Source)
+			assert.Equal(t, "delivery-1", handled[0].DeliveryID)
+			assert.Equal(t, `{"action":"push"}`, string(handled[0].Payload))
+		}
+	})
+
+	t.Run("Returns401ForInvalidSignature", func(t *testing.T) {
+
+		receiver := NewWebhookReceiver(config, func(ctx context.Context, webhook Webhook) error {
+			t.Error("webhook with invalid signature should not be handled")
+			return nil
+		}, nil)
+
+		// act
+		statusCode := post(receiver, `{"action":"push"}`, SignHMACSHA256([]byte("wrong"), []byte(`{"action":"push"}`)), "delivery-1")
+
+		assert.Equal(t, http.StatusUnauthorized, statusCode)
+	})
+
+	t.Run("Returns413ForTooLargePayload", func(t *testing.T) {
+
+		receiver := NewWebhookReceiver(config, func(ctx context.Context, webhook Webhook) error { return nil }, nil)
+		payload := strings.Repeat("x", 65)
+
+		// act
+		statusCode := post(receiver, payload, SignHMACSHA256([]byte("secret"), []byte(payload)), "delivery-1")
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusCode)
+	})
+
+	t.Run("Returns400IfPayloadCanNotBeRead", func(t *testing.T) {
+
+		receiver := NewWebhookReceiver(config, func(ctx context.Context, webhook Webhook) error { return nil }, nil)
+		request := httptest.NewRequest(http.MethodPost, "/webhooks/github", iotest.ErrReader(errors.New("connection reset")))
+		recorder := httptest.NewRecorder()
+
+		// act
+		receiver.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("Returns401IfNoSecretIsSet", func(t *testing.T) {
+
+		unsignedConfig := config
+		unsignedConfig.Secret = nil
+		receiver := NewWebhookReceiver(unsignedConfig, func(ctx context.Context, webhook Webhook) error {
+			t.Error("webhook without secret should not be handled")
+			return nil
+		}, nil)
+
+		// act
+		statusCode := post(receiver, `{"action":"push"}`, "", "delivery-1")
+
+		assert.Equal(t, http.StatusUnauthorized, statusCode)
+	})
+
+	t.Run("Returns200WithoutSignatureIfUnsignedWebhooksAreAllowed", func(t *testing.T) {
+
+		unsignedConfig := config
+		unsignedConfig.Secret = nil
+		unsignedConfig.AllowUnsigned = true
+		receiver := NewWebhookReceiver(unsignedConfig, func(ctx context.Context, webhook Webhook) error { return nil }, nil)
+
+		// act
+		statusCode := post(receiver, `{"action":"push"}`, "", "delivery-1")
+
+		assert.Equal(t, http.StatusOK, statusCode)
+	})
+
+	t.Run("IgnoresReplayedDelivery", func(t *testing.T) {
+
+		waitGroup := &sync.WaitGroup{}
+		var mutex sync.Mutex
+		handledCount := 0
+		receiver := NewWebhookReceiver(config, func(ctx context.Context, webhook Webhook) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			handledCount++
+			return nil
+		}, waitGroup)
+		signature := SignHMACSHA256([]byte("secret"), []byte(`{}`))
+
+		// act
+		post(receiver, `{}`, signature, "delivery-1")
+		statusCode := post(receiver, `{}`, signature, "delivery-1")
+
+		assert.Equal(t, http.StatusOK, statusCode)
+		waitGroup.Wait()
+		assert.Equal(t, 1, handledCount)
+	})
+
+	t.Run("Returns503IfAllWorkersAreBusy", func(t *testing.T) {
+
+		busyConfig := config
+		busyConfig.MaxConcurrency = 1
+		waitGroup := &sync.WaitGroup{}
+		release := make(chan struct{})
+		receiver := NewWebhookReceiver(busyConfig, func(ctx context.Context, webhook Webhook) error {
+			<-release
+			return nil
+		}, waitGroup)
+		signature := SignHMACSHA256([]byte("secret"), []byte(`{}`))
+		post(receiver, `{}`, signature, "delivery-1")
+
+		// act
+		statusCode := post(receiver, `{}`, signature, "delivery-2")
+
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		close(release)
+		waitGroup.Wait()
+	})
+}
@@ -0,0 +1,234 @@
+package foundation
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Message types and close codes as defined in RFC 6455, matching the constants in github.com/gorilla/websocket
+const (
+	WebSocketTextMessage   = 1
+	WebSocketBinaryMessage = 2
+	WebSocketCloseMessage  = 8
+
+	WebSocketCloseNormalClosure   = 1000
+	WebSocketCloseGoingAway       = 1001
+	WebSocketClosePolicyViolation = 1008
+)
+
+// ErrWebSocketHubDraining is returned when registering or sending to a connection while the hub is draining
+var ErrWebSocketHubDraining = errors.New("WebSocket hub is draining")
+
+// ErrWebSocketConnectionUnknown is returned when sending to a connection that isn't registered with the hub
+var ErrWebSocketConnectionUnknown = errors.New("WebSocket connection is not registered")
+
+// WebSocketConn is the subset of a websocket connection used by the hub; *websocket.Conn from github.com/gorilla/websocket satisfies it
+type WebSocketConn interface {
+	WriteMessage(messageType int, data []byte) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	Close() error
+}
+
+// WebSocketHub fans out messages to registered websocket connections, each with its own send queue
+type WebSocketHub interface {
+	// Register adds a connection and starts writing its send queue to it
+	Register(conn WebSocketConn) error
+	// Unregister closes the connection with a normal closure frame after its queued messages have been written
+	Unregister(conn WebSocketConn)
+	// Send queues a message for a single connection
+	Send(conn WebSocketConn, messageType int, data []byte) error
+	// Broadcast queues a message for all connections; connections whose send queue is full are too slow to keep up and get closed
+	Broadcast(messageType int, data []byte)
+	// Len returns the number of registered connections
+	Len() int
+	// Drain stops accepting connections, writes queued messages and closes all connections with a going away frame; if ctx expires first the remaining connections are closed without waiting
+	Drain(ctx context.Context) error
+}
+
+type webSocketMessage struct {
+	messageType int
+	data        []byte
+}
+
+type webSocketClient struct {
+	conn      WebSocketConn
+	send      chan webSocketMessage
+	closeCode int
+}
+
+type webSocketHub struct {
+	mutex         sync.Mutex
+	clients       map[WebSocketConn]*webSocketClient
+	sendQueueSize int
+	draining      bool
+	writers       sync.WaitGroup
+}
+
+// NewWebSocketHub returns a WebSocketHub buffering up to sendQueueSize messages per connection
+func NewWebSocketHub(sendQueueSize int) WebSocketHub {
+	if sendQueueSize < 1 {
+		sendQueueSize = 1
+	}
+
+	return &webSocketHub{
+		clients:       map[WebSocketConn]*webSocketClient{},
+		sendQueueSize: sendQueueSize,
+	}
+}
+
+func (h *webSocketHub) Register(conn WebSocketConn) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.draining {
+		return ErrWebSocketHubDraining
+	}
+	if _, ok := h.clients[conn]; ok {
+		return nil
+	}
+
+	client := &webSocketClient{
+		conn:      conn,
+		send:      make(chan webSocketMessage, h.sendQueueSize),
+		closeCode: WebSocketCloseNormalClosure,
+	}
+	h.clients[conn] = client
+
+	h.writers.Add(1)
+	go h.write(client)
+
+	return nil
+}
+
+func (h *webSocketHub) Unregister(conn WebSocketConn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if client, ok := h.clients[conn]; ok {
+		h.remove(client, WebSocketCloseNormalClosure)
+	}
+}
+
+func (h *webSocketHub) Send(conn WebSocketConn, messageType int, data []byte) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.draining {
+		return ErrWebSocketHubDraining
+	}
+
+	client, ok := h.clients[conn]
+	if !ok {
+		return ErrWebSocketConnectionUnknown
+	}
+
+	h.enqueue(client, webSocketMessage{messageType: messageType, data: data})
+
+	return nil
+}
+
+func (h *webSocketHub) Broadcast(messageType int, data []byte) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.draining {
+		return
+	}
+
+	for _, client := range h.clients {
+		h.enqueue(client, webSocketMessage{messageType: messageType, data: data})
+	}
+}
+
+func (h *webSocketHub) Len() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.clients)
+}
+
+func (h *webSocketHub) Drain(ctx context.Context) error {
+	h.mutex.Lock()
+	h.draining = true
+	clients := make([]*webSocketClient, 0, len(h.clients))
+	for _, client := range h.clients {
+		clients = append(clients, client)
+		h.remove(client, WebSocketCloseGoingAway)
+	}
+	h.mutex.Unlock()
+
+	log.Info().Msgf("Draining %v websocket connections...", len(clients))
+
+	done := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, client := range clients {
+			client.conn.Close()
+		}
+		return ctx.Err()
+	}
+}
+
+// enqueue adds a message to the client's send queue without blocking; must be called with the mutex held
+func (h *webSocketHub) enqueue(client *webSocketClient, message webSocketMessage) {
+	select {
+	case client.send <- message:
+	default:
+		log.Warn().Msg("WebSocket send queue full, closing slow connection")
+		h.remove(client, WebSocketClosePolicyViolation)
+	}
+}
+
+// remove unregisters the client and closes its send queue so the writer sends a close frame; must be called with the mutex held
+func (h *webSocketHub) remove(client *webSocketClient, closeCode int) {
+	delete(h.clients, client.conn)
+	client.closeCode = closeCode
+	close(client.send)
+}
+
+func (h *webSocketHub) write(client *webSocketClient) {
+	defer h.writers.Done()
+
+	failed := false
+	for message := range client.send {
+		if failed {
+			// keep consuming until the queue is closed
+			continue
+		}
+		if err := client.conn.WriteMessage(message.messageType, message.data); err != nil {
+			log.Debug().Err(err).Msg("Writing websocket message failed, unregistering connection")
+			failed = true
+			go h.Unregister(client.conn)
+		}
+	}
+
+	if !failed {
+		// closeCode is safe to read here, it's set before the send queue gets closed
+		err := client.conn.WriteControl(WebSocketCloseMessage, formatWebSocketCloseMessage(client.closeCode, ""), time.Now().Add(5*time.Second))
+		if err != nil {
+			log.Debug().Err(err).Msg("Writing websocket close frame failed")
+		}
+	}
+
+	client.conn.Close()
+}
+
+func formatWebSocketCloseMessage(closeCode int, text string) []byte {
+	message := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(message, uint16(closeCode))
+	copy(message[2:], text)
+
+	return message
+}
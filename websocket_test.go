@@ -0,0 +1,139 @@
+package foundation
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWebSocketConn struct {
+	mutex      sync.Mutex
+	messages   []string
+	closeCodes []int
+	closed     bool
+	block      chan struct{}
+}
+
+func (c *fakeWebSocketConn) WriteMessage(messageType int, data []byte) error {
+	if c.block != nil {
+		<-c.block
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.messages = append(c.messages, string(data))
+	return nil
+}
+
+func (c *fakeWebSocketConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if messageType == WebSocketCloseMessage {
+		c.closeCodes = append(c.closeCodes, int(binary.BigEndian.Uint16(data)))
+	}
+	return nil
+}
+
+func (c *fakeWebSocketConn) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakeWebSocketConn) state() ([]string, []int, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string{}, c.messages...), append([]int{}, c.closeCodes...), c.closed
+}
+
+func TestWebSocketHub(t *testing.T) {
+
+	t.Run("BroadcastsToAllConnectionsAndClosesWithGoingAwayOnDrain", func(t *testing.T) {
+
+		hub := NewWebSocketHub(10)
+		conn1 := &fakeWebSocketConn{}
+		conn2 := &fakeWebSocketConn{}
+		hub.Register(conn1)
+		hub.Register(conn2)
+
+		// act
+		hub.Broadcast(WebSocketTextMessage, []byte("build started"))
+		hub.Send(conn2, WebSocketTextMessage, []byte("only for you"))
+		err := hub.Drain(context.Background())
+
+		assert.Nil(t, err)
+		messages, closeCodes, closed := conn1.state()
+		assert.Equal(t, []string{"build started"}, messages)
+		assert.Equal(t, []int{WebSocketCloseGoingAway}, closeCodes)
+		assert.True(t, closed)
+		messages, _, _ = conn2.state()
+		assert.Equal(t, []string{"build started", "only for you"}, messages)
+		assert.Equal(t, 0, hub.Len())
+	})
+
+	t.Run("ClosesConnectionWithNormalClosureOnUnregister", func(t *testing.T) {
+
+		hub := NewWebSocketHub(10)
+		conn := &fakeWebSocketConn{}
+		hub.Register(conn)
+
+		// act
+		hub.Unregister(conn)
+		hub.Drain(context.Background())
+
+		_, closeCodes, closed := conn.state()
+		assert.Equal(t, []int{WebSocketCloseNormalClosure}, closeCodes)
+		assert.True(t, closed)
+	})
+
+	t.Run("ClosesSlowConnectionWhenSendQueueIsFull", func(t *testing.T) {
+
+		hub := NewWebSocketHub(1)
+		slow := &fakeWebSocketConn{block: make(chan struct{})}
+		hub.Register(slow)
+
+		// act
+		for i := 0; i < 3; i++ {
+			hub.Broadcast(WebSocketTextMessage, []byte("update"))
+		}
+
+		assert.Equal(t, 0, hub.Len())
+		close(slow.block)
+		hub.Drain(context.Background())
+		_, closeCodes, _ := slow.state()
+		assert.Equal(t, []int{WebSocketClosePolicyViolation}, closeCodes)
+	})
+
+	t.Run("ReturnsErrorWhenRegisteringWhileDraining", func(t *testing.T) {
+
+		hub := NewWebSocketHub(10)
+		hub.Drain(context.Background())
+
+		// act
+		err := hub.Register(&fakeWebSocketConn{})
+
+		assert.Equal(t, ErrWebSocketHubDraining, err)
+	})
+
+	t.Run("ClosesRemainingConnectionsWhenDrainTimesOut", func(t *testing.T) {
+
+		hub := NewWebSocketHub(10)
+		stuck := &fakeWebSocketConn{block: make(chan struct{})}
+		defer close(stuck.block)
+		hub.Register(stuck)
+		hub.Broadcast(WebSocketTextMessage, []byte("update"))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		// act
+		err := hub.Drain(ctx)
+
+		assert.Equal(t, context.DeadlineExceeded, err)
+		_, _, closed := stuck.state()
+		assert.True(t, closed)
+	})
+}